@@ -1,60 +1,110 @@
 package api
 
 const (
-	AuthServerCAMountDir                = "/var/auth-server-ca"
-	AuthServerCAFileName                = "ca-bundle.crt"
-	CLIOIDCClientComponentName          = "cli"
-	ClusterOperatorName                 = "console"
-	ConfigResourceName                  = "cluster"
-	ConsoleContainerPort                = 443
-	ConsoleContainerPortName            = "https"
-	ConsoleContainerTargetPort          = 8443
-	ConsoleServingCertName              = "console-serving-cert"
-	DefaultIngressCertConfigMapName     = "default-ingress-cert"
-	DownloadsPort                       = 8080
-	DownloadsPortName                   = "http"
-	DownloadsResourceName               = "downloads"
-	NodeArchitectureLabel               = "kubernetes.io/arch"
-	NodeOperatingSystemLabel            = "kubernetes.io/os"
-	OAuthConfigMapName                  = "oauth-openshift"
-	OAuthServingCertConfigMapName       = "oauth-serving-cert"
-	OCCLIDownloadsCustomResourceName    = "oc-cli-downloads"
-	OLMConfigGroup                      = "operators.coreos.com"
-	OLMConfigResource                   = "olmconfigs"
-	OLMConfigVersion                    = "v1"
-	OpenShiftConfigManagedNamespace     = "openshift-config-managed"
-	OpenShiftConfigNamespace            = "openshift-config"
-	OpenShiftConsoleConfigMapName       = "console-config"
-	OpenShiftConsoleName                = "console"
-	OpenShiftConsoleOperator            = "console-operator"
-	OpenShiftConsoleOperatorNamespace   = "openshift-console-operator"
-	OpenShiftConsolePublicConfigMapName = "console-public"
-	OpenShiftCustomLogoConfigMapName    = "custom-logo"
-	OpenShiftMonitoringConfigMapName    = "monitoring-shared-config"
-	OpenshiftConsoleCustomRouteName     = "console-custom"
-	OpenshiftDownloadsCustomRouteName   = "downloads-custom"
-	OpenshiftConsoleRedirectServiceName = "console-redirect"
-	RedirectContainerPort               = 8444
-	RedirectContainerPortName           = "custom-route-redirect"
-	ServiceCAConfigMapName              = "service-ca"
-	SessionSecretName                   = "session-secret"
-	TargetNamespace                     = "openshift-console"
-	TrustedCABundleKey                  = "ca-bundle.crt"
-	TrustedCABundleMountDir             = "/etc/pki/ca-trust/extracted/pem"
-	TrustedCABundleMountFile            = "tls-ca-bundle.pem"
-	TrustedCAConfigMapName              = "trusted-ca-bundle"
-	UpgradeConsoleNotification          = "cluster-upgrade"
-	V1Alpha1PluginI18nAnnotation        = "console.openshift.io/use-i18n"
-	VersionResourceName                 = "version"
+	AuthServerCAMountDir                    = "/var/auth-server-ca"
+	AuthServerCAFileName                    = "ca-bundle.crt"
+	BackendCAName                           = "console-backend-ca"
+	BackendTLSPolicyResource                = "backendtlspolicies"
+	BreakGlassName                          = "console-break-glass"
+	BreakGlassServingCertName               = "console-break-glass-serving-cert"
+	BreakGlassUntilAnnotation               = "console.openshift.io/break-glass-until"
+	CLIOIDCClientComponentName              = "cli"
+	CertManagerAPIGroup                     = "cert-manager.io"
+	CertManagerAPIVersion                   = "v1"
+	CertManagerCertificateResource          = "certificates"
+	ClusterOperatorName                     = "console"
+	ConfigResourceName                      = "cluster"
+	ConsoleContainerPort                    = 443
+	ConsoleContainerPortName                = "https"
+	ConsoleContainerTargetPort              = 8443
+	ConsoleServingCertName                  = "console-serving-cert"
+	DefaultIngressCertConfigMapName         = "default-ingress-cert"
+	DownloadsPort                           = 8080
+	DownloadsPortName                       = "http"
+	DownloadsResourceName                   = "downloads"
+	ExposureModeAnnotation                  = "console.openshift.io/exposure-mode"
+	GatewayAnnotation                       = "console.openshift.io/gateway"
+	GatewayAPIGroup                         = "gateway.networking.k8s.io"
+	GatewayAPIVersion                       = "v1"
+	HealthCheckClientCertAnnotation         = "console.openshift.io/health-check-client-certificate"
+	HTTPRouteResource                       = "httproutes"
+	ImpersonationAnnotation                 = "console.openshift.io/impersonation"
+	ImpersonationAuditAnnotation            = "console.openshift.io/impersonation-audit-annotation"
+	ImpersonationGroupsAnnotation           = "console.openshift.io/impersonation-groups"
+	IngressClassAnnotation                  = "console.openshift.io/ingress-class"
+	IngressControllerAnnotation             = "console.openshift.io/ingress-controller"
+	NodeArchitectureLabel                   = "kubernetes.io/arch"
+	NodeOperatingSystemLabel                = "kubernetes.io/os"
+	OAuthClientConsoleHostsAnnotation       = "console.openshift.io/console-hosts"
+	OAuthClientGrantMethodAnnotation        = "console.openshift.io/oauth-grant-method"
+	OAuthClientManagedTokenConfigAnnotation = "console.openshift.io/managed-token-config"
+	OAuthConfigMapName                      = "oauth-openshift"
+	OAuthRedirectURIsPolicyAnnotation       = "console.openshift.io/oauth-redirect-uris-policy"
+	OAuthServingCertConfigMapName           = "oauth-serving-cert"
+	OAuthTokenInactivityAnnotation          = "console.openshift.io/oauth-access-token-inactivity-timeout"
+	OAuthTokenMaxAgeAnnotation              = "console.openshift.io/oauth-access-token-max-age"
+	OCCLIDownloadsCustomResourceName        = "oc-cli-downloads"
+	OIDCPostLogoutRedirectAnnotation        = "console.openshift.io/oidc-post-logout-redirect-uri"
+	OLMConfigGroup                          = "operators.coreos.com"
+	OLMConfigResource                       = "olmconfigs"
+	OLMConfigVersion                        = "v1"
+	OpenShiftConfigManagedNamespace         = "openshift-config-managed"
+	OpenShiftConfigNamespace                = "openshift-config"
+	OpenShiftConsoleConfigMapName           = "console-config"
+	OpenShiftConsoleName                    = "console"
+	OpenShiftConsoleOperator                = "console-operator"
+	OpenShiftConsoleOperatorNamespace       = "openshift-console-operator"
+	OpenShiftConsolePublicConfigMapName     = "console-public"
+	OpenShiftCustomLogoConfigMapName        = "custom-logo"
+	OpenShiftMonitoringConfigMapName        = "monitoring-shared-config"
+	OpenshiftConsoleCustomRouteName         = "console-custom"
+	OpenshiftDownloadsCustomRouteName       = "downloads-custom"
+	OpenshiftConsoleRedirectServiceName     = "console-redirect"
+	PluginAutoEnableDenyAnnotation          = "console.openshift.io/plugin-auto-enable-deny"
+	PluginAutoEnableNamespaceAnnotation     = "console.openshift.io/plugin-auto-enable-namespaces"
+	PluginAutoEnableSelectorAnnotation      = "console.openshift.io/plugin-auto-enable-selector"
+	PluginCompatibilityConfigMapName        = "console-plugin-compatibility"
+	OIDCDiscoveryConfigMapName              = "console-oidc-discovery"
+	PluginCSPGuardrailsAnnotation           = "console.openshift.io/plugin-csp-guardrails"
+	PluginDependenciesAnnotation            = "console.openshift.io/plugin-dependencies"
+	PluginEgressNetworkPolicyName           = "console-plugins-egress"
+	PluginI18nLanguagesAnnotation           = "console.openshift.io/plugin-i18n-languages"
+	PluginQuarantineConfigMapName           = "console-plugin-quarantine"
+	RedirectContainerPort                   = 8444
+	RedirectContainerPortName               = "custom-route-redirect"
+	RouteTLSSecretNamespaceAnnotation       = "console.openshift.io/route-tls-secret-namespace"
+	ServiceCAConfigMapName                  = "service-ca"
+	SessionSecretName                       = "session-secret"
+	TargetNamespace                         = "openshift-console"
+	TrustedCABundleKey                      = "ca-bundle.crt"
+	TrustedCABundleMountDir                 = "/etc/pki/ca-trust/extracted/pem"
+	TrustedCABundleMountFile                = "tls-ca-bundle.pem"
+	TrustedCAConfigMapName                  = "trusted-ca-bundle"
+	UpgradeConsoleNotification              = "cluster-upgrade"
+	V1Alpha1PluginI18nAnnotation            = "console.openshift.io/use-i18n"
+	VersionResourceName                     = "version"
 
 	HelmChartreposViewerRoleName             = "helm-chartrepos-viewer"
 	ProjectHelmChartrepositoryEditorRoleName = "project-helm-chartrepository-editor"
 	ConsoleExtensionsReaderRoleName          = "console-extensions-reader"
 
+	// the console and downloads can be exposed through a Route (default),
+	// a networking.k8s.io Ingress or a Gateway API HTTPRoute
+	ExposureModeRoute     = "Route"
+	ExposureModeIngress   = "Ingress"
+	ExposureModeHTTPRoute = "HTTPRoute"
+
+	// keys of the console-oauth-config secret next to clientSecret: the client secret of an auth
+	// type the console doesn't serve yet, and the client secret a rotation replaced, which the IdP
+	// still accepts while the console rolls out the rotated one
+	PendingClientSecretKey  = "pendingClientSecret"
+	PreviousClientSecretKey = "previousClientSecret"
+
 	// ingress instance named "default" is the OOTB ingresscontroller
 	// this is an implicit stable API
 	DefaultIngressController   = "default"
 	IngressControllerNamespace = "openshift-ingress-operator"
+	OpenShiftIngressNamespace  = "openshift-ingress"
 
 	OAuthClientName                             = OpenShiftConsoleName
 	OpenShiftConsoleDeploymentName              = OpenShiftConsoleName
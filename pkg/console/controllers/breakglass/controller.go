@@ -0,0 +1,318 @@
+package breakglass
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	// k8s
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1informers "k8s.io/client-go/informers/apps/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	networkingv1informers "k8s.io/client-go/informers/networking/v1"
+	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	networkingclientv1 "k8s.io/client-go/kubernetes/typed/networking/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	networkingv1listers "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	breakglasssub "github.com/openshift/console-operator/pkg/console/subresource/breakglass"
+)
+
+// BreakGlassController deploys a secondary console for cluster admins while the OIDC client of
+// the console is reported Degraded, e.g. after the certificate of the IdP changed. Admins request
+// it by annotating the operator config with the time it should end at, at most a day ahead:
+//
+//	console.openshift.io/break-glass-until: "2024-01-01T12:00:00Z"
+//
+// The console has no login which keeps working without the IdP, so the login of the break-glass
+// console is disabled and it is not routed. All ingress to its pods is denied, admins reach it
+// through a port forward, which requires their kubeconfig:
+//
+//	oc port-forward -n openshift-console service/console-break-glass 8443:443
+//
+// It is removed once the time passed, the annotation is removed or the console client recovers.
+// Activation and removal are recorded as events.
+//
+// ==========
+//
+//	writes:
+//	- deployments.console-break-glass -n openshift-console
+//	- services.console-break-glass -n openshift-console
+//	- networkpolicies.console-break-glass -n openshift-console
+//	- configmaps.console-break-glass -n openshift-console
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=BreakGlassDegraded
+//		- type=BreakGlassSyncProgressing
+//		- type=BreakGlassSyncDegraded
+//		- type=BreakGlassAccess
+type BreakGlassController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	authnConfigLister    configv1listers.AuthenticationLister
+	configMapLister      corev1listers.ConfigMapLister
+	serviceLister        corev1listers.ServiceLister
+	networkPolicyLister  networkingv1listers.NetworkPolicyLister
+	deploymentLister     appsv1listers.DeploymentLister
+	configMapClient      coreclientv1.ConfigMapsGetter
+	serviceClient        coreclientv1.ServicesGetter
+	networkPolicyClient  networkingclientv1.NetworkPoliciesGetter
+	deploymentClient     appsclientv1.DeploymentsGetter
+	resourceCache        resourceapply.ResourceCache
+	externalOIDCEnabled  bool
+}
+
+func NewBreakGlassController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	coreClient coreclientv1.CoreV1Interface,
+	networkPolicyClient networkingclientv1.NetworkPoliciesGetter,
+	deploymentClient appsclientv1.DeploymentsGetter,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	authnConfigInformer configv1informers.AuthenticationInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
+	serviceInformer corev1informers.ServiceInformer,
+	networkPolicyInformer networkingv1informers.NetworkPolicyInformer,
+	deploymentInformer appsv1informers.DeploymentInformer,
+	externalOIDCEnabled bool,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &BreakGlassController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		authnConfigLister:    authnConfigInformer.Lister(),
+		configMapLister:      configMapInformer.Lister(),
+		serviceLister:        serviceInformer.Lister(),
+		networkPolicyLister:  networkPolicyInformer.Lister(),
+		deploymentLister:     deploymentInformer.Lister(),
+		configMapClient:      coreClient,
+		serviceClient:        coreClient,
+		networkPolicyClient:  networkPolicyClient,
+		deploymentClient:     deploymentClient,
+		resourceCache:        resourceapply.NewResourceCache(),
+		externalOIDCEnabled:  externalOIDCEnabled,
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			authnConfigInformer.Informer(),
+		).
+		WithFilteredEventsInformers(
+			util.IncludeNamesFilter(api.OpenShiftConsoleConfigMapName, api.BreakGlassName),
+			configMapInformer.Informer(),
+		).
+		WithFilteredEventsInformers(
+			util.IncludeNamesFilter(api.BreakGlassName),
+			serviceInformer.Informer(),
+			networkPolicyInformer.Informer(),
+		).
+		WithFilteredEventsInformers(
+			util.IncludeNamesFilter(api.OpenShiftConsoleDeploymentName, api.BreakGlassName),
+			deploymentInformer.Informer(),
+		).
+		ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("BreakGlassController", recorder.WithComponentSuffix("break-glass-controller"))
+}
+
+func (c *BreakGlassController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch operatorConfig.Spec.ManagementState {
+	case operatorv1.Managed:
+		klog.V(4).Info("console-operator is in a managed state: syncing break-glass console")
+	case operatorv1.Unmanaged:
+		klog.V(4).Info("console-operator is in an unmanaged state: skipping break-glass console sync")
+		return nil
+	case operatorv1.Removed:
+		klog.V(4).Info("console-operator is in a removed state: deleting break-glass console")
+		_, err := c.removeBreakGlass(ctx)
+		return err
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	now := time.Now()
+
+	until, untilErr := breakglasssub.GetUntil(operatorConfig, now)
+	statusHandler.AddCondition(status.HandleDegraded("BreakGlass", "InvalidBreakGlassUntil", untilErr))
+
+	authnConfig, err := c.authnConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+
+	inactiveReason, inactiveMessage := getInactiveReason(c.externalOIDCEnabled, authnConfig, until, now)
+	if len(inactiveReason) > 0 {
+		removed, removeErr := c.removeBreakGlass(ctx)
+		if removed {
+			controllerContext.Recorder().Eventf("BreakGlassDeactivated", "Break-glass console access removed: %s", inactiveMessage)
+		}
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("BreakGlassSync", "FailedDelete", removeErr))
+		statusHandler.AddCondition(status.HandleInformational("BreakGlassAccess", inactiveReason, inactiveMessage))
+		return statusHandler.FlushAndReturn(removeErr)
+	}
+
+	reason, err := c.syncBreakGlass(ctx, controllerContext.Recorder())
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("BreakGlassSync", reason, err))
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+	statusHandler.AddCondition(status.HandleInformational("BreakGlassAccess", "Active", fmt.Sprintf("break-glass console access at https://localhost:%d through %q until %s", breakglasssub.LocalPort, breakglasssub.GetPortForwardCommand(), until.Format(time.RFC3339))))
+
+	// remove the break-glass console right when it expires rather than on the next resync
+	controllerContext.Queue().AddAfter(controllerContext.QueueKey(), until.Sub(now))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+// syncBreakGlass applies the break-glass console.
+func (c *BreakGlassController) syncBreakGlass(ctx context.Context, recorder events.Recorder) (string, error) {
+	consoleConfigMap, err := c.configMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleConfigMapName)
+	if err != nil {
+		return "FailedConsoleConfigGet", err
+	}
+	consoleDeployment, err := c.deploymentLister.Deployments(api.TargetNamespace).Get(api.OpenShiftConsoleDeploymentName)
+	if err != nil {
+		return "FailedConsoleDeploymentGet", err
+	}
+	// the break-glass deployment only changes with its spec, which is tracked by the spec hash
+	expectedGeneration := int64(-1)
+	existingDeployment, err := c.deploymentLister.Deployments(api.TargetNamespace).Get(api.BreakGlassName)
+	activated := apierrors.IsNotFound(err)
+	if err == nil {
+		expectedGeneration = existingDeployment.Generation
+	}
+
+	// deny the ingress before the pods with the disabled login are started
+	err = util.RetryOnTransientError(func() error {
+		_, _, e := resourceapply.ApplyNetworkPolicy(ctx, c.networkPolicyClient, recorder, breakglasssub.DefaultNetworkPolicy(), c.resourceCache)
+		return e
+	})
+	if err != nil {
+		return "FailedNetworkPolicyApply", err
+	}
+
+	requiredConfigMap, err := breakglasssub.DefaultConfigMap(consoleConfigMap)
+	if err != nil {
+		return "InvalidConsoleConfig", err
+	}
+
+	var configMap *corev1.ConfigMap
+	err = util.RetryOnTransientError(func() error {
+		var e error
+		configMap, _, e = resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, requiredConfigMap)
+		return e
+	})
+	if err != nil {
+		return "FailedConfigMapApply", err
+	}
+
+	err = util.RetryOnTransientError(func() error {
+		_, _, e := resourceapply.ApplyService(ctx, c.serviceClient, recorder, breakglasssub.DefaultService())
+		return e
+	})
+	if err != nil {
+		return "FailedServiceApply", err
+	}
+
+	requiredDeployment := breakglasssub.DefaultDeployment(consoleDeployment, configMap)
+	err = util.RetryOnTransientError(func() error {
+		_, _, e := resourceapply.ApplyDeployment(ctx, c.deploymentClient, recorder, requiredDeployment, expectedGeneration)
+		return e
+	})
+	if err != nil {
+		return "FailedDeploymentApply", err
+	}
+
+	if activated {
+		recorder.Warningf("BreakGlassActivated", "Break-glass console access activated through %q, the OIDC client of the console is degraded", breakglasssub.GetPortForwardCommand())
+	}
+	return "", nil
+}
+
+// removeBreakGlass removes the break-glass console and returns whether it was deployed. Only
+// the resources which are found are deleted.
+func (c *BreakGlassController) removeBreakGlass(ctx context.Context) (bool, error) {
+	var removals []func() error
+	_, err := c.deploymentLister.Deployments(api.TargetNamespace).Get(api.BreakGlassName)
+	deployed := err == nil
+	if deployed {
+		removals = append(removals, func() error {
+			return c.deploymentClient.Deployments(api.TargetNamespace).Delete(ctx, api.BreakGlassName, metav1.DeleteOptions{})
+		})
+	} else if !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	if _, err := c.serviceLister.Services(api.TargetNamespace).Get(api.BreakGlassName); err == nil {
+		removals = append(removals, func() error {
+			return c.serviceClient.Services(api.TargetNamespace).Delete(ctx, api.BreakGlassName, metav1.DeleteOptions{})
+		})
+	} else if !apierrors.IsNotFound(err) {
+		return deployed, err
+	}
+	if _, err := c.configMapLister.ConfigMaps(api.TargetNamespace).Get(api.BreakGlassName); err == nil {
+		removals = append(removals, func() error {
+			return c.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, api.BreakGlassName, metav1.DeleteOptions{})
+		})
+	} else if !apierrors.IsNotFound(err) {
+		return deployed, err
+	}
+	// the ingress is denied until the pods are gone
+	if _, err := c.networkPolicyLister.NetworkPolicies(api.TargetNamespace).Get(api.BreakGlassName); err == nil {
+		removals = append(removals, func() error {
+			return c.networkPolicyClient.NetworkPolicies(api.TargetNamespace).Delete(ctx, api.BreakGlassName, metav1.DeleteOptions{})
+		})
+	} else if !apierrors.IsNotFound(err) {
+		return deployed, err
+	}
+
+	for _, remove := range removals {
+		if err := remove(); err != nil && !apierrors.IsNotFound(err) {
+			return deployed, err
+		}
+	}
+	return deployed, nil
+}
+
+// getInactiveReason returns why the break-glass console is not deployed, an empty reason
+// means it is.
+func getInactiveReason(externalOIDCEnabled bool, authnConfig *configv1.Authentication, until time.Time, now time.Time) (string, string) {
+	switch {
+	case until.IsZero():
+		return "NotRequested", "break-glass console access is not requested"
+	case !now.Before(until):
+		return "Expired", fmt.Sprintf("break-glass console access expired at %s", until.Format(time.RFC3339))
+	case !externalOIDCEnabled || authnConfig.Spec.Type != configv1.AuthenticationTypeOIDC:
+		return "OIDCNotConfigured", "break-glass console access is only provided when logging in through an external OIDC provider"
+	case !breakglasssub.IsConsoleClientDegraded(authnConfig):
+		return "ConsoleClientNotDegraded", "break-glass console access is only provided while the OIDC client of the console is degraded"
+	}
+	return "", ""
+}
@@ -13,9 +13,12 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
 	// openshift
+	configv1 "github.com/openshift/api/config/v1"
 	v1 "github.com/openshift/api/console/v1"
 	operatorsv1 "github.com/openshift/api/operator/v1"
 	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
@@ -41,6 +44,8 @@ import (
 	"github.com/openshift/console-operator/pkg/api"
 	controllersutil "github.com/openshift/console-operator/pkg/console/controllers/util"
 	"github.com/openshift/console-operator/pkg/console/status"
+	authnsub "github.com/openshift/console-operator/pkg/console/subresource/authentication"
+	infrastructuresub "github.com/openshift/console-operator/pkg/console/subresource/infrastructure"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
 	"github.com/openshift/console-operator/pkg/console/subresource/util"
 )
@@ -49,9 +54,13 @@ type CLIDownloadsSyncController struct {
 	// clients
 	operatorClient            v1helpers.OperatorClient
 	consoleCliDownloadsClient consoleclientv1.ConsoleCLIDownloadInterface
+	namespaceLister           corev1listers.NamespaceLister
 	routeLister               routev1listers.RouteLister
 	ingressConfigLister       configlistersv1.IngressLister
+	authnConfigLister         configlistersv1.AuthenticationLister
+	infrastructureLister      configlistersv1.InfrastructureLister
 	operatorConfigLister      operatorv1listers.ConsoleLister
+	ingressControllerLister   operatorv1listers.IngressControllerLister
 }
 
 func NewCLIDownloadsSyncController(
@@ -65,6 +74,8 @@ func NewCLIDownloadsSyncController(
 	configInformer configinformer.SharedInformerFactory,
 	consoleCLIDownloadsInformers consoleinformersv1.ConsoleCLIDownloadInformer,
 	routeInformer routesinformersv1.RouteInformer,
+	ingressControllerInformer operatorinformersv1.IngressControllerInformer,
+	namespaceInformer coreinformersv1.NamespaceInformer,
 	// events
 	recorder events.Recorder,
 ) factory.Controller {
@@ -72,10 +83,14 @@ func NewCLIDownloadsSyncController(
 	ctrl := &CLIDownloadsSyncController{
 		// clients
 		operatorClient:            operatorClient,
+		namespaceLister:           namespaceInformer.Lister(),
 		consoleCliDownloadsClient: cliDownloadsInterface,
 		routeLister:               routeInformer.Lister(),
 		ingressConfigLister:       configInformer.Config().V1().Ingresses().Lister(),
+		authnConfigLister:         configInformer.Config().V1().Authentications().Lister(),
+		infrastructureLister:      configInformer.Config().V1().Infrastructures().Lister(),
 		operatorConfigLister:      operatorConfigInformer.Lister(),
+		ingressControllerLister:   ingressControllerInformer.Lister(),
 	}
 
 	configV1Informers := configInformer.Config().V1()
@@ -85,11 +100,16 @@ func NewCLIDownloadsSyncController(
 			controllersutil.IncludeNamesFilter(api.ConfigResourceName),
 			operatorConfigInformer.Informer(),
 			configV1Informers.Ingresses().Informer(),
+			configV1Informers.Authentications().Informer(),
+			configV1Informers.Infrastructures().Informer(),
 		).WithFilteredEventsInformers( // console resources
 		controllersutil.IncludeNamesFilter(api.OpenShiftConsoleDownloadsRouteName),
 		routeInformer.Informer(),
 	).WithInformers(
 		consoleCLIDownloadsInformers.Informer(),
+	).WithInformers( // ingress controllers — the routes can be placed on any shard
+		ingressControllerInformer.Informer(),
+		namespaceInformer.Informer(),
 	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
 		ToController("ConsoleCLIDownloadsController", recorder.WithComponentSuffix("console-cli-downloads-controller"))
 }
@@ -127,19 +147,27 @@ func (c *CLIDownloadsSyncController) Sync(ctx context.Context, controllerContext
 		}
 
 		activeRouteName := api.OpenShiftConsoleDownloadsRouteName
-		routeConfig := routesub.NewRouteConfig(updatedOperatorConfig, ingressConfig, activeRouteName)
+		ingressController, err := routesub.GetServedIngressController(c.ingressControllerLister, c.namespaceLister, updatedOperatorConfig)
+		if err != nil {
+			return statusHandler.FlushAndReturn(err)
+		}
+		routeConfig := routesub.NewShardedRouteConfig(updatedOperatorConfig, ingressConfig, ingressController, activeRouteName)
 		if routeConfig.IsCustomHostnameSet() {
 			activeRouteName = api.OpenshiftDownloadsCustomRouteName
 		}
 
-		downloadsRoute, downloadsRouteErr := c.routeLister.Routes(api.TargetNamespace).Get(activeRouteName)
-		if downloadsRouteErr != nil {
-			return downloadsRouteErr
-		}
+		if routesub.GetExposureMode(updatedOperatorConfig) != api.ExposureModeRoute {
+			downloadsURI = routeConfig.GetExposedURL()
+		} else {
+			downloadsRoute, downloadsRouteErr := c.routeLister.Routes(api.TargetNamespace).Get(activeRouteName)
+			if downloadsRouteErr != nil {
+				return downloadsRouteErr
+			}
 
-		downloadsURI, _, downloadsErr = routeapihelpers.IngressURI(downloadsRoute, downloadsRoute.Spec.Host)
-		if downloadsErr != nil {
-			return downloadsErr
+			downloadsURI, _, downloadsErr = routeapihelpers.IngressURI(downloadsRoute, downloadsRoute.Spec.Host)
+			if downloadsErr != nil {
+				return downloadsErr
+			}
 		}
 	} else {
 		downloadsURI, downloadsErr = url.Parse(operatorConfig.Spec.Ingress.ClientDownloadsURL)
@@ -148,7 +176,12 @@ func (c *CLIDownloadsSyncController) Sync(ctx context.Context, controllerContext
 		}
 	}
 
-	ocConsoleCLIDownloads := PlatformBasedOCConsoleCLIDownloads(downloadsURI.String(), api.OCCLIDownloadsCustomResourceName)
+	ocLoginCommand, err := c.getOCLoginCommand()
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+
+	ocConsoleCLIDownloads := PlatformBasedOCConsoleCLIDownloads(downloadsURI.String(), api.OCCLIDownloadsCustomResourceName, ocLoginCommand)
 	_, ocCLIDownloadsErrReason, ocCLIDownloadsErr := ApplyCLIDownloads(ctx, c.consoleCliDownloadsClient, ocConsoleCLIDownloads)
 	statusHandler.AddCondition(status.HandleDegraded("OCDownloadsSync", ocCLIDownloadsErrReason, ocCLIDownloadsErr))
 	if ocCLIDownloadsErr != nil {
@@ -158,6 +191,23 @@ func (c *CLIDownloadsSyncController) Sync(ctx context.Context, controllerContext
 	return statusHandler.FlushAndReturn(nil)
 }
 
+// getOCLoginCommand returns the oc login command of the CLI OIDC client, the same one the console
+// shows, or an empty string if the cluster doesn't log in through an external OIDC provider.
+func (c *CLIDownloadsSyncController) getOCLoginCommand() (string, error) {
+	authnConfig, err := c.authnConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return "", err
+	}
+	if authnConfig.Spec.Type != configv1.AuthenticationTypeOIDC {
+		return "", nil
+	}
+	infrastructureConfig, err := c.infrastructureLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return "", err
+	}
+	return authnsub.GetOIDCOCLoginCommand(authnConfig, infrastructuresub.GetAPIServerURL(infrastructureConfig)), nil
+}
+
 func (c *CLIDownloadsSyncController) removeCLIDownloads(ctx context.Context) error {
 	defer klog.V(4).Info("finished deleting ConsoleCliDownloads custom resources")
 	var errs []error
@@ -169,7 +219,7 @@ func GetPlatformURL(baseURL, platform, archiveType string) string {
 	return fmt.Sprintf("%s/%s/%s", baseURL, platform, archiveType)
 }
 
-func PlatformBasedOCConsoleCLIDownloads(host, cliDownloadsName string) *v1.ConsoleCLIDownload {
+func PlatformBasedOCConsoleCLIDownloads(host, cliDownloadsName, ocLoginCommand string) *v1.ConsoleCLIDownload {
 	baseURL := fmt.Sprintf("%s", util.HTTPS(host))
 	platforms := []struct {
 		label    string
@@ -206,15 +256,26 @@ func PlatformBasedOCConsoleCLIDownloads(host, cliDownloadsName string) *v1.Conso
 		Text: "LICENSE",
 	})
 
+	description := `With the OpenShift command line interface, you can create applications and manage OpenShift projects from a terminal.
+
+The oc binary offers the same capabilities as the kubectl binary, but it is further extended to natively support OpenShift Container Platform features. You can download oc using the following links.
+`
+	if len(ocLoginCommand) > 0 {
+		description += fmt.Sprintf(`
+The cluster authenticates users through an external OIDC provider. Log in with oc using the following command, which opens the login page of the provider in your browser:
+
+`+"```"+`
+%s
+`+"```"+`
+`, ocLoginCommand)
+	}
+
 	return &v1.ConsoleCLIDownload{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: cliDownloadsName,
 		},
 		Spec: v1.ConsoleCLIDownloadSpec{
-			Description: `With the OpenShift command line interface, you can create applications and manage OpenShift projects from a terminal.
-
-The oc binary offers the same capabilities as the kubectl binary, but it is further extended to natively support OpenShift Container Platform features. You can download oc using the following links.
-`,
+			Description: description,
 			DisplayName: "oc - OpenShift Command Line Interface (CLI)",
 			Links:       links,
 		},
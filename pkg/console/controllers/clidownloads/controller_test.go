@@ -1,6 +1,7 @@
 package clidownloads
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/go-test/deep"
@@ -191,9 +192,17 @@ The oc binary offers the same capabilities as the kubectl binary, but it is furt
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if diff := deep.Equal(PlatformBasedOCConsoleCLIDownloads(tt.args.host, tt.args.cliDownloadsName), tt.want); diff != nil {
+			if diff := deep.Equal(PlatformBasedOCConsoleCLIDownloads(tt.args.host, tt.args.cliDownloadsName, ""), tt.want); diff != nil {
 				t.Error(diff)
 			}
 		})
 	}
 }
+
+func TestPlatformBasedOCConsoleCLIDownloadsLoginCommand(t *testing.T) {
+	loginCommand := "oc login https://api.example.com:6443 --issuer-url https://idp.example.com --exec-plugin oc-oidc --client-id oc"
+	description := PlatformBasedOCConsoleCLIDownloads("www.example.com", "oc-cli-downloads", loginCommand).Spec.Description
+	if !strings.Contains(description, "```\n"+loginCommand+"\n```") {
+		t.Errorf("description doesn't hold the oc login command:\n%s", description)
+	}
+}
@@ -7,6 +7,8 @@ import (
 
 	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -23,7 +25,8 @@ import (
 	authnsub "github.com/openshift/console-operator/pkg/console/subresource/authentication"
 )
 
-// cliOIDCClientStatusController:
+// cliOIDCClientStatusController validates the configuration of the CLI client: oc logs in as a
+// public client through a localhost callback, which the issuer has to support.
 //
 //	writes:
 //	- authentication.config.openshift.io/cluster .status.oidcClients:
@@ -41,6 +44,7 @@ import (
 //		- type=CLIAuthStatusHandlerDegraded
 type cliOIDCClientStatusController struct {
 	authnLister                configv1listers.AuthenticationLister
+	configConfigMapLister      corev1listers.ConfigMapLister
 	authStatusHandler          *status.AuthStatusHandler
 	operatorClient             v1helpers.OperatorClient
 	statusHandler              status.StatusHandler
@@ -52,11 +56,13 @@ func NewCLIOIDCClientStatusController(
 	authnInformer configv1informers.AuthenticationInformer,
 	authenticationClient configv1client.AuthenticationInterface,
 	consoleOperatorInformer operatorv1informers.ConsoleInformer,
+	configConfigMapInformer corev1informers.ConfigMapInformer,
 	externalOIDCFeatureEnabled bool,
 	recorder events.Recorder,
 ) factory.Controller {
 	c := &cliOIDCClientStatusController{
 		authnLister:                authnInformer.Lister(),
+		configConfigMapLister:      configConfigMapInformer.Lister(),
 		authStatusHandler:          status.NewAuthStatusHandler(authenticationClient, api.CLIOIDCClientComponentName, api.TargetNamespace, "CLIOIDCClientStatusController"),
 		externalOIDCFeatureEnabled: externalOIDCFeatureEnabled,
 		operatorClient:             operatorClient,
@@ -68,9 +74,28 @@ func NewCLIOIDCClientStatusController(
 			authnInformer.Informer(),
 			consoleOperatorInformer.Informer(),
 		).
+		WithFilteredEventsInformers(
+			c.caConfigMapsFilter, configConfigMapInformer.Informer(),
+		).
 		ToController("CLIOIDCClientStatusController", recorder.WithComponentSuffix("CLIOIDCClientStatusController"))
 }
 
+// caConfigMapsFilter passes the events of the CA configmaps of the OIDC providers, which are the
+// only openshift-config configmaps the controller reads.
+func (c *cliOIDCClientStatusController) caConfigMapsFilter(obj interface{}) bool {
+	authnConfig, err := c.authnLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return false
+	}
+	caConfigMapNames := []string{}
+	for _, provider := range authnConfig.Spec.OIDCProviders {
+		if name := provider.Issuer.CertificateAuthority.Name; len(name) > 0 {
+			caConfigMapNames = append(caConfigMapNames, name)
+		}
+	}
+	return util.IncludeNamesFilter(caConfigMapNames...)(obj)
+}
+
 func (c *cliOIDCClientStatusController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
 	c.statusHandler = status.NewStatusHandler(c.operatorClient)
 	return util.HandleManagementState(ctx, c, c.operatorClient)
@@ -146,7 +171,7 @@ func (c *cliOIDCClientStatusController) HandleManaged(ctx context.Context) error
 }
 
 func (c *cliOIDCClientStatusController) syncOIDCCLient(authnConfig *configv1.Authentication) error {
-	_, clientConfig := authnsub.GetOIDCClientConfig(authnConfig, api.TargetNamespace, api.CLIOIDCClientComponentName)
+	oidcProvider, clientConfig := authnsub.GetOIDCClientConfig(authnConfig, api.TargetNamespace, api.CLIOIDCClientComponentName)
 	if clientConfig == nil {
 		c.authStatusHandler.WithCurrentOIDCClient("")
 		c.authStatusHandler.Unavailable("CLIOIDCClientStatus", "no CLI OIDC client spec found")
@@ -156,6 +181,22 @@ func (c *cliOIDCClientStatusController) syncOIDCCLient(authnConfig *configv1.Aut
 		return fmt.Errorf("no ID set on CLI OIDC client spec")
 	}
 	c.authStatusHandler.WithCurrentOIDCClient(clientConfig.ClientID)
+
+	// a misconfigured client would otherwise only show up as failing oc logins
+	if reason, err := c.validateCLIClient(oidcProvider, clientConfig); err != nil {
+		c.authStatusHandler.Degraded("CLIOIDCClient"+reason, err.Error())
+		return nil
+	}
 	c.authStatusHandler.Available("CLIOIDCConfigAvailable", "")
 	return nil
 }
+
+// validateCLIClient validates the CLI client against the issuer of its provider, trusting the
+// CA the provider is configured with.
+func (c *cliOIDCClientStatusController) validateCLIClient(provider *configv1.OIDCProvider, clientConfig *configv1.OIDCClientConfig) (string, error) {
+	httpClient, _, reason, err := authnsub.NewProviderIssuerClient(c.configConfigMapLister, provider)
+	if err != nil {
+		return reason, err
+	}
+	return authnsub.ValidateCLIClient(httpClient, provider, clientConfig)
+}
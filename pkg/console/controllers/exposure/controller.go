@@ -0,0 +1,591 @@
+package exposure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	// k8s
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	networkinginformersv1 "k8s.io/client-go/informers/networking/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	networkingclientv1 "k8s.io/client-go/kubernetes/typed/networking/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	networkingv1listers "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	v1 "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
+	subresourceutil "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// ExposureSyncController exposes the console or downloads through a networking.k8s.io
+// Ingress or a Gateway API HTTPRoute, when requested by the exposure mode annotation
+// of the operator config. Routes are handled by the RouteSyncController.
+//
+// The console serves TLS itself, so the connection from the ingress controller or the
+// Gateway is re-encrypted: through annotations known to the controller of the IngressClass,
+// or through a BackendTLSPolicy verifying the console against the service CA.
+//
+// HTTPRoutes and BackendTLSPolicies are watched if the Gateway API is installed when the
+// operator starts, otherwise they are applied on every resync. The same goes for the
+// cert-manager Certificates issuing custom TLS secrets in the openshift-console namespace.
+type ExposureSyncController struct {
+	routeName string
+	// clients
+	operatorClient         v1helpers.OperatorClient
+	ingressClient          networkingclientv1.IngressesGetter
+	secretClient           coreclientv1.SecretsGetter
+	configMapClient        coreclientv1.ConfigMapsGetter
+	namespaceLister        corev1listers.NamespaceLister
+	dynamicClient          dynamic.Interface
+	operatorConfigLister   operatorv1listers.ConsoleLister
+	ingressConfigLister    configlistersv1.IngressLister
+	ingressLister          networkingv1listers.IngressLister
+	ingressClassLister     networkingv1listers.IngressClassLister
+	configNSSecretLister   corev1listers.SecretLister
+	targetNSSecretLister   corev1listers.SecretLister
+	configMapLister        corev1listers.ConfigMapLister
+	httpRouteLister        cache.GenericLister
+	backendTLSPolicyLister cache.GenericLister
+	certificateLister      cache.GenericLister
+	// skips applying HTTPRoutes and BackendTLSPolicies the informers show unchanged
+	resourceCache resourceapply.ResourceCache
+	// the exposed hosts are derived from the domain of the IngressController shard
+	ingressControllerLister operatorv1listers.IngressControllerLister
+	// exposure mode the objects of the other modes were last cleaned up for,
+	// the cleanup only runs again when the mode changes
+	lastExposureMode string
+}
+
+func NewExposureSyncController(
+	routeName string,
+	// top level config
+	configInformer configinformer.SharedInformerFactory,
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	ingressClient networkingclientv1.IngressesGetter,
+	secretClient coreclientv1.SecretsGetter,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	dynamicClient dynamic.Interface,
+	// informers
+	operatorConfigInformer v1.ConsoleInformer,
+	ingressInformer networkinginformersv1.IngressInformer,
+	ingressClassInformer networkinginformersv1.IngressClassInformer,
+	configNSSecretInformer coreinformersv1.SecretInformer,
+	targetNSSecretInformer coreinformersv1.SecretInformer,
+	targetNSConfigMapInformer coreinformersv1.ConfigMapInformer,
+	ingressControllerInformer v1.IngressControllerInformer,
+	namespaceInformer coreinformersv1.NamespaceInformer,
+	dynamicInformers dynamicinformer.DynamicSharedInformerFactory, // `openshift-console` namespace
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &ExposureSyncController{
+		routeName:            routeName,
+		operatorClient:       operatorClient,
+		ingressClient:        ingressClient,
+		secretClient:         secretClient,
+		configMapClient:      configMapClient,
+		namespaceLister:      namespaceInformer.Lister(),
+		dynamicClient:        dynamicClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		ingressConfigLister:  configInformer.Config().V1().Ingresses().Lister(),
+		ingressLister:        ingressInformer.Lister(),
+		ingressClassLister:   ingressClassInformer.Lister(),
+		configNSSecretLister: configNSSecretInformer.Lister(),
+		targetNSSecretLister: targetNSSecretInformer.Lister(),
+		configMapLister:      targetNSConfigMapInformer.Lister(),
+		resourceCache:        resourceapply.NewResourceCache(),
+
+		ingressControllerLister: ingressControllerInformer.Lister(),
+	}
+
+	controllerFactory := factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			configInformer.Config().V1().Ingresses().Informer(),
+		).WithFilteredEventsInformers( // ingress
+		util.IncludeNamesFilter(routeName),
+		ingressInformer.Informer(),
+	).WithInformers(
+		ingressClassInformer.Informer(),
+		configNSSecretInformer.Informer(),
+	).WithFilteredEventsInformers( // secrets
+		ctrl.isTargetNSSecret,
+		targetNSSecretInformer.Informer(),
+	).WithFilteredEventsInformers( // configmaps
+		util.IncludeNamesFilter(api.ServiceCAConfigMapName, api.BackendCAName),
+		targetNSConfigMapInformer.Informer(),
+	).WithInformers( // ingress controllers — the hosts can be on any shard
+		ingressControllerInformer.Informer(),
+		namespaceInformer.Informer(),
+	)
+
+	// The Gateway API is optional, an informer for a missing resource would never sync.
+	if found, _ := util.IsResourceEnabled(dynamicClient, routesub.HTTPRouteGVR, api.OpenShiftConsoleNamespace); found {
+		httpRouteInformer := dynamicInformers.ForResource(routesub.HTTPRouteGVR)
+		ctrl.httpRouteLister = httpRouteInformer.Lister()
+		controllerFactory = controllerFactory.WithFilteredEventsInformers(util.IncludeNamesFilter(routeName), httpRouteInformer.Informer())
+	} else {
+		klog.Infof("httproutes resource does not exist in cluster, %q httproute is not watched", routeName)
+	}
+	if found, _ := util.IsResourceEnabled(dynamicClient, routesub.CertificateGVR, api.OpenShiftConsoleNamespace); found {
+		certificateInformer := dynamicInformers.ForResource(routesub.CertificateGVR)
+		ctrl.certificateLister = certificateInformer.Lister()
+		controllerFactory = controllerFactory.WithFilteredEventsInformers(ctrl.isTLSSecretCertificate, certificateInformer.Informer())
+	} else {
+		klog.Infof("certificates resource does not exist in cluster, polling for cert-manager to be installed")
+		util.StartPollAndRestartIfResourceEnabled(dynamicClient, routesub.CertificateGVR, api.OpenShiftConsoleNamespace)
+	}
+	if routeName == api.OpenShiftConsoleRouteName {
+		if found, _ := util.IsResourceEnabled(dynamicClient, routesub.BackendTLSPolicyGVR, api.OpenShiftConsoleNamespace); found {
+			backendTLSPolicyInformer := dynamicInformers.ForResource(routesub.BackendTLSPolicyGVR)
+			ctrl.backendTLSPolicyLister = backendTLSPolicyInformer.Lister()
+			controllerFactory = controllerFactory.WithFilteredEventsInformers(util.IncludeNamesFilter(api.OpenShiftConsoleServiceName), backendTLSPolicyInformer.Informer())
+		}
+	}
+
+	return controllerFactory.ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController(fmt.Sprintf("%sExposureController", strings.Title(routeName)), recorder.WithComponentSuffix(fmt.Sprintf("%s-exposure-controller", routeName)))
+}
+
+func (c *ExposureSyncController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+	updatedOperatorConfig := operatorConfig.DeepCopy()
+
+	switch updatedOperatorConfig.Spec.ManagementState {
+	case operatorsv1.Managed:
+		klog.V(4).Infof("console-operator is in a managed state: syncing %q exposure", c.routeName)
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infof("console-operator is in an unmanaged state: skipping %q exposure sync", c.routeName)
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infof("console-operator is in a removed state: deleting %q ingress and httproute", c.routeName)
+		c.lastExposureMode = ""
+		if err = c.removeIngress(ctx); err != nil {
+			return err
+		}
+		return c.removeHTTPRoute(ctx)
+	default:
+		return fmt.Errorf("unknown state: %v", updatedOperatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	// Same as for routes, alternative ingress takes precedence over the exposure mode.
+	switch c.routeName {
+	case api.OpenShiftConsoleRouteName:
+		if len(operatorConfig.Spec.Ingress.ConsoleURL) != 0 {
+			return statusHandler.FlushAndReturn(nil)
+		}
+	case api.OpenShiftConsoleDownloadsRouteName:
+		if len(operatorConfig.Spec.Ingress.ClientDownloadsURL) != 0 {
+			return statusHandler.FlushAndReturn(nil)
+		}
+	}
+
+	typePrefix := fmt.Sprintf("%sExposureSync", strings.Title(c.routeName))
+	if err := routesub.ValidateExposureMode(updatedOperatorConfig); err != nil {
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded(typePrefix, "InvalidExposureMode", err))
+		return statusHandler.FlushAndReturn(err)
+	}
+
+	ingressConfig, err := c.ingressConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+	ingressController, err := routesub.GetServedIngressController(c.ingressControllerLister, c.namespaceLister, updatedOperatorConfig)
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+	routeConfig := routesub.NewShardedRouteConfig(updatedOperatorConfig, ingressConfig, ingressController, c.routeName)
+
+	exposureMode := routesub.GetExposureMode(updatedOperatorConfig)
+	cleanupErr := c.removeUnusedObjects(ctx, exposureMode)
+	if cleanupErr != nil {
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded(typePrefix, "FailedCleanup", cleanupErr))
+		return statusHandler.FlushAndReturn(cleanupErr)
+	}
+
+	var (
+		exposureErrReason string
+		exposureErr       error
+	)
+	switch exposureMode {
+	case api.ExposureModeIngress:
+		exposureErrReason, exposureErr = c.SyncIngress(ctx, updatedOperatorConfig, routeConfig, controllerContext.Recorder())
+	case api.ExposureModeHTTPRoute:
+		exposureErrReason, exposureErr = c.SyncHTTPRoute(ctx, updatedOperatorConfig, routeConfig, controllerContext.Recorder())
+	}
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded(typePrefix, exposureErrReason, exposureErr))
+
+	return statusHandler.FlushAndReturn(exposureErr)
+}
+
+// SyncIngress applies the Ingress of the component and waits for its admission. The custom
+// TLS secret is copied to the console namespace, where the Ingress can reference it.
+func (c *ExposureSyncController) SyncIngress(ctx context.Context, operatorConfig *operatorsv1.Console, routeConfig *routesub.RouteConfig, recorder events.Recorder) (string, error) {
+	tlsSecretName, reason, err := c.syncIngressTLSSecret(ctx, operatorConfig, routeConfig, recorder)
+	if err != nil {
+		return reason, err
+	}
+
+	// only the console serves TLS, downloads are served over plain HTTP
+	var annotations map[string]string
+	if c.routeName == api.OpenShiftConsoleRouteName {
+		annotations, reason, err = c.syncIngressBackendTLS(ctx, operatorConfig, recorder)
+		if err != nil {
+			return reason, err
+		}
+	}
+
+	requiredIngress := routeConfig.DefaultIngress(operatorConfig.Annotations[api.IngressClassAnnotation], tlsSecretName, annotations)
+	ingress, _, err := routesub.ApplyIngress(ctx, c.ingressClient, requiredIngress)
+	if err != nil {
+		return "FailedIngressApply", err
+	}
+	if !routesub.IsIngressAdmitted(ingress) {
+		return "IngressNotAdmitted", fmt.Errorf("%s ingress is not admitted", ingress.Name)
+	}
+	return "", nil
+}
+
+func (c *ExposureSyncController) syncIngressTLSSecret(ctx context.Context, operatorConfig *operatorsv1.Console, routeConfig *routesub.RouteConfig, recorder events.Recorder) (string, string, error) {
+	secretName := routeConfig.GetExposedTLSSecretName()
+	if len(secretName) == 0 {
+		return "", "", c.removeIngressTLSSecret(ctx)
+	}
+
+	// secrets in the console namespace can be referenced by the Ingress directly,
+	// once the cert-manager Certificate issuing them, if any, is ready
+	if routeConfig.GetTLSSecretNamespace() == api.OpenShiftConsoleNamespace {
+		if err := routesub.CheckCertificateReady(c.certificateLister, secretName); err != nil {
+			return "", "CertificateNotReady", err
+		}
+		if _, err := c.targetNSSecretLister.Secrets(api.OpenShiftConsoleNamespace).Get(secretName); err != nil {
+			return "", "FailedGetCustomTLSSecret", err
+		}
+		return secretName, "", c.removeIngressTLSSecret(ctx)
+	}
+
+	sourceSecret, err := c.configNSSecretLister.Secrets(api.OpenShiftConfigNamespace).Get(secretName)
+	if err != nil {
+		return "", "FailedGetCustomTLSSecret", err
+	}
+	if sourceSecret.Type != corev1.SecretTypeTLS {
+		return "", "InvalidCustomTLSSecret", fmt.Errorf("custom cert secret is not in %q type, instead uses %q type", corev1.SecretTypeTLS, sourceSecret.Type)
+	}
+	if _, err := routesub.GetCustomTLS(sourceSecret); err != nil {
+		return "", "InvalidCustomTLSSecret", err
+	}
+
+	meta := subresourceutil.SharedMeta()
+	meta.Name = routesub.GetIngressTLSSecretName(c.routeName)
+	requiredSecret := &corev1.Secret{
+		ObjectMeta: meta,
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       sourceSecret.Data[corev1.TLSCertKey],
+			corev1.TLSPrivateKeyKey: sourceSecret.Data[corev1.TLSPrivateKeyKey],
+		},
+	}
+	subresourceutil.AddOwnerRef(requiredSecret, subresourceutil.OwnerRefFrom(operatorConfig))
+
+	if _, _, err := resourceapply.ApplySecret(ctx, c.secretClient, recorder, requiredSecret); err != nil {
+		return "", "FailedIngressTLSSecretApply", err
+	}
+	return requiredSecret.Name, "", nil
+}
+
+// syncIngressBackendTLS returns the annotations making the controller of the IngressClass
+// re-encrypt the connection to the console, and syncs the backend CA secret they reference.
+func (c *ExposureSyncController) syncIngressBackendTLS(ctx context.Context, operatorConfig *operatorsv1.Console, recorder events.Recorder) (map[string]string, string, error) {
+	var ingressClass *networkingv1.IngressClass
+	if ingressClassName := operatorConfig.Annotations[api.IngressClassAnnotation]; len(ingressClassName) != 0 {
+		var err error
+		ingressClass, err = c.ingressClassLister.Get(ingressClassName)
+		if err != nil {
+			return nil, "FailedGetIngressClass", err
+		}
+	} else {
+		ingressClasses, err := c.ingressClassLister.List(labels.Everything())
+		if err != nil {
+			return nil, "FailedGetIngressClass", err
+		}
+		ingressClass, err = routesub.GetDefaultIngressClass(ingressClasses)
+		if err != nil {
+			return nil, "NoDefaultIngressClass", err
+		}
+	}
+
+	annotations, usesBackendCA, err := routesub.GetIngressBackendTLSAnnotations(ingressClass.Spec.Controller)
+	if err != nil {
+		return nil, "UnsupportedIngressClassController", err
+	}
+	if !usesBackendCA {
+		return annotations, "", c.removeBackendCASecret(ctx)
+	}
+
+	caBundle, err := c.getServiceCABundle()
+	if err != nil {
+		return nil, "FailedGetServiceCA", err
+	}
+	meta := subresourceutil.SharedMeta()
+	meta.Name = api.BackendCAName
+	requiredSecret := &corev1.Secret{
+		ObjectMeta: meta,
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{"ca.crt": []byte(caBundle)},
+	}
+	subresourceutil.AddOwnerRef(requiredSecret, subresourceutil.OwnerRefFrom(operatorConfig))
+	if _, _, err := resourceapply.ApplySecret(ctx, c.secretClient, recorder, requiredSecret); err != nil {
+		return nil, "FailedBackendCAApply", err
+	}
+	return annotations, "", nil
+}
+
+// getServiceCABundle returns the service CA, which signs the serving certificate of the console.
+func (c *ExposureSyncController) getServiceCABundle() (string, error) {
+	serviceCA, err := c.configMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.ServiceCAConfigMapName)
+	if err != nil {
+		return "", err
+	}
+	caBundle := serviceCA.Data["service-ca.crt"]
+	if len(caBundle) == 0 {
+		return "", fmt.Errorf("%s configmap has no service CA injected yet", api.ServiceCAConfigMapName)
+	}
+	return caBundle, nil
+}
+
+// SyncHTTPRoute applies the HTTPRoute of the component and waits until the referenced
+// Gateway accepts it. The Gateway re-encrypts the connection to the console as set by
+// the BackendTLSPolicy.
+func (c *ExposureSyncController) SyncHTTPRoute(ctx context.Context, operatorConfig *operatorsv1.Console, routeConfig *routesub.RouteConfig, recorder events.Recorder) (string, error) {
+	gatewayNamespace, gatewayName, err := routesub.GetGatewayRef(operatorConfig)
+	if err != nil {
+		return "InvalidGatewayReference", err
+	}
+
+	if c.routeName == api.OpenShiftConsoleRouteName {
+		if reason, err := c.syncBackendTLSPolicy(ctx, operatorConfig, recorder); err != nil {
+			return reason, err
+		}
+	}
+
+	requiredHTTPRoute := routeConfig.DefaultHTTPRoute(gatewayNamespace, gatewayName)
+	httpRoute, err := c.applyUnstructured(ctx, recorder, requiredHTTPRoute, routesub.HTTPRouteGVR, c.httpRouteLister)
+	if err != nil {
+		return "FailedHTTPRouteApply", err
+	}
+	if !routesub.IsHTTPRouteAccepted(httpRoute, gatewayNamespace, gatewayName) {
+		return "HTTPRouteNotAccepted", fmt.Errorf("%s httproute is not accepted by %s/%s gateway", httpRoute.GetName(), gatewayNamespace, gatewayName)
+	}
+	return "", nil
+}
+
+// syncBackendTLSPolicy applies the BackendTLSPolicy of the console service together with the
+// copy of the service CA it references, since the Gateway API expects the CA under "ca.crt".
+func (c *ExposureSyncController) syncBackendTLSPolicy(ctx context.Context, operatorConfig *operatorsv1.Console, recorder events.Recorder) (string, error) {
+	caBundle, err := c.getServiceCABundle()
+	if err != nil {
+		return "FailedGetServiceCA", err
+	}
+	meta := subresourceutil.SharedMeta()
+	meta.Name = api.BackendCAName
+	requiredConfigMap := &corev1.ConfigMap{
+		ObjectMeta: meta,
+		Data:       map[string]string{"ca.crt": caBundle},
+	}
+	subresourceutil.AddOwnerRef(requiredConfigMap, subresourceutil.OwnerRefFrom(operatorConfig))
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, requiredConfigMap); err != nil {
+		return "FailedBackendCAApply", err
+	}
+
+	if _, err := c.applyUnstructured(ctx, recorder, routesub.DefaultBackendTLSPolicy(), routesub.BackendTLSPolicyGVR, c.backendTLSPolicyLister); err != nil {
+		return "FailedBackendTLSPolicyApply", err
+	}
+	return "", nil
+}
+
+// applyUnstructured applies the Gateway API object, unless the informer shows it unchanged
+// since it was last applied. Without an informer the object is applied on every sync.
+func (c *ExposureSyncController) applyUnstructured(ctx context.Context, recorder events.Recorder, required *unstructured.Unstructured, resource schema.GroupVersionResource, lister cache.GenericLister) (*unstructured.Unstructured, error) {
+	if lister != nil {
+		obj, err := lister.ByNamespace(required.GetNamespace()).Get(required.GetName())
+		if existing, ok := obj.(*unstructured.Unstructured); err == nil && ok && c.resourceCache.SafeToSkipApply(required, existing) {
+			return existing, nil
+		}
+	}
+	actual, _, err := resourceapply.ApplyUnstructuredResourceImproved(ctx, c.dynamicClient, recorder, required, c.resourceCache, resource, nil, nil)
+	return actual, err
+}
+
+// isTargetNSSecret returns true for the secrets the Ingress references in the openshift-console
+// namespace: the copy of the custom TLS secret or the custom TLS secret itself, and the
+// backend CA.
+func (c *ExposureSyncController) isTargetNSSecret(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	metaObj, ok := obj.(metav1.Object)
+	if !ok {
+		klog.Errorf("Unexpected type %T", obj)
+		return false
+	}
+	switch name := metaObj.GetName(); name {
+	case routesub.GetIngressTLSSecretName(c.routeName), api.BackendCAName:
+		return true
+	default:
+		return c.isTLSSecretName(name)
+	}
+}
+
+// isTLSSecretCertificate returns true for the cert-manager Certificate issuing the custom
+// TLS secret of the exposed host.
+func (c *ExposureSyncController) isTLSSecretCertificate(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	certificate, ok := obj.(*unstructured.Unstructured)
+	return ok && c.isTLSSecretName(routesub.GetCertificateSecretName(certificate))
+}
+
+// isTLSSecretName returns true if the custom TLS secret of the exposed host is read from
+// the openshift-console namespace under the given name.
+func (c *ExposureSyncController) isTLSSecretName(name string) bool {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return false
+	}
+	ingressConfig, err := c.ingressConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return false
+	}
+	ingressController, err := routesub.GetServedIngressController(c.ingressControllerLister, c.namespaceLister, operatorConfig)
+	if err != nil {
+		return false
+	}
+	routeConfig := routesub.NewShardedRouteConfig(operatorConfig, ingressConfig, ingressController, c.routeName)
+	if routeConfig.GetTLSSecretNamespace() != api.OpenShiftConsoleNamespace || len(name) == 0 {
+		return false
+	}
+	return name == routeConfig.GetExposedTLSSecretName()
+}
+
+// removeUnusedObjects deletes the objects created for a previously used exposure mode.
+// The check is skipped until the mode changes, so the HTTPRoute API is not queried on
+// every resync of clusters which don't use it.
+func (c *ExposureSyncController) removeUnusedObjects(ctx context.Context, exposureMode string) error {
+	if exposureMode == c.lastExposureMode {
+		return nil
+	}
+	if exposureMode != api.ExposureModeIngress {
+		if err := c.removeIngress(ctx); err != nil {
+			return err
+		}
+	}
+	if exposureMode != api.ExposureModeHTTPRoute {
+		if err := c.removeHTTPRoute(ctx); err != nil {
+			return err
+		}
+	}
+	c.lastExposureMode = exposureMode
+	return nil
+}
+
+func (c *ExposureSyncController) removeIngress(ctx context.Context) error {
+	if err := c.removeIngressTLSSecret(ctx); err != nil {
+		return err
+	}
+	if err := c.removeBackendCASecret(ctx); err != nil {
+		return err
+	}
+	if _, err := c.ingressLister.Ingresses(api.OpenShiftConsoleNamespace).Get(c.routeName); apierrors.IsNotFound(err) {
+		return nil
+	}
+	err := c.ingressClient.Ingresses(api.OpenShiftConsoleNamespace).Delete(ctx, c.routeName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *ExposureSyncController) removeIngressTLSSecret(ctx context.Context) error {
+	secretName := routesub.GetIngressTLSSecretName(c.routeName)
+	if _, err := c.targetNSSecretLister.Secrets(api.OpenShiftConsoleNamespace).Get(secretName); apierrors.IsNotFound(err) {
+		return nil
+	}
+	err := c.secretClient.Secrets(api.OpenShiftConsoleNamespace).Delete(ctx, secretName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *ExposureSyncController) removeBackendCASecret(ctx context.Context) error {
+	if c.routeName != api.OpenShiftConsoleRouteName {
+		return nil
+	}
+	if _, err := c.targetNSSecretLister.Secrets(api.OpenShiftConsoleNamespace).Get(api.BackendCAName); apierrors.IsNotFound(err) {
+		return nil
+	}
+	err := c.secretClient.Secrets(api.OpenShiftConsoleNamespace).Delete(ctx, api.BackendCAName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// removeHTTPRoute deletes the HTTPRoute of the component, and the BackendTLSPolicy of the
+// console. A missing Gateway API is not an error, there is nothing to remove in that case.
+func (c *ExposureSyncController) removeHTTPRoute(ctx context.Context) error {
+	err := c.dynamicClient.Resource(routesub.HTTPRouteGVR).Namespace(api.OpenShiftConsoleNamespace).Delete(ctx, c.routeName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if c.routeName != api.OpenShiftConsoleRouteName {
+		return nil
+	}
+	err = c.dynamicClient.Resource(routesub.BackendTLSPolicyGVR).Namespace(api.OpenShiftConsoleNamespace).Delete(ctx, api.OpenShiftConsoleServiceName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if _, err := c.configMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.BackendCAName); apierrors.IsNotFound(err) {
+		return nil
+	}
+	err = c.configMapClient.ConfigMaps(api.OpenShiftConsoleNamespace).Delete(ctx, api.BackendCAName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
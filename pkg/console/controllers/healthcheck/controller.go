@@ -11,9 +11,12 @@ import (
 	"time"
 
 	// k8s
-
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
@@ -44,11 +47,15 @@ import (
 type HealthCheckController struct {
 	// clients
 	operatorClient             v1helpers.OperatorClient
+	namespaceLister            corev1listers.NamespaceLister
 	infrastructureConfigLister configlistersv1.InfrastructureLister
 	configMapLister            corev1listers.ConfigMapLister
 	routeLister                routev1listers.RouteLister
 	ingressConfigLister        configlistersv1.IngressLister
 	operatorConfigLister       operatorv1listers.ConsoleLister
+	configNSSecretLister       corev1listers.SecretLister
+	ingressControllerLister    operatorv1listers.IngressControllerLister
+	ingressSecretClient        coreclientv1.SecretsGetter
 }
 
 func NewHealthCheckController(
@@ -56,21 +63,29 @@ func NewHealthCheckController(
 	configClient configclientv1.ConfigV1Interface,
 	// clients
 	operatorClient v1helpers.OperatorClient,
+	ingressSecretClient coreclientv1.SecretsGetter, // default certificates of the IngressController shards
 	// informers
 	operatorConfigInformer v1.ConsoleInformer,
 	configInformer configinformer.SharedInformerFactory,
 	coreInformer coreinformersv1.Interface,
+	configNSSecretInformer coreinformersv1.SecretInformer,
 	routeInformer routesinformersv1.RouteInformer,
+	ingressControllerInformer v1.IngressControllerInformer,
+	namespaceInformer coreinformersv1.NamespaceInformer,
 	// events
 	recorder events.Recorder,
 ) factory.Controller {
 	ctrl := &HealthCheckController{
 		operatorClient:             operatorClient,
+		namespaceLister:            namespaceInformer.Lister(),
 		operatorConfigLister:       operatorConfigInformer.Lister(),
 		infrastructureConfigLister: configInformer.Config().V1().Infrastructures().Lister(),
 		ingressConfigLister:        configInformer.Config().V1().Ingresses().Lister(),
 		routeLister:                routeInformer.Lister(),
 		configMapLister:            coreInformer.ConfigMaps().Lister(),
+		configNSSecretLister:       configNSSecretInformer.Lister(),
+		ingressControllerLister:    ingressControllerInformer.Lister(),
+		ingressSecretClient:        ingressSecretClient,
 	}
 
 	configMapInformer := coreInformer.ConfigMaps()
@@ -87,6 +102,9 @@ func NewHealthCheckController(
 	).WithFilteredEventsInformers( // route
 		util.IncludeNamesFilter(api.OpenShiftConsoleRouteName, api.OpenshiftConsoleCustomRouteName),
 		routeInformer.Informer(),
+	).WithInformers( // ingress controllers and the console namespace selected by the shards
+		ingressControllerInformer.Informer(),
+		namespaceInformer.Informer(),
 	).ResyncEvery(30*time.Second).WithSync(ctrl.Sync).
 		ToController("HealthCheckController", recorder.WithComponentSuffix("health-check-controller"))
 }
@@ -131,19 +149,28 @@ func (c *HealthCheckController) Sync(ctx context.Context, controllerContext fact
 	}
 
 	activeRouteName := api.OpenShiftConsoleRouteName
-	routeConfig := routesub.NewRouteConfig(updatedOperatorConfig, ingressConfig, activeRouteName)
+	ingressController, err := routesub.GetServedIngressController(c.ingressControllerLister, c.namespaceLister, updatedOperatorConfig)
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+	routeConfig := routesub.NewShardedRouteConfig(updatedOperatorConfig, ingressConfig, ingressController, activeRouteName)
 	if routeConfig.IsCustomHostnameSet() {
 		activeRouteName = api.OpenshiftConsoleCustomRouteName
 	}
 
-	activeRoute, activeRouteErr := c.routeLister.Routes(api.OpenShiftConsoleNamespace).Get(activeRouteName)
-	statusHandler.AddConditions(status.HandleProgressingOrDegraded("RouteHealth", "FailedRouteGet", activeRouteErr))
-	if activeRouteErr != nil {
-		klog.V(4).Infof("failed getting %q route for performing health check: %v", activeRouteName, activeRouteErr)
-		return statusHandler.FlushAndReturn(activeRouteErr)
+	// no route exists if the console is exposed through an Ingress or an HTTPRoute
+	var activeRoute *routev1.Route
+	if routesub.GetExposureMode(updatedOperatorConfig) == api.ExposureModeRoute {
+		var activeRouteErr error
+		activeRoute, activeRouteErr = c.routeLister.Routes(api.OpenShiftConsoleNamespace).Get(activeRouteName)
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("RouteHealth", "FailedRouteGet", activeRouteErr))
+		if activeRouteErr != nil {
+			klog.V(4).Infof("failed getting %q route for performing health check: %v", activeRouteName, activeRouteErr)
+			return statusHandler.FlushAndReturn(activeRouteErr)
+		}
 	}
 
-	routeHealthCheckErrReason, routeHealthCheckErr := c.CheckRouteHealth(ctx, updatedOperatorConfig, activeRoute)
+	routeHealthCheckErrReason, routeHealthCheckErr := c.CheckRouteHealth(ctx, updatedOperatorConfig, ingressController, routeConfig, activeRoute)
 	if routeHealthCheckErr != nil {
 		klog.V(4).Infof("failed to performing health check: %v", routeHealthCheckErr)
 	}
@@ -153,7 +180,9 @@ func (c *HealthCheckController) Sync(ctx context.Context, controllerContext fact
 	return statusHandler.FlushAndReturn(routeHealthCheckErr)
 }
 
-func (c *HealthCheckController) CheckRouteHealth(ctx context.Context, operatorConfig *operatorsv1.Console, route *routev1.Route) (string, error) {
+// CheckRouteHealth checks the health endpoint of the console, exposed either through the
+// given route or, if the route is nil, through an Ingress or an HTTPRoute.
+func (c *HealthCheckController) CheckRouteHealth(ctx context.Context, operatorConfig *operatorsv1.Console, ingressController *operatorsv1.IngressController, routeConfig *routesub.RouteConfig, route *routev1.Route) (string, error) {
 	var reason string
 	healthCheckBackoff := wait.Backoff{
 		Steps:    10,
@@ -169,7 +198,9 @@ func (c *HealthCheckController) CheckRouteHealth(ctx context.Context, operatorCo
 				url *url.URL
 				err error
 			)
-			if len(operatorConfig.Spec.Ingress.ConsoleURL) == 0 {
+			if len(operatorConfig.Spec.Ingress.ConsoleURL) == 0 && route == nil {
+				url = routeConfig.GetExposedURL()
+			} else if len(operatorConfig.Spec.Ingress.ConsoleURL) == 0 {
 				url, _, err = routeapihelpers.IngressURI(route, route.Spec.Host)
 				if err != nil {
 					reason = "RouteNotAdmitted"
@@ -177,6 +208,12 @@ func (c *HealthCheckController) CheckRouteHealth(ctx context.Context, operatorCo
 					logHealthCheckError(errStr)
 					return errors.New(errStr)
 				}
+				if ingressControllerName := routesub.GetRouterName(ingressController); !routesub.IsAdmittedByIngressController(route, ingressControllerName) {
+					reason = "RouteNotAdmittedByIngressController"
+					errStr := fmt.Sprintf("%s route is not admitted by %q ingress controller", route.Name, ingressControllerName)
+					logHealthCheckError(errStr)
+					return errors.New(errStr)
+				}
 			} else {
 				url, err = url.Parse(operatorConfig.Spec.Ingress.ConsoleURL)
 				if err != nil {
@@ -187,14 +224,32 @@ func (c *HealthCheckController) CheckRouteHealth(ctx context.Context, operatorCo
 				}
 			}
 
-			caPool, err := c.getCA(ctx, route.Spec.TLS)
+			var routeTLS *routev1.TLSConfig
+			if route != nil {
+				routeTLS = route.Spec.TLS
+			}
+			ingressCert, err := getIngressCertificate(ctx, c.ingressSecretClient, ingressController)
+			if err != nil {
+				reason = "FailedLoadIngressCertificate"
+				errStr := fmt.Sprintf("failed to read ingress controller certificate to check route health: %v", err)
+				logHealthCheckError(errStr)
+				return errors.New(errStr)
+			}
+			caPool, err := getCA(c.configMapLister, routeTLS, ingressCert, routesub.GetExposureMode(operatorConfig))
 			if err != nil {
 				reason = "FailedLoadCA"
 				errStr := fmt.Sprintf("failed to read CA to check route health: %v", err)
 				logHealthCheckError(errStr)
 				return errors.New(errStr)
 			}
-			client := clientWithCA(caPool)
+			clientCert, err := getClientCertificate(c.configNSSecretLister, operatorConfig)
+			if err != nil {
+				reason = "FailedLoadClientCertificate"
+				errStr := fmt.Sprintf("failed to read client certificate to check route health: %v", err)
+				logHealthCheckError(errStr)
+				return errors.New(errStr)
+			}
+			client := clientWithCA(caPool, clientCert)
 
 			req, err := http.NewRequest(http.MethodGet, url.String(), nil)
 			if err != nil {
@@ -225,7 +280,13 @@ func (c *HealthCheckController) CheckRouteHealth(ctx context.Context, operatorCo
 	return reason, err
 }
 
-func (c *HealthCheckController) getCA(ctx context.Context, tls *routev1.TLSConfig) (*x509.CertPool, error) {
+// getCA returns the CA pool the console and downloads hosts are verified with. The default
+// certificate of an IngressController shard is trusted instead of the default ingress
+// certificate, since the shard serves the routes with it. Hosts exposed through an Ingress
+// or an HTTPRoute may be served by another ingress controller or a Gateway, on clusters
+// without the default ingress certificate, so it is only trusted there if it exists. Their
+// CA is expected in the trusted CA bundle.
+func getCA(configMapLister corev1listers.ConfigMapLister, tls *routev1.TLSConfig, ingressCert []byte, exposureMode string) (*x509.CertPool, error) {
 	caCertPool := x509.NewCertPool()
 
 	if tls != nil && len(tls.Certificate) != 0 {
@@ -234,8 +295,19 @@ func (c *HealthCheckController) getCA(ctx context.Context, tls *routev1.TLSConfi
 		}
 	}
 
-	for _, cmName := range []string{api.TrustedCAConfigMapName, api.DefaultIngressCertConfigMapName} {
-		cm, err := c.configMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(cmName)
+	cmNames := []string{api.TrustedCAConfigMapName, api.DefaultIngressCertConfigMapName}
+	if len(ingressCert) != 0 {
+		if ok := caCertPool.AppendCertsFromPEM(ingressCert); !ok {
+			klog.V(4).Infof("failed to parse ingress controller tls.crt")
+		}
+		cmNames = []string{api.TrustedCAConfigMapName}
+	}
+
+	for _, cmName := range cmNames {
+		cm, err := configMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(cmName)
+		if apierrors.IsNotFound(err) && cmName == api.DefaultIngressCertConfigMapName && exposureMode != api.ExposureModeRoute {
+			continue
+		}
 		if err != nil {
 			klog.V(4).Infof("failed to GET configmap %s / %s ", api.OpenShiftConsoleNamespace, cmName)
 			return nil, err
@@ -248,14 +320,53 @@ func (c *HealthCheckController) getCA(ctx context.Context, tls *routev1.TLSConfi
 	return caCertPool, nil
 }
 
-func clientWithCA(caPool *x509.CertPool) *http.Client {
+// getIngressCertificate returns the default certificate of the IngressController shard the
+// routes are placed on, or nil if the shard uses the certificate generated by the ingress
+// operator, which is signed by the CA in the default ingress certificate. The secret is read
+// from the API server, the name is only known from the shard so it can't be watched without
+// watching every secret in the openshift-ingress namespace.
+func getIngressCertificate(ctx context.Context, secretClient coreclientv1.SecretsGetter, ingressController *operatorsv1.IngressController) ([]byte, error) {
+	if ingressController == nil || ingressController.Name == api.DefaultIngressController || ingressController.Spec.DefaultCertificate == nil {
+		return nil, nil
+	}
+	secret, err := secretClient.Secrets(api.OpenShiftIngressNamespace).Get(ctx, ingressController.Spec.DefaultCertificate.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return secret.Data[corev1.TLSCertKey], nil
+}
+
+// getClientCertificate returns the client certificate referenced by the health check
+// client certificate annotation of the operator config, if any. The referenced secret
+// has to be a kubernetes.io/tls secret in the openshift-config namespace.
+func getClientCertificate(secretLister corev1listers.SecretLister, operatorConfig *operatorsv1.Console) (*tls.Certificate, error) {
+	secretName := operatorConfig.Annotations[api.HealthCheckClientCertAnnotation]
+	if secretName == "" {
+		return nil, nil
+	}
+	secret, err := secretLister.Secrets(api.OpenShiftConfigNamespace).Get(secretName)
+	if err != nil {
+		return nil, err
+	}
+	clientCert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s/%s client certificate: %w", secret.Namespace, secret.Name, err)
+	}
+	return &clientCert, nil
+}
+
+func clientWithCA(caPool *x509.CertPool, clientCert *tls.Certificate) *http.Client {
+	tlsConfig := &tls.Config{
+		RootCAs: caPool,
+	}
+	if clientCert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*clientCert}
+	}
 	return &http.Client{
 		Timeout: 5 * time.Second,
 		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			TLSClientConfig: &tls.Config{
-				RootCAs: caPool,
-			},
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
 		},
 	}
 }
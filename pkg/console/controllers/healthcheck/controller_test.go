@@ -1,10 +1,19 @@
 package healthcheck
 
 import (
+	"context"
 	"testing"
 
 	"github.com/go-test/deep"
 	configv1 "github.com/openshift/api/config/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/console-operator/pkg/api"
 )
 
 func TestGetPlatformURL(t *testing.T) {
@@ -100,3 +109,95 @@ func TestGetPlatformURL(t *testing.T) {
 		})
 	}
 }
+
+func TestGetIngressCertificate(t *testing.T) {
+	secretClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "shard-cert", Namespace: api.OpenShiftIngressNamespace},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("shard-cert-pem")},
+	}).CoreV1()
+
+	ingressController := func(name string, defaultCertificate string) *operatorsv1.IngressController {
+		ingressController := &operatorsv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if defaultCertificate != "" {
+			ingressController.Spec.DefaultCertificate = &corev1.LocalObjectReference{Name: defaultCertificate}
+		}
+		return ingressController
+	}
+	tests := []struct {
+		name              string
+		ingressController *operatorsv1.IngressController
+		want              []byte
+		wantErr           bool
+	}{
+		{
+			name: "No ingress controller",
+		},
+		{
+			name:              "Default ingress controller",
+			ingressController: ingressController(api.DefaultIngressController, "shard-cert"),
+		},
+		{
+			name:              "Shard with the generated certificate",
+			ingressController: ingressController("shard", ""),
+		},
+		{
+			name:              "Shard with its own default certificate",
+			ingressController: ingressController("shard", "shard-cert"),
+			want:              []byte("shard-cert-pem"),
+		},
+		{
+			name:              "Missing default certificate",
+			ingressController: ingressController("shard", "missing"),
+			wantErr:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getIngressCertificate(context.TODO(), secretClient, tt.ingressController)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetCA(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := indexer.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: api.TrustedCAConfigMapName, Namespace: api.OpenShiftConsoleNamespace},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	configMapLister := corev1listers.NewConfigMapLister(indexer)
+
+	tests := []struct {
+		name         string
+		exposureMode string
+		wantErr      bool
+	}{
+		{
+			name:         "Route requires the default ingress certificate",
+			exposureMode: api.ExposureModeRoute,
+			wantErr:      true,
+		},
+		{
+			name:         "Ingress without the default ingress certificate",
+			exposureMode: api.ExposureModeIngress,
+		},
+		{
+			name:         "HTTPRoute without the default ingress certificate",
+			exposureMode: api.ExposureModeHTTPRoute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := getCA(configMapLister, nil, nil, tt.exposureMode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
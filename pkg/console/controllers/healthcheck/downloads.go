@@ -0,0 +1,262 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	// k8s
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	consoleinformersv1 "github.com/openshift/client-go/console/informers/externalversions/console/v1"
+	consolev1listers "github.com/openshift/client-go/console/listers/console/v1"
+	v1 "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	routesinformersv1 "github.com/openshift/client-go/route/informers/externalversions/route/v1"
+	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"github.com/openshift/library-go/pkg/route/routeapihelpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
+)
+
+// DownloadsHealthCheckController probes the downloads route (or the configured
+// ClientDownloadsURL) and every artifact linked from the oc-cli-downloads
+// ConsoleCLIDownload, reporting DownloadsHealthDegraded for any that are broken.
+type DownloadsHealthCheckController struct {
+	// clients
+	operatorClient             v1helpers.OperatorClient
+	namespaceLister            corev1listers.NamespaceLister
+	infrastructureConfigLister configlistersv1.InfrastructureLister
+	configMapLister            corev1listers.ConfigMapLister
+	routeLister                routev1listers.RouteLister
+	ingressConfigLister        configlistersv1.IngressLister
+	operatorConfigLister       operatorv1listers.ConsoleLister
+	cliDownloadsLister         consolev1listers.ConsoleCLIDownloadLister
+	configNSSecretLister       corev1listers.SecretLister
+	ingressControllerLister    operatorv1listers.IngressControllerLister
+	ingressSecretClient        coreclientv1.SecretsGetter
+}
+
+func NewDownloadsHealthCheckController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	ingressSecretClient coreclientv1.SecretsGetter, // default certificates of the IngressController shards
+	// informers
+	operatorConfigInformer v1.ConsoleInformer,
+	configInformer configinformer.SharedInformerFactory,
+	coreInformer coreinformersv1.Interface,
+	configNSSecretInformer coreinformersv1.SecretInformer,
+	routeInformer routesinformersv1.RouteInformer,
+	cliDownloadsInformer consoleinformersv1.ConsoleCLIDownloadInformer,
+	ingressControllerInformer v1.IngressControllerInformer,
+	namespaceInformer coreinformersv1.NamespaceInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &DownloadsHealthCheckController{
+		operatorClient:             operatorClient,
+		namespaceLister:            namespaceInformer.Lister(),
+		operatorConfigLister:       operatorConfigInformer.Lister(),
+		infrastructureConfigLister: configInformer.Config().V1().Infrastructures().Lister(),
+		ingressConfigLister:        configInformer.Config().V1().Ingresses().Lister(),
+		routeLister:                routeInformer.Lister(),
+		configMapLister:            coreInformer.ConfigMaps().Lister(),
+		cliDownloadsLister:         cliDownloadsInformer.Lister(),
+		configNSSecretLister:       configNSSecretInformer.Lister(),
+		ingressControllerLister:    ingressControllerInformer.Lister(),
+		ingressSecretClient:        ingressSecretClient,
+	}
+
+	configV1Informers := configInformer.Config().V1()
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			configV1Informers.Ingresses().Informer(),
+		).WithFilteredEventsInformers( // service
+		util.IncludeNamesFilter(api.TrustedCAConfigMapName, api.DefaultIngressCertConfigMapName),
+		coreInformer.ConfigMaps().Informer(),
+	).WithFilteredEventsInformers( // route
+		util.IncludeNamesFilter(api.OpenShiftConsoleDownloadsRouteName, api.OpenshiftDownloadsCustomRouteName),
+		routeInformer.Informer(),
+	).WithFilteredEventsInformers( // console cli downloads
+		util.IncludeNamesFilter(api.OCCLIDownloadsCustomResourceName),
+		cliDownloadsInformer.Informer(),
+	).WithInformers( // ingress controllers and the console namespace selected by the shards
+		ingressControllerInformer.Informer(),
+		namespaceInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("DownloadsHealthCheckController", recorder.WithComponentSuffix("downloads-health-check-controller"))
+}
+
+func (c *DownloadsHealthCheckController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		klog.Errorf("operator config error: %v", err)
+		return statusHandler.FlushAndReturn(err)
+	}
+
+	updatedOperatorConfig := operatorConfig.DeepCopy()
+
+	switch updatedOperatorConfig.Spec.ManagementState {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: starting downloads health checks")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping downloads health checks")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: skipping downloads health checks")
+		return nil
+	default:
+		return fmt.Errorf("unknown state: %v", updatedOperatorConfig.Spec.ManagementState)
+	}
+	ingressConfig, err := c.ingressConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		klog.Errorf("ingress config error: %v", err)
+		return statusHandler.FlushAndReturn(err)
+	}
+	infrastructureConfig, err := c.infrastructureConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		klog.Errorf("infrastructure config error: %v", err)
+		return statusHandler.FlushAndReturn(err)
+	}
+
+	// Same as for the console route, the downloads route can not be reached from
+	// within the cluster for external control plane topology with ingress NLB.
+	if isExternalControlPlaneWithNLB(infrastructureConfig, ingressConfig) {
+		return nil
+	}
+
+	ingressController, err := routesub.GetServedIngressController(c.ingressControllerLister, c.namespaceLister, updatedOperatorConfig)
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+	downloadsURL, routeTLS, reason, err := c.getDownloadsURL(updatedOperatorConfig, ingressConfig, ingressController)
+	if err == nil {
+		reason, err = c.CheckDownloadsHealth(ctx, updatedOperatorConfig, ingressController, downloadsURL, routeTLS)
+	}
+	if err != nil {
+		klog.V(4).Infof("downloads health check failed: %v", err)
+	}
+	statusHandler.AddCondition(status.HandleDegraded("DownloadsHealth", reason, err))
+
+	return statusHandler.FlushAndReturn(err)
+}
+
+// getDownloadsURL returns the URL the downloads server is exposed on, together with
+// the TLS config of the route serving it, if any.
+func (c *DownloadsHealthCheckController) getDownloadsURL(operatorConfig *operatorsv1.Console, ingressConfig *configv1.Ingress, ingressController *operatorsv1.IngressController) (*url.URL, *routev1.TLSConfig, string, error) {
+	if len(operatorConfig.Spec.Ingress.ClientDownloadsURL) != 0 {
+		downloadsURL, err := url.Parse(operatorConfig.Spec.Ingress.ClientDownloadsURL)
+		if err != nil {
+			return nil, nil, "FailedParseDownloadsURL", fmt.Errorf("failed to parse downloads url: %w", err)
+		}
+		return downloadsURL, nil, "", nil
+	}
+
+	activeRouteName := api.OpenShiftConsoleDownloadsRouteName
+	routeConfig := routesub.NewShardedRouteConfig(operatorConfig, ingressConfig, ingressController, activeRouteName)
+	if routeConfig.IsCustomHostnameSet() {
+		activeRouteName = api.OpenshiftDownloadsCustomRouteName
+	}
+	if routesub.GetExposureMode(operatorConfig) != api.ExposureModeRoute {
+		return routeConfig.GetExposedURL(), nil, "", nil
+	}
+
+	downloadsRoute, err := c.routeLister.Routes(api.OpenShiftConsoleNamespace).Get(activeRouteName)
+	if err != nil {
+		return nil, nil, "FailedRouteGet", fmt.Errorf("failed getting %q route: %w", activeRouteName, err)
+	}
+
+	downloadsURL, _, err := routeapihelpers.IngressURI(downloadsRoute, downloadsRoute.Spec.Host)
+	if err != nil {
+		return nil, nil, "RouteNotAdmitted", fmt.Errorf("%s route is not admitted", downloadsRoute.Name)
+	}
+	if ingressControllerName := routesub.GetRouterName(ingressController); !routesub.IsAdmittedByIngressController(downloadsRoute, ingressControllerName) {
+		return nil, nil, "RouteNotAdmittedByIngressController", fmt.Errorf("%s route is not admitted by %q ingress controller", downloadsRoute.Name, ingressControllerName)
+	}
+	return downloadsURL, downloadsRoute.Spec.TLS, "", nil
+}
+
+// CheckDownloadsHealth verifies that the downloads server responds and that every
+// link of the oc-cli-downloads ConsoleCLIDownload is reachable.
+func (c *DownloadsHealthCheckController) CheckDownloadsHealth(ctx context.Context, operatorConfig *operatorsv1.Console, ingressController *operatorsv1.IngressController, downloadsURL *url.URL, routeTLS *routev1.TLSConfig) (string, error) {
+	cliDownloads, err := c.cliDownloadsLister.Get(api.OCCLIDownloadsCustomResourceName)
+	if err != nil {
+		return "FailedGetCLIDownloads", fmt.Errorf("failed to get %s consoleclidownloads: %w", api.OCCLIDownloadsCustomResourceName, err)
+	}
+
+	ingressCert, err := getIngressCertificate(ctx, c.ingressSecretClient, ingressController)
+	if err != nil {
+		return "FailedLoadIngressCertificate", fmt.Errorf("failed to read ingress controller certificate to check downloads health: %w", err)
+	}
+	caPool, err := getCA(c.configMapLister, routeTLS, ingressCert, routesub.GetExposureMode(operatorConfig))
+	if err != nil {
+		return "FailedLoadCA", fmt.Errorf("failed to read CA to check downloads health: %w", err)
+	}
+	clientCert, err := getClientCertificate(c.configNSSecretLister, operatorConfig)
+	if err != nil {
+		return "FailedLoadClientCertificate", fmt.Errorf("failed to read client certificate to check downloads health: %w", err)
+	}
+	client := clientWithCA(caPool, clientCert)
+
+	if err := checkURL(client, downloadsURL.String()); err != nil {
+		return "FailedGet", err
+	}
+
+	if broken := brokenDownloadLinks(client, cliDownloads.Spec.Links); len(broken) > 0 {
+		return "BrokenDownloadLinks", fmt.Errorf("%d of %d %s download links are broken: %s", len(broken), len(cliDownloads.Spec.Links), api.OCCLIDownloadsCustomResourceName, strings.Join(broken, "; "))
+	}
+	return "", nil
+}
+
+// brokenDownloadLinks returns a description of every link that does not respond with 200.
+func brokenDownloadLinks(client *http.Client, links []consolev1.CLIDownloadLink) []string {
+	broken := []string{}
+	for _, link := range links {
+		if err := checkURL(client, link.Href); err != nil {
+			broken = append(broken, fmt.Sprintf("%q: %v", link.Text, err))
+		}
+	}
+	return broken
+}
+
+// checkURL issues a HEAD request, so the archives are not transferred on every probe.
+func checkURL(client *http.Client, rawURL string) error {
+	req, err := http.NewRequest(http.MethodHead, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request to %s: %v", rawURL, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %v", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returns '%s'", rawURL, resp.Status)
+	}
+	return nil
+}
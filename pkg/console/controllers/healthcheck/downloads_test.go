@@ -0,0 +1,54 @@
+package healthcheck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-test/deep"
+	consolev1 "github.com/openshift/api/console/v1"
+)
+
+func TestBrokenDownloadLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/amd64/linux/oc.tar", "/oc-license":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		links []consolev1.CLIDownloadLink
+		want  []string
+	}{
+		{
+			name: "All links are reachable",
+			links: []consolev1.CLIDownloadLink{
+				{Text: "Download oc for Linux for x86_64", Href: server.URL + "/amd64/linux/oc.tar"},
+				{Text: "LICENSE", Href: server.URL + "/oc-license"},
+			},
+			want: []string{},
+		},
+		{
+			name: "Missing archive is reported",
+			links: []consolev1.CLIDownloadLink{
+				{Text: "Download oc for Linux for x86_64", Href: server.URL + "/amd64/linux/oc.tar"},
+				{Text: "Download oc for Mac for ARM 64", Href: server.URL + "/arm64/mac/oc.zip"},
+			},
+			want: []string{
+				`"Download oc for Mac for ARM 64": ` + server.URL + `/arm64/mac/oc.zip returns '404 Not Found'`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(brokenDownloadLinks(server.Client(), tt.links), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package impersonation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	// k8s
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+)
+
+var groupGVR = userv1.GroupVersion.WithResource("groups")
+
+// ImpersonationController validates the impersonation config of the console. Admins set it
+// by annotating the operator config:
+//
+//	console.openshift.io/impersonation: Restricted
+//	console.openshift.io/impersonation-groups: cluster-admins,support
+//	console.openshift.io/impersonation-audit-annotation: console.openshift.io/impersonation-reason
+//
+// The groups allowed to impersonate are checked against the user.openshift.io Groups, unless
+// users log in through an external OIDC provider, whose tokens carry the groups instead.
+//
+// ==========
+//
+//	writes:
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=ImpersonationConfigDegraded
+//		- type=ImpersonationGroupsNotFound
+type ImpersonationController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	authnConfigLister    configv1listers.AuthenticationLister
+	dynamicClient        dynamic.Interface
+}
+
+func NewImpersonationController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	dynamicClient dynamic.Interface,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	authnConfigInformer configv1informers.AuthenticationInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &ImpersonationController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		authnConfigLister:    authnConfigInformer.Lister(),
+		dynamicClient:        dynamicClient,
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			authnConfigInformer.Informer(),
+		).
+		// groups aren't watched, a group created after the config is found on the next resync
+		ResyncEvery(10*time.Minute).WithSync(ctrl.Sync).
+		ToController("ImpersonationController", recorder.WithComponentSuffix("impersonation-controller"))
+}
+
+func (c *ImpersonationController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch operatorConfig.Spec.ManagementState {
+	case operatorv1.Managed:
+		klog.V(4).Info("console-operator is in a managed state: validating impersonation config")
+	case operatorv1.Unmanaged:
+		klog.V(4).Info("console-operator is in an unmanaged state: skipping impersonation config validation")
+		return nil
+	case operatorv1.Removed:
+		klog.V(4).Info("console-operator is in a removed state: skipping impersonation config validation")
+		return nil
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	impersonation, impersonationErr := consoleserver.GetImpersonation(operatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("ImpersonationConfig", "InvalidAnnotation", impersonationErr))
+
+	authnConfig, err := c.authnConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+
+	missingGroups, err := c.getMissingGroups(ctx, authnConfig, impersonation)
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+	// a missing group grants nothing, so the restriction still holds, but it is likely a typo
+	var missingGroupsErr error
+	if len(missingGroups) > 0 {
+		missingGroupsErr = fmt.Errorf("%s references groups which don't exist: %s", api.ImpersonationGroupsAnnotation, strings.Join(missingGroups, ", "))
+	}
+	statusHandler.AddCondition(status.HandleWarning("ImpersonationGroupsNotFound", "GroupsNotFound", missingGroupsErr))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+// getMissingGroups returns the groups allowed to impersonate which don't exist.
+func (c *ImpersonationController) getMissingGroups(ctx context.Context, authnConfig *configv1.Authentication, impersonation *consoleserver.Impersonation) ([]string, error) {
+	if impersonation == nil || impersonation.State != consoleserver.ImpersonationRestricted {
+		return nil, nil
+	}
+	if authnConfig.Spec.Type == configv1.AuthenticationTypeOIDC {
+		klog.V(4).Info("groups are taken from the OIDC token claims: skipping impersonation groups validation")
+		return nil, nil
+	}
+
+	missingGroups := []string{}
+	for _, group := range impersonation.AllowedGroups {
+		_, err := c.dynamicClient.Resource(groupGVR).Get(ctx, group, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			missingGroups = append(missingGroups, group)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return missingGroups, nil
+}
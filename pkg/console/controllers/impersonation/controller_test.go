@@ -0,0 +1,73 @@
+package impersonation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+)
+
+func TestGetMissingGroups(t *testing.T) {
+	group := &unstructured.Unstructured{}
+	group.SetAPIVersion("user.openshift.io/v1")
+	group.SetKind("Group")
+	group.SetName("cluster-admins")
+
+	restricted := &consoleserver.Impersonation{
+		State:         consoleserver.ImpersonationRestricted,
+		AllowedGroups: []string{"cluster-admins", "support"},
+	}
+	tests := []struct {
+		name          string
+		authType      configv1.AuthenticationType
+		impersonation *consoleserver.Impersonation
+		want          []string
+	}{
+		{
+			name: "Not configured",
+		},
+		{
+			name:          "Not restricted",
+			impersonation: &consoleserver.Impersonation{State: consoleserver.ImpersonationEnabled},
+		},
+		{
+			name:          "Missing group",
+			impersonation: restricted,
+			want:          []string{"support"},
+		},
+		{
+			name:          "Existing groups",
+			impersonation: &consoleserver.Impersonation{State: consoleserver.ImpersonationRestricted, AllowedGroups: []string{"cluster-admins"}},
+		},
+		{
+			name:          "Groups from OIDC claims",
+			authType:      configv1.AuthenticationTypeOIDC,
+			impersonation: restricted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ImpersonationController{
+				dynamicClient: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), group),
+			}
+			authnConfig := &configv1.Authentication{Spec: configv1.AuthenticationSpec{Type: tt.authType}}
+			missingGroups, err := c.getMissingGroups(context.TODO(), authnConfig, tt.impersonation)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(missingGroups) == 0 {
+				missingGroups = nil
+			}
+			if diff := deep.Equal(missingGroups, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
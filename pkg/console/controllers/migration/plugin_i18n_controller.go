@@ -0,0 +1,97 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	consoleclientv1 "github.com/openshift/client-go/console/clientset/versioned/typed/console/v1"
+	consoleinformersv1 "github.com/openshift/client-go/console/informers/externalversions/console/v1"
+	consolev1listers "github.com/openshift/client-go/console/listers/console/v1"
+	v1 "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleplugin"
+)
+
+// PluginI18nMigrationController moves the console.openshift.io/use-i18n annotation of the
+// plugins created through v1alpha1 to the v1 spec.i18n.loadType field. Since the removal
+// of the conversion webhook nothing else migrates them, and the console would leave
+// their localization resources unloaded.
+//
+// Plugins managed by a controller, e.g. OLM, are left to it, since it would re-apply the
+// annotation right away. GetI18nLoadType honors the annotation for them anyway.
+type PluginI18nMigrationController struct {
+	consolePluginClient  consoleclientv1.ConsolePluginsGetter
+	operatorConfigLister operatorv1listers.ConsoleLister
+	consolePluginLister  consolev1listers.ConsolePluginLister
+}
+
+func NewPluginI18nMigrationController(
+	// clients
+	consolePluginClient consoleclientv1.ConsolePluginsGetter,
+	// informers
+	operatorConfigInformer v1.ConsoleInformer,
+	consolePluginInformer consoleinformersv1.ConsolePluginInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	c := &PluginI18nMigrationController{
+		consolePluginClient:  consolePluginClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		consolePluginLister:  consolePluginInformer.Lister(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithInformers(
+		consolePluginInformer.Informer(),
+	).ResyncEvery(10*time.Minute).WithSync(c.Sync).
+		ToController("PluginI18nMigrationController", recorder.WithComponentSuffix("plugin-i18n-migration-controller"))
+}
+
+func (c *PluginI18nMigrationController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+	if operatorConfig.Spec.ManagementState != operatorsv1.Managed {
+		klog.V(4).Infof("console is in %q state: skipping plugin i18n migration", operatorConfig.Spec.ManagementState)
+		return nil
+	}
+
+	plugins, err := c.consolePluginLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	errs := []error{}
+	for _, plugin := range plugins {
+		migrated := consoleplugin.MigrateI18nAnnotation(plugin)
+		if migrated == nil {
+			continue
+		}
+		if owner := metav1.GetControllerOf(plugin); owner != nil {
+			klog.V(4).Infof("skipping i18n annotation migration of %q plugin: managed by %s %q", plugin.Name, owner.Kind, owner.Name)
+			continue
+		}
+		if _, err := c.consolePluginClient.ConsolePlugins().Update(ctx, migrated, metav1.UpdateOptions{}); err != nil {
+			errs = append(errs, fmt.Errorf("failed to migrate i18n annotation of %q plugin: %w", plugin.Name, err))
+			continue
+		}
+		klog.V(2).Infof("migrated %s annotation of %q plugin to %q i18n load type", api.V1Alpha1PluginI18nAnnotation, plugin.Name, migrated.Spec.I18n.LoadType)
+		controllerContext.Recorder().Eventf("PluginI18nMigrated", "Migrated %s annotation of %q plugin to %q i18n load type", api.V1Alpha1PluginI18nAnnotation, plugin.Name, migrated.Spec.I18n.LoadType)
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -4,9 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"slices"
+	"strings"
 	"time"
 
-	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -43,13 +44,19 @@ import (
 //
 //	updates:
 //	- oauthclient.oauth.openshift.io/console (creates if doesn't exist)
+//		- redirectURIs, secret, the console-hosts annotation
+//		- grantMethod, accessTokenMaxAgeSeconds, accessTokenInactivityTimeoutSeconds, when annotated
 //	writes:
 //	- consoles.operator.openshift.io/cluster .status.conditions:
 //		- type=OAuthClientSyncProgressing
 //		- type=OAuthClientSyncDegraded
+//		- type=OAuthClientTokenConfigDegraded
+//		- type=OAuthClientRedirectURIsPolicyDegraded
+//		- type=OAuthClientUnservedRedirectURIs, True with the current unserved redirect URIs while the Report policy keeps them
 type oauthClientsController struct {
-	oauthClient    oauthv1client.OAuthClientsGetter
-	operatorClient v1helpers.OperatorClient
+	oauthClient     oauthv1client.OAuthClientsGetter
+	operatorClient  v1helpers.OperatorClient
+	namespaceLister corev1listers.NamespaceLister
 
 	oauthClientLister           oauthv1lister.OAuthClientLister
 	oauthClientSwitchedInformer *util.InformerWithSwitch
@@ -58,6 +65,10 @@ type oauthClientsController struct {
 	routesLister                routev1listers.RouteLister
 	ingressConfigLister         configv1lister.IngressLister
 	targetNSSecretsLister       corev1listers.SecretLister
+	ingressControllerLister     operatorv1listers.IngressControllerLister
+
+	// reportedUnservedRedirectURIs are the unserved redirect URIs last listed in an event
+	reportedUnservedRedirectURIs []string
 }
 
 func NewOAuthClientsController(
@@ -68,12 +79,15 @@ func NewOAuthClientsController(
 	routeInformer routev1informers.RouteInformer,
 	ingressConfigInformer configv1informers.IngressInformer,
 	targetNSsecretsInformer corev1informers.SecretInformer,
+	ingressControllerInformer operatorv1informers.IngressControllerInformer,
+	namespaceInformer corev1informers.NamespaceInformer,
 	oauthClientSwitchedInformer *util.InformerWithSwitch,
 	recorder events.Recorder,
 ) factory.Controller {
 	c := oauthClientsController{
-		oauthClient:    oauthClient.OauthV1(),
-		operatorClient: operatorClient,
+		oauthClient:     oauthClient.OauthV1(),
+		operatorClient:  operatorClient,
+		namespaceLister: namespaceInformer.Lister(),
 
 		oauthClientLister:           oauthClientSwitchedInformer.Lister(),
 		oauthClientSwitchedInformer: oauthClientSwitchedInformer,
@@ -82,6 +96,7 @@ func NewOAuthClientsController(
 		routesLister:                routeInformer.Lister(),
 		ingressConfigLister:         ingressConfigInformer.Lister(),
 		targetNSSecretsLister:       targetNSsecretsInformer.Lister(),
+		ingressControllerLister:     ingressControllerInformer.Lister(),
 	}
 
 	return factory.New().
@@ -92,6 +107,8 @@ func NewOAuthClientsController(
 			routeInformer.Informer(),
 			ingressConfigInformer.Informer(),
 			targetNSsecretsInformer.Informer(),
+			ingressControllerInformer.Informer(),
+			namespaceInformer.Informer(),
 		).
 		WithFilteredEventsInformers(
 			factory.NamesFilter(api.OAuthClientName),
@@ -136,10 +153,20 @@ func (c *oauthClientsController) sync(ctx context.Context, controllerContext fac
 	}
 
 	var consoleURL *url.URL
-
+	// the IngressController is only needed to tell the route hosts
+	var ingressController *operatorv1.IngressController
 	if len(operatorConfig.Spec.Ingress.ConsoleURL) == 0 {
+		ingressController, err = routesub.GetServedIngressController(c.ingressControllerLister, c.namespaceLister, operatorConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(operatorConfig.Spec.Ingress.ConsoleURL) == 0 && routesub.GetExposureMode(operatorConfig) != api.ExposureModeRoute {
+		consoleURL = routesub.NewShardedRouteConfig(operatorConfig, ingressConfig, ingressController, api.OpenShiftConsoleRouteName).GetExposedURL()
+	} else if len(operatorConfig.Spec.Ingress.ConsoleURL) == 0 {
 		routeName := api.OpenShiftConsoleRouteName
-		routeConfig := routesub.NewRouteConfig(operatorConfig, ingressConfig, routeName)
+		routeConfig := routesub.NewShardedRouteConfig(operatorConfig, ingressConfig, ingressController, routeName)
 		if routeConfig.IsCustomHostnameSet() {
 			routeName = api.OpenshiftConsoleCustomRouteName
 		}
@@ -167,14 +194,36 @@ func (c *oauthClientsController) sync(ctx context.Context, controllerContext fac
 	if err != nil {
 		return err
 	}
+	// while switching from OIDC, the client secret of the OAuthClient is staged next to the served one
+	clientSecretString := secretsub.GetSecretStringForAuthType(clientSecret, authnConfig.Spec.Type)
+	if len(clientSecretString) == 0 {
+		return statusHandler.FlushAndReturn(fmt.Errorf("the client secret of the %s OAuthClient is not synced yet", api.OAuthClientName))
+	}
+
+	// invalid values are reported, the client keeps its current values for them
+	tokenConfig, tokenConfigErr := oauthsub.GetTokenConfig(operatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("OAuthClientTokenConfig", "InvalidTokenConfig", tokenConfigErr))
+
+	redirectURIsPolicy, redirectURIsPolicyErr := oauthsub.GetRedirectURIsPolicy(operatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("OAuthClientRedirectURIsPolicy", "InvalidPolicy", redirectURIsPolicyErr))
 
 	additionalHosts := routesub.GetAdditionalRouteHostnames(ingressConfig)
-	oauthErrReason, err := c.syncOAuthClient(ctx, clientSecret, consoleURL.String(), additionalHosts...)
+	audit, oauthErrReason, err := c.syncOAuthClient(ctx, clientSecretString, tokenConfig, redirectURIsPolicy, controllerContext.Recorder(), consoleURL.String(), additionalHosts...)
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSync", oauthErrReason, err))
 	if err != nil {
 		return statusHandler.FlushAndReturn(err)
 	}
 
+	// pruned URIs are only listed in events, kept ones are reported until they are removed
+	var unservedRedirectURIsErr error
+	if redirectURIsPolicy == oauthsub.RedirectURIsReport && len(audit.Unserved()) > 0 {
+		unservedRedirectURIsErr = fmt.Errorf("the %s OAuthClient holds redirect URIs the console doesn't serve, stale: [%s], foreign: [%s]", api.OAuthClientName, strings.Join(audit.Stale, ", "), strings.Join(audit.Foreign, ", "))
+		c.reportUnservedRedirectURIs(audit, controllerContext.Recorder())
+	} else {
+		c.reportedUnservedRedirectURIs = nil
+	}
+	statusHandler.AddCondition(status.HandleWarning("OAuthClientUnservedRedirectURIs", "UnservedRedirectURIs", unservedRedirectURIsErr))
+
 	return statusHandler.FlushAndReturn(nil)
 }
 
@@ -206,14 +255,17 @@ func (c *oauthClientsController) handleManaged(ctx context.Context) (bool, error
 // should not be called until route & secret dependencies are verified
 func (c *oauthClientsController) syncOAuthClient(
 	ctx context.Context,
-	sec *corev1.Secret,
+	clientSecret string,
+	tokenConfig oauthsub.TokenConfig,
+	redirectURIsPolicy string,
+	recorder events.Recorder,
 	consoleURL string,
 	additionalHosts ...string,
-) (reason string, err error) {
+) (audit oauthsub.RedirectURIAudit, reason string, err error) {
 	oauthClient, err := c.oauthClientLister.Get(oauthsub.Stub().Name)
 	if err != nil && !errors.IsNotFound(err) {
 		// at this point we must die & wait for someone to fix the lack of an outhclient. there is nothing we can do.
-		return "FailedGet", fmt.Errorf("getting console oauth client (%w)", err)
+		return audit, "FailedGet", fmt.Errorf("getting console oauth client (%w)", err)
 	}
 
 	if errors.IsNotFound(err) {
@@ -227,15 +279,40 @@ func (c *oauthClientsController) syncOAuthClient(
 	}
 
 	clientCopy := oauthClient.DeepCopy()
-	oauthsub.RegisterConsoleToOAuthClient(clientCopy, consoleURL, secretsub.GetSecretString(sec), additionalHosts...)
+	oauthsub.RegisterConsoleToOAuthClient(clientCopy, consoleURL, clientSecret, additionalHosts...)
+	oauthsub.SetTokenConfig(clientCopy, tokenConfig)
+	// redirect URIs of decommissioned hosts, or added by other actors, would keep redirecting
+	// authorization codes to wherever they point to
+	audit = oauthsub.AuditRedirectURIs(oauthClient.RedirectURIs, clientCopy.RedirectURIs, oauthsub.GetConsoleHosts(oauthClient), redirectURIsPolicy)
+	consoleRedirectURIs := slices.Clone(clientCopy.RedirectURIs)
+	if redirectURIsPolicy == oauthsub.RedirectURIsReport {
+		// the kept callbacks of previous console hosts are still recognized as stale
+		consoleRedirectURIs = append(consoleRedirectURIs, audit.Stale...)
+	}
+	oauthsub.SetConsoleHosts(clientCopy, consoleRedirectURIs)
+	clientCopy.RedirectURIs = audit.Current
 	oauthErr := util.RetryOnTransientError(func() error {
 		_, _, e := oauthsub.CustomApplyOAuth(c.oauthClient, clientCopy, ctx)
 		return e
 	})
 	if oauthErr != nil {
-		return "FailedRegister", oauthErr
+		return audit, "FailedRegister", oauthErr
+	}
+	if unserved := audit.Unserved(); redirectURIsPolicy == oauthsub.RedirectURIsPrune && len(unserved) > 0 {
+		klog.V(2).Infof("pruned redirect URIs not served by the console from the %s OAuthClient: %s", clientCopy.Name, strings.Join(unserved, ", "))
+		recorder.Eventf("OAuthClientRedirectURIsPruned", "Removed redirect URIs not served by the console from the %s OAuthClient: %s", clientCopy.Name, strings.Join(unserved, ", "))
+	}
+	return audit, "", nil
+}
+
+// reportUnservedRedirectURIs lists the unserved redirect URIs in an event whenever they change.
+func (c *oauthClientsController) reportUnservedRedirectURIs(audit oauthsub.RedirectURIAudit, recorder events.Recorder) {
+	unserved := audit.Unserved()
+	if slices.Equal(unserved, c.reportedUnservedRedirectURIs) {
+		return
 	}
-	return "", nil
+	c.reportedUnservedRedirectURIs = unserved
+	recorder.Warningf("OAuthClientRedirectURIsUnserved", "The %s OAuthClient holds redirect URIs the console doesn't serve, stale: [%s], foreign: [%s]", api.OAuthClientName, strings.Join(audit.Stale, ", "), strings.Join(audit.Foreign, ", "))
 }
 
 func (c *oauthClientsController) deregisterClient(ctx context.Context) error {
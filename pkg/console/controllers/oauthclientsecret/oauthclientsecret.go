@@ -3,10 +3,14 @@ package oauthclientsecret
 import (
 	"context"
 	"fmt"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	appsv1informers "k8s.io/client-go/informers/apps/v1"
 	corev1informers "k8s.io/client-go/informers/core/v1"
 	corev1clients "k8s.io/client-go/kubernetes/typed/core/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
@@ -26,25 +30,35 @@ import (
 
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
 	authnsub "github.com/openshift/console-operator/pkg/console/subresource/authentication"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
 	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
 )
 
 // oauthClientSecretController behaves differently based on authentication/cluster .spec.type:
 //
 //   - IntegratedOAuth - self-manage the client secret string
-//   - OIDC - lookup our client in the authentication/cluster .spec.oidcProviders[x].oidcClients
+//   - OIDC - lookup our client in the authentication/cluster .spec.oidcProviders[0].oidcClients
 //     slice and use the 'clientSecret' from the secret referred to by .clientSecret.name
 //   - None - do nothing
 //
-// The secret written is 'openshift-console/console-oauth-config' in .Data['clientSecret']
+// The secret written is 'openshift-console/console-oauth-config' in .Data['clientSecret']. When
+// the OIDC client secret is rotated, the console keeps logging in with the secret it uses until the
+// token endpoint of the issuer accepts the new one, so that the console is not rolled out with a
+// secret the IdP doesn't know yet. The replaced secret is kept in .Data['previousClientSecret']
+// until the console is rolled out with the rotated one, an optional 'previousClientSecret' key of
+// the referenced secret is copied as well. When the authentication type is switched, the client
+// secret of the new type is staged in .Data['pendingClientSecret'] and the console operator
+// switches it over together with the console config.
 //
 // ==========
 //
 //	writes:
-//	- secrets.console-oauth-config -n openshift-console .Data['clientSecret']
+//	- secrets.console-oauth-config -n openshift-console .Data['clientSecret'], .Data['pendingClientSecret'], .Data['previousClientSecret']
 //	- consoles.operator.openshift.io/cluster .status.conditions:
 //		- type=OAuthClientSecretSyncProgressing
 //		- type=OAuthClientSecretSyncDegraded
+//		- type=OAuthClientSecretRolloutProgressing
+//		- type=OAuthClientSecretRotationProgressing
 type oauthClientSecretController struct {
 	operatorClient v1helpers.OperatorClient
 	secretsClient  corev1clients.SecretsGetter
@@ -52,7 +66,9 @@ type oauthClientSecretController struct {
 	authConfigLister      configv1listers.AuthenticationLister
 	consoleOperatorLister operatorv1listers.ConsoleLister
 	configSecretsLister   corev1listers.SecretLister
+	configConfigMapLister corev1listers.ConfigMapLister
 	targetNSSecretsLister corev1listers.SecretLister
+	deploymentsLister     appsv1listers.DeploymentLister
 }
 
 func NewOAuthClientSecretController(
@@ -61,7 +77,9 @@ func NewOAuthClientSecretController(
 	authnInformer configv1informers.AuthenticationInformer,
 	consoleOperatorInformer operatorv1informers.ConsoleInformer,
 	configSecretsInformer corev1informers.SecretInformer,
+	configConfigMapInformer corev1informers.ConfigMapInformer,
 	targetNSsecretsInformer corev1informers.SecretInformer,
+	deploymentsInformer appsv1informers.DeploymentInformer,
 	recorder events.Recorder,
 ) factory.Controller {
 	c := &oauthClientSecretController{
@@ -71,10 +89,14 @@ func NewOAuthClientSecretController(
 		authConfigLister:      authnInformer.Lister(),
 		consoleOperatorLister: consoleOperatorInformer.Lister(),
 		configSecretsLister:   configSecretsInformer.Lister(),
+		configConfigMapLister: configConfigMapInformer.Lister(),
 		targetNSSecretsLister: targetNSsecretsInformer.Lister(),
+		deploymentsLister:     deploymentsInformer.Lister(),
 	}
 
 	return factory.New().
+		// a pending rotation is retried until the issuer accepts the new client secret
+		ResyncEvery(time.Minute).
 		WithSync(c.sync).
 		WithInformers(
 			authnInformer.Informer(),
@@ -84,6 +106,9 @@ func NewOAuthClientSecretController(
 		WithFilteredEventsInformers(
 			factory.NamesFilter("console-oauth-config"), targetNSsecretsInformer.Informer(),
 		).
+		WithFilteredEventsInformers(
+			factory.NamesFilter(api.OpenShiftConsoleDeploymentName), deploymentsInformer.Informer(),
+		).
 		ToController("OAuthClientSecretController", recorder.WithComponentSuffix("oauthclient-secret-controller"))
 }
 
@@ -106,19 +131,23 @@ func (c *oauthClientSecretController) sync(ctx context.Context, syncCtx factory.
 		return fmt.Errorf("failed to retrieve authentication config: %w", err)
 	}
 
-	var secretString string
+	secretStrings := map[string]string{}
+	var rotationReason string
+	var rotationErr error
 	switch authConfig.Spec.Type {
 	// We don't disable auth since the internal OAuth server is not disabled even with auth type 'None'.
 	case "", configv1.AuthenticationTypeIntegratedOAuth, configv1.AuthenticationTypeNone:
 		// in OpenShift controlled world, we generate the client secret ourselves
+		var secretString string
 		if clientSecret != nil {
-			secretString = secretsub.GetSecretString(clientSecret)
+			secretString = secretsub.GetSecretStringForAuthType(clientSecret, authConfig.Spec.Type)
 		}
 		if len(secretString) == 0 {
 			secretString = crypto.Random256BitsString()
 		}
+		secretStrings[secretsub.ClientSecretKey] = secretString
 	case configv1.AuthenticationTypeOIDC:
-		_, clientConfig := authnsub.GetOIDCClientConfig(authConfig, api.TargetNamespace, api.OpenShiftConsoleName)
+		oidcProvider, clientConfig := authnsub.GetOIDCClientConfig(authConfig, api.TargetNamespace, api.OpenShiftConsoleName)
 		if clientConfig == nil {
 			// no config, flush the condition and return
 			statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "", nil))
@@ -136,11 +165,35 @@ func (c *oauthClientSecretController) sync(ctx context.Context, syncCtx factory.
 			return statusHandler.FlushAndReturn(err)
 		}
 
-		secretString = secretsub.GetSecretString(conficClientSecret)
+		secretString := secretsub.GetSecretString(conficClientSecret)
 		if len(secretString) == 0 {
 			statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "ClientSecretKeyMissing", fmt.Errorf("missing the 'clientSecret' key in the client secret secret %q", clientConfig.ClientSecret.Name)))
 			return statusHandler.FlushAndReturn(nil)
 		}
+		secretStrings[secretsub.ClientSecretKey] = secretString
+
+		// the console keeps the secret it logs in with until the IdP accepts the rotated one, the
+		// replaced secret is then kept as the previous one until the console is rolled out with the
+		// rotated one, so that the logins started by the replaced pods can complete
+		if clientSecret != nil && secretsub.GetAuthType(clientSecret) == configv1.AuthenticationTypeOIDC {
+			if servedSecretString := secretsub.GetSecretString(clientSecret); len(servedSecretString) > 0 && servedSecretString != secretString {
+				rotationReason, rotationErr = c.checkRotatedClientSecret(oidcProvider, clientConfig, secretString)
+				if rotationErr != nil {
+					secretStrings[secretsub.ClientSecretKey] = servedSecretString
+				} else {
+					secretStrings[api.PreviousClientSecretKey] = servedSecretString
+				}
+			} else if previousSecretString := secretsub.GetPreviousSecretString(clientSecret); len(previousSecretString) > 0 {
+				if rolloutReason, _ := c.checkRollout(secretStrings); len(rolloutReason) > 0 {
+					secretStrings[api.PreviousClientSecretKey] = previousSecretString
+				}
+			}
+		}
+
+		// admins rotating the secret at the IdP can keep the previous one for as long as the IdP accepts it
+		if previousSecretString := secretsub.GetPreviousSecretString(conficClientSecret); len(previousSecretString) > 0 && previousSecretString != secretStrings[secretsub.ClientSecretKey] {
+			secretStrings[api.PreviousClientSecretKey] = previousSecretString
+		}
 
 	default:
 		klog.V(2).Infof("unknown authentication type: %s", authConfig.Spec.Type)
@@ -148,25 +201,105 @@ func (c *oauthClientSecretController) sync(ctx context.Context, syncCtx factory.
 		return statusHandler.FlushAndReturn(nil)
 	}
 
-	err = c.syncSecret(ctx, secretString, syncCtx.Recorder())
+	staged, err := c.syncSecret(ctx, authConfig.Spec.Type, secretStrings, syncCtx.Recorder())
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "FailedApply", err))
-	return statusHandler.FlushAndReturn(err)
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+
+	statusHandler.AddCondition(status.HandleProgressing("OAuthClientSecretRotation", rotationReason, rotationErr))
+
+	// a staged client secret is rolled out once the console operator switches the auth type
+	if !staged {
+		rolloutReason, rolloutErr := c.checkRollout(secretStrings)
+		statusHandler.AddCondition(status.HandleProgressing("OAuthClientSecretRollout", rolloutReason, rolloutErr))
+	}
+	return statusHandler.FlushAndReturn(nil)
 }
 
-func (c *oauthClientSecretController) syncSecret(ctx context.Context, clientSecret string, recorder events.Recorder) error {
+// syncSecret writes the client secrets of the auth type. While the console serves another auth
+// type, the client secret is only staged next to the served one, so that restarting console pods
+// don't pick up the client secret of an auth type their config doesn't serve yet. It returns
+// whether the client secret is staged.
+func (c *oauthClientSecretController) syncSecret(ctx context.Context, authType configv1.AuthenticationType, secretStrings map[string]string, recorder events.Recorder) (bool, error) {
 	operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	secret, err := c.targetNSSecretsLister.Secrets(api.TargetNamespace).Get("console-oauth-config")
-	if apierrors.IsNotFound(err) || secretsub.GetSecretString(secret) != clientSecret {
-		err = util.RetryOnTransientError(func() error {
-			_, _, e := resourceapply.ApplySecret(ctx, c.secretsClient, recorder, secretsub.DefaultSecret(operatorConfig, clientSecret))
-			return e
-		})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+
+	staged := secret != nil && len(secretsub.GetAuthType(secret)) > 0 && !secretsub.SameAuthType(secretsub.GetAuthType(secret), authType)
+	var required *corev1.Secret
+	if staged {
+		if pendingAuthType, ok := secretsub.GetPendingAuthType(secret); ok && pendingAuthType == authType &&
+			secretsub.GetSecretStringForAuthType(secret, authType) == secretStrings[secretsub.ClientSecretKey] {
+			return staged, nil
+		}
+		required = secretsub.SetSecretStrings(secretsub.DefaultSecret(operatorConfig, ""), map[string]string{secretsub.ClientSecretKey: secretsub.GetSecretString(secret)})
+		secretsub.SetAuthType(required, secretsub.GetAuthType(secret))
+		secretsub.SetPendingSecretString(required, authType, secretStrings[secretsub.ClientSecretKey])
+	} else {
+		// the auth type tells the console operator whether the secrets of a new auth type are synced yet
+		if secret != nil && secretsub.HasSecretStrings(secret, secretStrings) && secretsub.GetAuthType(secret) == authType {
+			if _, pending := secretsub.GetPendingAuthType(secret); !pending {
+				return staged, nil
+			}
+		}
+		required = secretsub.SetSecretStrings(secretsub.DefaultSecret(operatorConfig, ""), secretStrings)
+		secretsub.SetAuthType(required, authType)
+		secretsub.ClearPendingAuthType(required)
+		if secret != nil && deploymentsub.GetClientSecretVersion(secret) != deploymentsub.GetClientSecretVersion(required) {
+			recorder.Eventf("OAuthClientSecretRotated", "The client secrets in %s/%s changed, the console is rolled out with the new client secrets", api.TargetNamespace, required.Name)
+		}
+	}
+	err = util.RetryOnTransientError(func() error {
+		_, _, e := resourceapply.ApplySecret(ctx, c.secretsClient, recorder, required)
+		return e
+	})
+	return staged, err
+}
+
+// checkRotatedClientSecret verifies the token endpoint of the issuer accepts the rotated client
+// secret, an error describes why the console keeps its current client secret.
+func (c *oauthClientSecretController) checkRotatedClientSecret(oidcProvider *configv1.OIDCProvider, clientConfig *configv1.OIDCClientConfig, clientSecret string) (string, error) {
+	httpClient, _, reason, err := authnsub.NewProviderIssuerClient(c.configConfigMapLister, oidcProvider)
+	if err != nil {
+		return reason, fmt.Errorf("the console keeps its current client secret, %w", err)
+	}
+	discovery, reason, err := authnsub.FetchDiscovery(httpClient, oidcProvider.Issuer.URL)
+	if err != nil {
+		return reason, fmt.Errorf("the console keeps its current client secret, OIDC provider %q: %w", oidcProvider.Name, err)
+	}
+	if reason, err := authnsub.CheckClientCredentials(httpClient, discovery, clientConfig.ClientID, clientSecret); err != nil {
+		return reason, fmt.Errorf("the console keeps its current client secret until the OIDC provider %q accepts the rotated one: %w", oidcProvider.Name, err)
+	}
+	return "", nil
+}
+
+// checkRollout reports whether the console deployment has picked up the current client secrets,
+// an error describes the pending rollout.
+func (c *oauthClientSecretController) checkRollout(secretStrings map[string]string) (string, error) {
+	depl, err := c.deploymentsLister.Deployments(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleDeploymentName)
+	if apierrors.IsNotFound(err) {
+		// the console is not deployed yet, the first rollout is reported by the deployment sync
+		return "", nil
+	}
+	if err != nil {
+		return "FailedDeploymentGet", err
+	}
+
+	clientSecretVersion := deploymentsub.GetClientSecretVersion(secretsub.SetSecretStrings(&corev1.Secret{}, secretStrings))
+	if clientSecretVersion != depl.ObjectMeta.Annotations[deploymentsub.SecretResourceVersionAnnotation] {
+		return "PendingDeploymentUpdate", fmt.Errorf("the console deployment doesn't use the current client secrets yet")
+	}
+	if !deploymentsub.IsAvailableAndUpdated(depl) {
+		return "RolloutInProgress", fmt.Errorf("the console deployment is rolling out the current client secrets")
 	}
-	return err
+	return "", nil
 }
 
 // handleStatus returns whether sync should happen and any error encountering
@@ -32,12 +32,21 @@ import (
 
 	authnsub "github.com/openshift/console-operator/pkg/console/subresource/authentication"
 	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
 	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
 )
 
 // oidcSetupController:
 //
+// Besides syncing the provider's CA, the issuer is validated by fetching its discovery
+// document and JWKS, sending a token request with the client ID and secret of the console's
+// client to check the issuer accepts them, and matching the extra scopes of the client against
+// the scopes_supported of the issuer. The token request is only sent again once the issuer,
+// its CA, the client ID or the secret change, rejected credentials are retried hourly.
+// The discovery of the valid issuer is kept in a configmap for the console operator.
+//
 //	writes:
+//	- configmaps.console-oidc-discovery -n openshift-console-operator
 //	- authentication.config.openshift.io/cluster .status.oidcClients:
 //		- componentName=console
 //		- componentNamespace=openshift-console
@@ -65,8 +74,18 @@ type oidcSetupController struct {
 	externalOIDCFeatureEnabled bool
 
 	authStatusHandler *status.AuthStatusHandler
+
+	// the result of the last client credentials probe
+	probedCredentialsHash   string
+	probedCredentialsAt     time.Time
+	probedCredentialsReason string
+	probedCredentialsErr    error
 }
 
+// rejectedCredentialsRecheckInterval is how often rejected client credentials are probed again,
+// as the client may be fixed at the issuer without any change on the cluster.
+const rejectedCredentialsRecheckInterval = time.Hour
+
 func NewOIDCSetupController(
 	operatorClient v1helpers.OperatorClient,
 	configMapClient corev1client.ConfigMapsGetter,
@@ -224,24 +243,75 @@ func (c *oidcSetupController) syncAuthTypeOIDC(ctx context.Context, authnConfig
 		}
 	}
 
-	if valid, msg, err := c.checkClientConfigStatus(authnConfig, clientSecret); err != nil {
+	// misconfigured issuers would otherwise only show up as login loops in the console, the
+	// client is reported degraded instead of available while the issuer is broken
+	discovery, reason, err := c.validateIssuer(oidcProvider, clientConfig, secretsub.GetSecretStringForAuthType(clientSecret, configv1.AuthenticationTypeOIDC))
+	if err != nil {
+		c.authStatusHandler.Degraded("OIDCIssuer"+reason, err.Error())
+		// the discovery of the last valid issuer is kept
+		return nil
+	}
+
+	// the console operator derives the logout redirect from the discovery
+	_, _, err = resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, authnsub.DefaultDiscoveryConfigMap(discovery))
+	if err != nil {
+		return fmt.Errorf("failed to apply the OIDC discovery configMap: %w", err)
+	}
+
+	valid, msg, err := c.checkClientConfigStatus(oidcProvider, clientSecret)
+	if err != nil {
 		c.authStatusHandler.Degraded("DeploymentOIDCConfig", err.Error())
 		return err
+	}
 
-	} else if !valid {
+	if valid {
+		c.authStatusHandler.Available("OIDCConfigAvailable", "")
+	} else {
 		c.authStatusHandler.Progressing("DeploymentOIDCConfig", msg)
-		return nil
 	}
-
-	c.authStatusHandler.Available("OIDCConfigAvailable", "")
 	return nil
 }
 
+// validateIssuer fetches the discovery document and the JWKS of the provider with the CA the
+// provider is configured with, and checks the client's extra scopes are supported and the token
+// endpoint accepts the client secret.
+func (c *oidcSetupController) validateIssuer(oidcProvider *configv1.OIDCProvider, clientConfig *configv1.OIDCClientConfig, clientSecret string) (*authnsub.ProviderDiscovery, string, error) {
+	httpClient, caBundle, reason, err := authnsub.NewProviderIssuerClient(c.configConfigMapLister, oidcProvider)
+	if err != nil {
+		return nil, reason, err
+	}
+
+	discovery, reason, err := authnsub.ValidateIssuer(httpClient, oidcProvider, clientConfig)
+	if err != nil {
+		return nil, reason, fmt.Errorf("OIDC provider %q: %w", oidcProvider.Name, err)
+	}
+
+	// every probe is a failed token request at the issuer, which may count it against the client
+	credentialsHash := authnsub.GetClientCredentialsHash(oidcProvider.Issuer.URL, caBundle, clientConfig.ClientID, clientSecret)
+	recheck := c.probedCredentialsErr != nil && time.Since(c.probedCredentialsAt) >= rejectedCredentialsRecheckInterval
+	if credentialsHash != c.probedCredentialsHash || recheck {
+		reason, err := authnsub.CheckClientCredentials(httpClient, discovery, clientConfig.ClientID, clientSecret)
+		switch reason {
+		case "", "InvalidClientCredentials", "UnauthorizedClient":
+			// only the verdicts of the issuer on the client are kept
+			c.probedCredentialsHash, c.probedCredentialsAt = credentialsHash, time.Now()
+			c.probedCredentialsReason, c.probedCredentialsErr = reason, err
+		default:
+			c.probedCredentialsHash = ""
+			return nil, reason, fmt.Errorf("OIDC provider %q: %w", oidcProvider.Name, err)
+		}
+	}
+	if c.probedCredentialsErr != nil {
+		return nil, c.probedCredentialsReason, fmt.Errorf("OIDC provider %q: %w", oidcProvider.Name, c.probedCredentialsErr)
+	}
+	return discovery, "", nil
+}
+
 // checkClientConfigStatus checks whether the current client configuration is being currently in use,
 // by looking at the deployment status. It checks whether the deployment is available and updated,
-// and also whether the resource versions for the oauth secret and server CA trust configmap match
+// and also whether the versions of the client secret and the provider's CA trust configmap match
 // the deployment.
-func (c *oidcSetupController) checkClientConfigStatus(authnConfig *configv1.Authentication, clientSecret *corev1.Secret) (bool, string, error) {
+func (c *oidcSetupController) checkClientConfigStatus(oidcProvider *configv1.OIDCProvider, clientSecret *corev1.Secret) (bool, string, error) {
 	depl, err := c.targetNSDeploymentsLister.Deployments(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleDeploymentName)
 	if err != nil {
 		return false, "", err
@@ -252,24 +322,22 @@ func (c *oidcSetupController) checkClientConfigStatus(authnConfig *configv1.Auth
 		return false, "deployment unavailable or outdated", nil
 	}
 
-	if clientSecret.GetResourceVersion() != depl.ObjectMeta.Annotations["console.openshift.io/oauth-secret-version"] {
+	if deploymentsub.GetClientSecretVersion(clientSecret) != depl.ObjectMeta.Annotations[deploymentsub.SecretResourceVersionAnnotation] {
 		return false, "client secret version not up to date in current deployment", nil
 	}
 
-	if len(authnConfig.Spec.OIDCProviders) > 0 {
-		serverCAConfigName := authnConfig.Spec.OIDCProviders[0].Issuer.CertificateAuthority.Name
-		if len(serverCAConfigName) == 0 {
-			return deplAvailableUpdated, "", nil
-		}
+	serverCAConfigName := oidcProvider.Issuer.CertificateAuthority.Name
+	if len(serverCAConfigName) == 0 {
+		return deplAvailableUpdated, "", nil
+	}
 
-		serverCAConfig, err := c.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(serverCAConfigName)
-		if err != nil {
-			return false, "", err
-		}
+	serverCAConfig, err := c.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(serverCAConfigName)
+	if err != nil {
+		return false, "", err
+	}
 
-		if serverCAConfig.GetResourceVersion() != depl.ObjectMeta.Annotations["console.openshift.io/authn-ca-trust-config-version"] {
-			return false, "OIDC provider CA version not up to date in current deployment", nil
-		}
+	if serverCAConfig.GetResourceVersion() != depl.ObjectMeta.Annotations["console.openshift.io/authn-ca-trust-config-version"] {
+		return false, "OIDC provider CA version not up to date in current deployment", nil
 	}
 
 	return deplAvailableUpdated, "", nil
@@ -0,0 +1,196 @@
+package pluginnetworkpolicy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	// k8s
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	networkinginformersv1 "k8s.io/client-go/informers/networking/v1"
+	networkingclientv1 "k8s.io/client-go/kubernetes/typed/networking/v1"
+	networkinglistersv1 "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	consoleinformersv1 "github.com/openshift/client-go/console/informers/externalversions/console/v1"
+	consolev1listers "github.com/openshift/client-go/console/listers/console/v1"
+	v1 "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleplugin"
+)
+
+// PluginNetworkPolicyController reconciles the console-plugins-egress NetworkPolicy, which
+// allows the console pods to reach the backend and proxy services of the enabled plugins
+// on clusters running a default-deny egress model. The rules of a plugin are removed once
+// it is disabled, and the policy is deleted when no plugin needs one.
+//
+// The plugin services and their endpoints are read on resync. Services without a selector are
+// matched by the addresses of their endpoints, the services which can't be matched by a
+// policy at all are reported through the PluginNetworkPolicyTargetsUnmatched warning condition.
+type PluginNetworkPolicyController struct {
+	// clients
+	operatorClient       v1helpers.OperatorClient
+	networkPolicyClient  networkingclientv1.NetworkPoliciesGetter
+	networkPolicyLister  networkinglistersv1.NetworkPolicyLister
+	operatorConfigLister operatorv1listers.ConsoleLister
+	consolePluginLister  consolev1listers.ConsolePluginLister
+	pluginServices       *util.PluginServices
+	resourceCache        resourceapply.ResourceCache
+}
+
+func NewPluginNetworkPolicyController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	networkPolicyClient networkingclientv1.NetworkPoliciesGetter,
+	pluginServices *util.PluginServices,
+	// informers
+	operatorConfigInformer v1.ConsoleInformer,
+	consolePluginInformer consoleinformersv1.ConsolePluginInformer,
+	networkPolicyInformer networkinginformersv1.NetworkPolicyInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &PluginNetworkPolicyController{
+		operatorClient:       operatorClient,
+		networkPolicyClient:  networkPolicyClient,
+		networkPolicyLister:  networkPolicyInformer.Lister(),
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		consolePluginLister:  consolePluginInformer.Lister(),
+		pluginServices:       pluginServices,
+		resourceCache:        resourceapply.NewResourceCache(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithInformers(
+		consolePluginInformer.Informer(),
+	).WithFilteredEventsInformers( // plugins egress policy
+		util.IncludeNamesFilter(api.PluginEgressNetworkPolicyName),
+		networkPolicyInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("PluginNetworkPolicyController", recorder.WithComponentSuffix("plugin-network-policy-controller"))
+}
+
+func (c *PluginNetworkPolicyController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch operatorConfig.Spec.ManagementState {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console is in a managed state: syncing plugins egress network policy")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console is in an unmanaged state: skipping plugins egress network policy sync")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console is in a removed state: deleting plugins egress network policy")
+		return c.removeNetworkPolicy(ctx)
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	targets, unmatched, reason, err := c.getEgressTargets(ctx, operatorConfig)
+	if err == nil {
+		reason, err = c.syncNetworkPolicy(ctx, operatorConfig, targets, controllerContext.Recorder())
+	}
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("PluginNetworkPolicySync", reason, err))
+	statusHandler.AddCondition(status.HandleWarning("PluginNetworkPolicyTargetsUnmatched", "UnmatchedServices", utilerrors.NewAggregate(unmatched)))
+	return statusHandler.FlushAndReturn(err)
+}
+
+// getEgressTargets returns the targets of the backend and proxy services of the enabled
+// plugins, along with the services which can't be matched by a policy. Missing services
+// are skipped, the plugin status controller reports them.
+func (c *PluginNetworkPolicyController) getEgressTargets(ctx context.Context, operatorConfig *operatorsv1.Console) ([]consoleplugin.EgressTarget, []error, string, error) {
+	plugins, err := c.consolePluginLister.List(labels.Everything())
+	if err != nil {
+		return nil, nil, "FailedListPlugins", err
+	}
+	// an invalid auto-enable policy is reported by the operator
+	enabledPluginNames, _ := consoleplugin.GetEnabledPluginNames(operatorConfig, plugins)
+
+	targets := []consoleplugin.EgressTarget{}
+	unmatched := []error{}
+	for _, pluginName := range enabledPluginNames {
+		plugin, err := c.consolePluginLister.Get(pluginName)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, "FailedGetPlugin", err
+		}
+		for _, pluginService := range consoleplugin.GetPluginServices(plugin) {
+			service, err := c.pluginServices.GetService(ctx, pluginService.Namespace, pluginService.Name)
+			if apierrors.IsNotFound(err) {
+				klog.V(4).Infof("skipping %s/%s plugin service egress rule: service not found", pluginService.Namespace, pluginService.Name)
+				continue
+			}
+			if err != nil {
+				return nil, nil, "FailedGetService", err
+			}
+			endpointSlices, err := c.pluginServices.ListEndpointSlices(ctx, service.Namespace, service.Name)
+			if err != nil {
+				return nil, nil, "FailedListEndpoints", err
+			}
+			target, err := consoleplugin.GetEgressTarget(service, endpointSlices, pluginService.Port)
+			if err != nil {
+				unmatched = append(unmatched, fmt.Errorf("no egress rule for %q plugin: %w", plugin.Name, err))
+				continue
+			}
+			targets = append(targets, *target)
+		}
+	}
+	return targets, unmatched, "", nil
+}
+
+func (c *PluginNetworkPolicyController) syncNetworkPolicy(ctx context.Context, operatorConfig *operatorsv1.Console, targets []consoleplugin.EgressTarget, recorder events.Recorder) (string, error) {
+	if len(targets) == 0 {
+		if err := c.removeNetworkPolicy(ctx); err != nil {
+			return "FailedDelete", err
+		}
+		return "", nil
+	}
+	requiredNetworkPolicy := consoleplugin.DefaultEgressNetworkPolicy(operatorConfig, targets)
+	err := util.RetryOnTransientError(func() error {
+		_, _, err := resourceapply.ApplyNetworkPolicy(ctx, c.networkPolicyClient, recorder, requiredNetworkPolicy, c.resourceCache)
+		return err
+	})
+	if err != nil {
+		return "FailedApply", err
+	}
+	return "", nil
+}
+
+func (c *PluginNetworkPolicyController) removeNetworkPolicy(ctx context.Context) error {
+	_, err := c.networkPolicyLister.NetworkPolicies(api.OpenShiftConsoleNamespace).Get(api.PluginEgressNetworkPolicyName)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = c.networkPolicyClient.NetworkPolicies(api.OpenShiftConsoleNamespace).Delete(ctx, api.PluginEgressNetworkPolicyName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
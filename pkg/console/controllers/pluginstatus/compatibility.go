@@ -0,0 +1,70 @@
+package pluginstatus
+
+import (
+	"context"
+	"os"
+
+	// k8s
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	// openshift
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleplugin"
+)
+
+// syncCompatibility records whether the manifest of each plugin declares a console SDK dependency
+// satisfied by the running release, since an incompatible plugin can break the whole console and
+// not only itself. The operator holds back the newly enabled plugins until their verdict is
+// recorded. A plugin whose manifest can't be fetched keeps its last known verdict, so an
+// unavailable backend doesn't flap the console config.
+func (c *PluginStatusController) syncCompatibility(ctx context.Context, enabledPluginNames []string, statuses []*PluginStatus, recorder events.Recorder) (string, error) {
+	existing, err := c.operatorNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleOperatorNamespace).Get(api.PluginCompatibilityConfigMapName)
+	if apierrors.IsNotFound(err) {
+		existing = nil
+	} else if err != nil {
+		return "FailedGet", err
+	}
+	previous := consoleplugin.GetCompatibilityVerdicts(existing)
+	verdicts := checkCompatibility(enabledPluginNames, statuses, previous, os.Getenv("OPERATOR_IMAGE_VERSION"))
+	for pluginName, message := range verdicts {
+		if len(message) != 0 && len(previous[pluginName]) == 0 {
+			recorder.Warningf("PluginIncompatible", "%q plugin excluded from the console config: %s", pluginName, message)
+		}
+	}
+
+	if existing == nil && len(verdicts) == 0 {
+		return "", nil
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, consoleplugin.DefaultCompatibilityConfigMap(verdicts)); err != nil {
+		return "FailedApply", err
+	}
+	return "", nil
+}
+
+// checkCompatibility returns the verdicts of the enabled plugins, an empty message for the
+// compatible ones. The plugins without a fetched manifest keep their previous verdict, if any.
+func checkCompatibility(enabledPluginNames []string, statuses []*PluginStatus, previous map[string]string, releaseVersion string) map[string]string {
+	enabledPlugins := sets.New(enabledPluginNames...)
+	verdicts := map[string]string{}
+	for _, pluginStatus := range statuses {
+		if !enabledPlugins.Has(pluginStatus.Name) {
+			continue
+		}
+		if pluginStatus.manifest == nil {
+			if message, known := previous[pluginStatus.Name]; known {
+				verdicts[pluginStatus.Name] = message
+			}
+			continue
+		}
+		verdicts[pluginStatus.Name] = ""
+		if _, err := consoleplugin.CheckCompatibility(pluginStatus.manifest, releaseVersion); err != nil {
+			verdicts[pluginStatus.Name] = err.Error()
+		}
+	}
+	return verdicts
+}
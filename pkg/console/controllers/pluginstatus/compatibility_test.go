@@ -0,0 +1,74 @@
+package pluginstatus
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleplugin"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	withManifest := func(name, pluginAPIRange string) *PluginStatus {
+		return &PluginStatus{
+			Name: name,
+			manifest: &consoleplugin.Manifest{
+				Name:         name,
+				Dependencies: map[string]string{consoleplugin.PluginAPIDependency: pluginAPIRange},
+			},
+		}
+	}
+	withoutManifest := func(name string) *PluginStatus {
+		return &PluginStatus{Name: name}
+	}
+
+	tests := []struct {
+		name               string
+		enabledPluginNames []string
+		statuses           []*PluginStatus
+		previous           map[string]string
+		want               map[string]string
+	}{
+		{
+			name:               "Compatible plugin",
+			enabledPluginNames: []string{"plugin-a"},
+			statuses:           []*PluginStatus{withManifest("plugin-a", ">=4.12")},
+			want:               map[string]string{"plugin-a": ""},
+		},
+		{
+			name:               "Incompatible plugin",
+			enabledPluginNames: []string{"plugin-a"},
+			statuses:           []*PluginStatus{withManifest("plugin-a", "~4.15.0")},
+			want:               map[string]string{"plugin-a": `"plugin-a" plugin requires console ~4.15.0, running 4.16.0`},
+		},
+		{
+			name:               "Previously incompatible plugin became compatible",
+			enabledPluginNames: []string{"plugin-a"},
+			statuses:           []*PluginStatus{withManifest("plugin-a", "~4.16.0")},
+			previous:           map[string]string{"plugin-a": "requires console ~4.15.0"},
+			want:               map[string]string{"plugin-a": ""},
+		},
+		{
+			name:               "Plugin without a fetched manifest keeps its verdict",
+			enabledPluginNames: []string{"plugin-a", "plugin-b", "plugin-c"},
+			statuses:           []*PluginStatus{withoutManifest("plugin-a"), withoutManifest("plugin-b"), withoutManifest("plugin-c")},
+			previous:           map[string]string{"plugin-a": "requires console ~4.15.0", "plugin-b": ""},
+			want:               map[string]string{"plugin-a": "requires console ~4.15.0", "plugin-b": ""},
+		},
+		{
+			name:               "Verdict of a disabled plugin is dropped",
+			enabledPluginNames: []string{},
+			statuses:           []*PluginStatus{withoutManifest("plugin-a")},
+			previous:           map[string]string{"plugin-a": "requires console ~4.15.0"},
+			want:               map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkCompatibility(tt.enabledPluginNames, tt.statuses, tt.previous, "4.16.0")
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+package pluginstatus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	// k8s
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	consoleinformersv1 "github.com/openshift/client-go/console/informers/externalversions/console/v1"
+	consolev1listers "github.com/openshift/client-go/console/listers/console/v1"
+	v1 "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
+	"github.com/openshift/console-operator/pkg/console/status"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleplugin"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// PluginStatusController checks every plugin enabled in the operator config, so plugins
+// which can't be loaded by the console are reported instead of being silently skipped.
+// The results are surfaced through the informational ConsolePlugins condition and the
+// console_plugin_status metric. The plugin services, their endpoints and the manifests
+// served by the backends are read on resync.
+//
+// Combined with the console health checks, the results are also used to quarantine the
+// plugins which break the console or whose backends stay unreachable. The quarantined
+// plugins are recorded in the console-plugin-quarantine configmap, which the operator
+// reads to leave them out of the console config. The same goes for the plugins whose
+// manifest requires another console release, recorded in the console-plugin-compatibility
+// configmap, so the operator itself never waits on the plugin backends.
+type PluginStatusController struct {
+	// clients
+	operatorClient            v1helpers.OperatorClient
+	configMapClient           coreclientv1.ConfigMapsGetter
+	operatorConfigLister      operatorv1listers.ConsoleLister
+	pluginServices            *util.PluginServices
+	consolePluginLister       consolev1listers.ConsolePluginLister
+	configMapLister           corev1listers.ConfigMapLister
+	operatorNSConfigMapLister corev1listers.ConfigMapLister
+	// quarantine
+	quarantineTracker          *quarantineTracker
+	lastQuarantinedPluginNames sets.Set[string]
+}
+
+func NewPluginStatusController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	pluginServices *util.PluginServices,
+	// informers
+	operatorConfigInformer v1.ConsoleInformer,
+	consolePluginInformer consoleinformersv1.ConsolePluginInformer,
+	configMapInformer coreinformersv1.ConfigMapInformer,
+	operatorNSConfigMapInformer coreinformersv1.ConfigMapInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &PluginStatusController{
+		operatorClient:             operatorClient,
+		configMapClient:            configMapClient,
+		operatorConfigLister:       operatorConfigInformer.Lister(),
+		pluginServices:             pluginServices,
+		consolePluginLister:        consolePluginInformer.Lister(),
+		configMapLister:            configMapInformer.Lister(),
+		operatorNSConfigMapLister:  operatorNSConfigMapInformer.Lister(),
+		quarantineTracker:          newQuarantineTracker(),
+		lastQuarantinedPluginNames: sets.New[string](),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithInformers(
+		consolePluginInformer.Informer(),
+	).WithFilteredEventsInformers( // service CA
+		util.IncludeNamesFilter(api.ServiceCAConfigMapName),
+		configMapInformer.Informer(),
+	).WithFilteredEventsInformers( // quarantined and incompatible plugins
+		util.IncludeNamesFilter(api.PluginQuarantineConfigMapName, api.PluginCompatibilityConfigMapName),
+		operatorNSConfigMapInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("PluginStatusController", recorder.WithComponentSuffix("plugin-status-controller"))
+}
+
+func (c *PluginStatusController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch operatorConfig.Spec.ManagementState {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console is in a managed state: checking plugins")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console is in an unmanaged state: skipping plugin checks")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console is in a removed state: skipping plugin checks")
+		metrics.HandlePluginStatus(nil)
+		return nil
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	plugins, err := c.consolePluginLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	// an invalid auto-enable policy is reported by the operator
+	enabledPluginNames, _ := consoleplugin.GetEnabledPluginNames(operatorConfig, plugins)
+
+	statuses := c.CheckPlugins(ctx, enabledPluginNames, consoleplugin.GetI18nLanguages(operatorConfig))
+	metrics.HandlePluginStatus(metricResults(statuses))
+
+	// a plugin which can't be loaded only breaks itself, the console stays available, so it is
+	// not aggregated into the ClusterOperator Degraded condition
+	reason, message := summarize(statuses)
+	statusHandler.AddCondition(status.HandleInformational("ConsolePlugins", reason, message))
+
+	records, quarantineErrReason, quarantineErr := c.syncQuarantine(ctx, enabledPluginNames, statuses, controllerContext.Recorder())
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("PluginQuarantineSync", quarantineErrReason, quarantineErr))
+	if quarantineErr != nil {
+		return statusHandler.FlushAndReturn(quarantineErr)
+	}
+	// the console stays available without the quarantined plugins, the quarantine is reported
+	// through events and a condition which is not aggregated into the ClusterOperator conditions
+	reason, message = summarizeQuarantine(records)
+	statusHandler.AddCondition(status.HandleInformational("PluginQuarantine", reason, message))
+
+	compatibilityErrReason, compatibilityErr := c.syncCompatibility(ctx, enabledPluginNames, statuses, controllerContext.Recorder())
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("PluginCompatibilitySync", compatibilityErrReason, compatibilityErr))
+	if compatibilityErr != nil {
+		return statusHandler.FlushAndReturn(compatibilityErr)
+	}
+
+	// the plugin failures are reported through the condition, no need to requeue
+	return statusHandler.FlushAndReturn(nil)
+}
+
+// CheckPlugins runs the checks for every enabled plugin. The localization resources of the
+// preloaded plugins are checked for each of the languages.
+func (c *PluginStatusController) CheckPlugins(ctx context.Context, enabledPluginNames []string, i18nLanguages []string) []*PluginStatus {
+	statuses := []*PluginStatus{}
+	plugins := []*consolev1.ConsolePlugin{}
+	for _, pluginName := range utilsub.RemoveDuplicateStr(enabledPluginNames) {
+		plugin, err := c.consolePluginLister.Get(pluginName)
+		if err != nil {
+			pluginStatus := &PluginStatus{Name: pluginName}
+			pluginStatus.fail(CheckExists, "NotFound", fmt.Errorf("failed to get %q plugin: %w", pluginName, err))
+			statuses = append(statuses, pluginStatus)
+			continue
+		}
+		plugins = append(plugins, plugin)
+	}
+
+	client, clientErr := c.getHTTPClient()
+	aggregatedSize := aggregatedCSPSize(plugins)
+	for _, plugin := range plugins {
+		pluginStatus := &PluginStatus{Name: plugin.Name}
+		statuses = append(statuses, pluginStatus)
+
+		pluginStatus.pass(CheckExists)
+		c.checkBackend(ctx, pluginStatus, plugin, client, clientErr, i18nLanguages)
+		c.checkProxyServices(ctx, pluginStatus, plugin)
+		if reason, err := checkCSPAggregation(plugin, aggregatedSize); err != nil {
+			pluginStatus.fail(CheckCSPAggregated, reason, err)
+		} else {
+			pluginStatus.pass(CheckCSPAggregated)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// syncQuarantine quarantines the plugins which break the console or whose backends stay
+// unreachable, and returns all the quarantined plugins. The quarantine of a plugin whose
+// backend recovered is lifted, admins clear the quarantine of any plugin by removing it from
+// the configmap. Either way, its tracking then starts over.
+func (c *PluginStatusController) syncQuarantine(ctx context.Context, enabledPluginNames []string, statuses []*PluginStatus, recorder events.Recorder) (map[string]consoleplugin.QuarantineRecord, string, error) {
+	now := time.Now()
+	existing, err := c.operatorNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleOperatorNamespace).Get(api.PluginQuarantineConfigMapName)
+	if apierrors.IsNotFound(err) {
+		existing = nil
+	} else if err != nil {
+		return nil, "FailedGet", err
+	}
+	records := consoleplugin.GetQuarantineRecords(existing)
+
+	for pluginName := range c.lastQuarantinedPluginNames {
+		if _, quarantined := records[pluginName]; !quarantined {
+			c.quarantineTracker.reset(now, pluginName)
+			recorder.Eventf("PluginQuarantineCleared", "quarantine of %q plugin cleared", pluginName)
+		}
+	}
+
+	c.quarantineTracker.observe(now, statuses)
+	for _, pluginName := range c.quarantineTracker.lift(records, statuses) {
+		delete(records, pluginName)
+		c.quarantineTracker.reset(now, pluginName)
+		recorder.Eventf("PluginQuarantineLifted", "quarantine of %q plugin lifted, its backend passed %d checks in a row", pluginName, backendHealthyChecksThreshold)
+	}
+	for pluginName, record := range c.quarantineTracker.quarantine(now, c.getConsoleUnhealthySince()) {
+		if _, quarantined := records[pluginName]; quarantined {
+			continue
+		}
+		records[pluginName] = record
+		recorder.Warningf("PluginQuarantined", "%q plugin removed from the console config: %s: %s", pluginName, record.Reason, record.Message)
+	}
+
+	// the records of plugins which are no longer enabled are dropped
+	enabledPlugins := sets.New(enabledPluginNames...)
+	for pluginName := range records {
+		if !enabledPlugins.Has(pluginName) {
+			delete(records, pluginName)
+		}
+	}
+	c.lastQuarantinedPluginNames = sets.KeySet(records)
+
+	if existing == nil && len(records) == 0 {
+		return records, "", nil
+	}
+	required, err := consoleplugin.DefaultQuarantineConfigMap(records)
+	if err != nil {
+		return nil, "FailedEncode", err
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, required); err != nil {
+		return nil, "FailedApply", err
+	}
+	return records, "", nil
+}
+
+// getConsoleUnhealthySince returns since when the console fails its health checks, or zero
+// time if it passes them.
+func (c *PluginStatusController) getConsoleUnhealthySince() time.Time {
+	_, operatorStatus, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return time.Time{}
+	}
+	condition := v1helpers.FindOperatorCondition(operatorStatus.Conditions, consoleHealthDegradedCondition)
+	if condition == nil || condition.Status != operatorsv1.ConditionTrue {
+		return time.Time{}
+	}
+	return condition.LastTransitionTime.Time
+}
+
+// checkBackend verifies the backend service, its endpoints and the plugin manifest served by it,
+// along with the localization resources if the plugin preloads them.
+func (c *PluginStatusController) checkBackend(ctx context.Context, pluginStatus *PluginStatus, plugin *consolev1.ConsolePlugin, client *http.Client, clientErr error, i18nLanguages []string) {
+	if plugin.Spec.Backend.Type != consolev1.Service || plugin.Spec.Backend.Service == nil {
+		pluginStatus.fail(CheckBackendService, "UnsupportedBackendType", fmt.Errorf("unknown backend type %q, currently only %q backend type is supported", plugin.Spec.Backend.Type, consolev1.Service))
+		return
+	}
+	backend := plugin.Spec.Backend.Service
+
+	service, err := c.pluginServices.GetService(ctx, backend.Namespace, backend.Name)
+	if err != nil {
+		pluginStatus.fail(CheckBackendService, "FailedGetService", fmt.Errorf("failed to get %s/%s service: %w", backend.Namespace, backend.Name, err))
+		return
+	}
+	if !hasPort(service, backend.Port) {
+		pluginStatus.fail(CheckBackendService, "PortNotFound", fmt.Errorf("%s/%s service does not expose port %d", backend.Namespace, backend.Name, backend.Port))
+		return
+	}
+	pluginStatus.pass(CheckBackendService)
+
+	if reason, err := c.checkEndpoints(ctx, backend.Namespace, backend.Name); err != nil {
+		pluginStatus.fail(CheckEndpointsReady, reason, err)
+		return
+	}
+	pluginStatus.pass(CheckEndpointsReady)
+
+	if clientErr != nil {
+		pluginStatus.fail(CheckManifestFetchable, "FailedLoadServiceCA", clientErr)
+		return
+	}
+	manifest, reason, err := consoleplugin.FetchManifest(client, consoleplugin.GetManifestURL(backend))
+	if err != nil {
+		pluginStatus.fail(CheckManifestFetchable, reason, err)
+		return
+	}
+	pluginStatus.manifest = manifest
+	pluginStatus.pass(CheckManifestFetchable)
+
+	if consoleplugin.GetI18nLoadType(plugin) != consolev1.Preload {
+		return
+	}
+	if reason, err := consoleplugin.CheckLocales(client, plugin, i18nLanguages); err != nil {
+		pluginStatus.fail(CheckLocalesServed, reason, err)
+		return
+	}
+	pluginStatus.pass(CheckLocalesServed)
+}
+
+func (c *PluginStatusController) checkEndpoints(ctx context.Context, namespace, serviceName string) (string, error) {
+	endpointSlices, err := c.pluginServices.ListEndpointSlices(ctx, namespace, serviceName)
+	if err != nil {
+		return "FailedListEndpoints", fmt.Errorf("failed to list endpoints of %s/%s service: %w", namespace, serviceName, err)
+	}
+	for _, endpointSlice := range endpointSlices {
+		for _, endpoint := range endpointSlice.Endpoints {
+			// nil should be interpreted as ready
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				return "", nil
+			}
+		}
+	}
+	return "NoReadyEndpoints", fmt.Errorf("%s/%s service has no ready endpoints", namespace, serviceName)
+}
+
+func (c *PluginStatusController) checkProxyServices(ctx context.Context, pluginStatus *PluginStatus, plugin *consolev1.ConsolePlugin) {
+	if len(plugin.Spec.Proxy) == 0 {
+		return
+	}
+	for _, proxy := range plugin.Spec.Proxy {
+		if proxy.Endpoint.Type != consolev1.ProxyTypeService || proxy.Endpoint.Service == nil {
+			pluginStatus.fail(CheckProxyServicesResolvable, "UnsupportedProxyType", fmt.Errorf("unknown proxy type %q of %q proxy, currently only %q proxy type is supported", proxy.Endpoint.Type, proxy.Alias, consolev1.ProxyTypeService))
+			return
+		}
+		proxyService := proxy.Endpoint.Service
+		_, err := c.pluginServices.GetService(ctx, proxyService.Namespace, proxyService.Name)
+		if apierrors.IsNotFound(err) {
+			pluginStatus.fail(CheckProxyServicesResolvable, "ServiceNotFound", fmt.Errorf("%s/%s service of %q proxy does not exist", proxyService.Namespace, proxyService.Name, proxy.Alias))
+			return
+		}
+		if err != nil {
+			pluginStatus.fail(CheckProxyServicesResolvable, "FailedGetService", fmt.Errorf("failed to get %s/%s service of %q proxy: %w", proxyService.Namespace, proxyService.Name, proxy.Alias, err))
+			return
+		}
+	}
+	pluginStatus.pass(CheckProxyServicesResolvable)
+}
+
+// getHTTPClient returns a client trusting the service CA, which signs the serving
+// certificates of the plugin backends.
+func (c *PluginStatusController) getHTTPClient() (*http.Client, error) {
+	serviceCA, err := c.configMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.ServiceCAConfigMapName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s configmap: %w", api.ServiceCAConfigMapName, err)
+	}
+	return consoleplugin.NewServiceCAClient(serviceCA)
+}
+
+func hasPort(service *corev1.Service, port int32) bool {
+	for _, servicePort := range service.Spec.Ports {
+		if servicePort.Port == port {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package pluginstatus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleplugin"
+)
+
+const (
+	// a plugin whose backend keeps failing for this long is quarantined
+	backendUnreachableThreshold = 10 * time.Minute
+	// the console turning unhealthy within this window after a plugin was enabled is
+	// attributed to the plugin
+	recentlyEnabledWindow = 5 * time.Minute
+	// the console has to stay unhealthy for this long before the recently enabled
+	// plugins are quarantined, so a single failed health check doesn't quarantine them
+	consoleUnhealthyThreshold = 2 * time.Minute
+	// a plugin quarantined for its unreachable backend is lifted from the quarantine once
+	// its backend passes this many checks in a row
+	backendHealthyChecksThreshold = 5
+	// condition reported by the console health check controller
+	consoleHealthDegradedCondition = "RouteHealthDegraded"
+)
+
+// quarantineTracker keeps track of when the plugins were enabled and since when their
+// backends are unreachable, to decide which plugins are quarantined. The state is kept in
+// memory, plugins enabled before the operator started are not considered recently enabled.
+type quarantineTracker struct {
+	initialized      bool
+	enabledSince     map[string]time.Time
+	unreachableSince map[string]time.Time
+	// checks in a row the backends of the quarantined plugins passed
+	healthyChecks map[string]int
+}
+
+func newQuarantineTracker() *quarantineTracker {
+	return &quarantineTracker{
+		enabledSince:     map[string]time.Time{},
+		unreachableSince: map[string]time.Time{},
+		healthyChecks:    map[string]int{},
+	}
+}
+
+// observe records the results of the plugin checks.
+func (t *quarantineTracker) observe(now time.Time, statuses []*PluginStatus) {
+	enabledSince := map[string]time.Time{}
+	unreachableSince := map[string]time.Time{}
+	for _, pluginStatus := range statuses {
+		since, tracked := t.enabledSince[pluginStatus.Name]
+		if !tracked && t.initialized {
+			since = now
+		}
+		enabledSince[pluginStatus.Name] = since
+
+		if !isBackendUnreachable(pluginStatus) {
+			continue
+		}
+		if since, tracked := t.unreachableSince[pluginStatus.Name]; tracked {
+			unreachableSince[pluginStatus.Name] = since
+		} else {
+			unreachableSince[pluginStatus.Name] = now
+		}
+	}
+	t.enabledSince = enabledSince
+	t.unreachableSince = unreachableSince
+	t.initialized = true
+}
+
+// reset restarts the tracking of a plugin whose quarantine was cleared, as if it was just enabled.
+func (t *quarantineTracker) reset(now time.Time, pluginName string) {
+	t.enabledSince[pluginName] = now
+	delete(t.unreachableSince, pluginName)
+	delete(t.healthyChecks, pluginName)
+}
+
+// lift returns the plugins quarantined for their unreachable backend, whose backend passed
+// enough checks in a row to leave the quarantine. Plugins quarantined for breaking the console
+// are not lifted, a healthy backend says nothing about the console, admins clear them.
+func (t *quarantineTracker) lift(records map[string]consoleplugin.QuarantineRecord, statuses []*PluginStatus) []string {
+	healthyChecks := map[string]int{}
+	lifted := []string{}
+	for _, pluginStatus := range statuses {
+		record, quarantined := records[pluginStatus.Name]
+		if !quarantined || record.Reason != "BackendUnreachable" || !isBackendHealthy(pluginStatus) {
+			continue
+		}
+		healthyChecks[pluginStatus.Name] = t.healthyChecks[pluginStatus.Name] + 1
+		if healthyChecks[pluginStatus.Name] >= backendHealthyChecksThreshold {
+			lifted = append(lifted, pluginStatus.Name)
+		}
+	}
+	t.healthyChecks = healthyChecks
+	sort.Strings(lifted)
+	return lifted
+}
+
+// quarantine returns the plugins which should be quarantined. The consoleUnhealthySince is
+// zero while the console passes its health checks.
+func (t *quarantineTracker) quarantine(now time.Time, consoleUnhealthySince time.Time) map[string]consoleplugin.QuarantineRecord {
+	records := map[string]consoleplugin.QuarantineRecord{}
+	for pluginName, since := range t.unreachableSince {
+		if now.Sub(since) < backendUnreachableThreshold {
+			continue
+		}
+		records[pluginName] = consoleplugin.QuarantineRecord{
+			Reason:  "BackendUnreachable",
+			Message: fmt.Sprintf("plugin backend unreachable since %s", since.UTC().Format(time.RFC3339)),
+			Since:   metav1.NewTime(now),
+		}
+	}
+
+	if consoleUnhealthySince.IsZero() || now.Sub(consoleUnhealthySince) < consoleUnhealthyThreshold {
+		return records
+	}
+	for pluginName, since := range t.enabledSince {
+		if since.IsZero() || consoleUnhealthySince.Before(since) || consoleUnhealthySince.Sub(since) > recentlyEnabledWindow {
+			continue
+		}
+		records[pluginName] = consoleplugin.QuarantineRecord{
+			Reason:  "ConsoleUnhealthyAfterEnable",
+			Message: fmt.Sprintf("console failing health checks since %s, right after the plugin was enabled", consoleUnhealthySince.UTC().Format(time.RFC3339)),
+			Since:   metav1.NewTime(now),
+		}
+	}
+	return records
+}
+
+// isBackendUnreachable returns true if the plugin backend or its manifest can't be reached.
+func isBackendUnreachable(pluginStatus *PluginStatus) bool {
+	for _, check := range pluginStatus.Failed() {
+		switch check.Type {
+		case CheckBackendService, CheckEndpointsReady:
+			return true
+		case CheckManifestFetchable:
+			// failing to load the service CA is not the plugin's fault
+			return check.Reason != "FailedLoadServiceCA"
+		}
+	}
+	return false
+}
+
+// isBackendHealthy returns true if the plugin backend serves the plugin manifest.
+func isBackendHealthy(pluginStatus *PluginStatus) bool {
+	for _, check := range pluginStatus.Checks {
+		if check.Type == CheckManifestFetchable {
+			return check.Passed
+		}
+	}
+	return false
+}
+
+// summarizeQuarantine returns the reason and the message listing the quarantined plugins,
+// or the NoPluginsQuarantined reason and an empty message if there are none.
+func summarizeQuarantine(records map[string]consoleplugin.QuarantineRecord) (string, string) {
+	if len(records) == 0 {
+		return "NoPluginsQuarantined", ""
+	}
+	messages := []string{}
+	for _, pluginName := range consoleplugin.QuarantinedPluginNames(records) {
+		record := records[pluginName]
+		messages = append(messages, fmt.Sprintf("%s: %s: %s", pluginName, record.Reason, record.Message))
+	}
+	return "PluginsQuarantined", fmt.Sprintf("%d plugin(s) removed from the console config, remove them from the %s/%s configmap to enable them again: %s",
+		len(messages), api.OpenShiftConsoleOperatorNamespace, api.PluginQuarantineConfigMapName, strings.Join(messages, "; "))
+}
@@ -0,0 +1,179 @@
+package pluginstatus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleplugin"
+)
+
+func TestQuarantine(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	healthy := func(name string) *PluginStatus {
+		pluginStatus := &PluginStatus{Name: name}
+		pluginStatus.pass(CheckExists)
+		pluginStatus.pass(CheckBackendService)
+		pluginStatus.pass(CheckEndpointsReady)
+		return pluginStatus
+	}
+	unreachable := func(name string) *PluginStatus {
+		pluginStatus := &PluginStatus{Name: name}
+		pluginStatus.pass(CheckExists)
+		pluginStatus.pass(CheckBackendService)
+		pluginStatus.fail(CheckEndpointsReady, "NoReadyEndpoints", errors.New("no ready endpoints"))
+		return pluginStatus
+	}
+
+	tests := []struct {
+		name string
+		// each observation happens a minute after the previous one
+		observations          [][]*PluginStatus
+		consoleUnhealthySince time.Time
+		want                  []string
+	}{
+		{
+			name: "Healthy plugins are not quarantined",
+			observations: [][]*PluginStatus{
+				{healthy("plugin-a")},
+				{healthy("plugin-a"), healthy("plugin-b")},
+			},
+		},
+		{
+			name: "Backend unreachable for less than the threshold",
+			observations: [][]*PluginStatus{
+				{unreachable("plugin-a")},
+				{unreachable("plugin-a")},
+			},
+		},
+		{
+			name: "Backend unreachable for the threshold",
+			observations: func() [][]*PluginStatus {
+				observations := [][]*PluginStatus{}
+				for i := 0; i <= 10; i++ {
+					observations = append(observations, []*PluginStatus{unreachable("plugin-a"), healthy("plugin-b")})
+				}
+				return observations
+			}(),
+			want: []string{"plugin-a"},
+		},
+		{
+			name: "Backend recovered before the threshold",
+			observations: func() [][]*PluginStatus {
+				observations := [][]*PluginStatus{}
+				for i := 0; i <= 10; i++ {
+					observations = append(observations, []*PluginStatus{unreachable("plugin-a")})
+				}
+				observations[5] = []*PluginStatus{healthy("plugin-a")}
+				return observations
+			}(),
+		},
+		{
+			name: "Console unhealthy right after the plugin was enabled",
+			observations: [][]*PluginStatus{
+				{healthy("plugin-a")},
+				{healthy("plugin-a"), healthy("plugin-b")},
+				{healthy("plugin-a"), healthy("plugin-b")},
+				{healthy("plugin-a"), healthy("plugin-b")},
+				{healthy("plugin-a"), healthy("plugin-b")},
+			},
+			consoleUnhealthySince: start.Add(2 * time.Minute),
+			want:                  []string{"plugin-b"},
+		},
+		{
+			name: "Console unhealthy for less than the threshold",
+			observations: [][]*PluginStatus{
+				{healthy("plugin-a")},
+				{healthy("plugin-a"), healthy("plugin-b")},
+				{healthy("plugin-a"), healthy("plugin-b")},
+			},
+			consoleUnhealthySince: start.Add(2 * time.Minute),
+		},
+		{
+			name: "Console unhealthy before the plugin was enabled",
+			observations: [][]*PluginStatus{
+				{healthy("plugin-a")},
+				{healthy("plugin-a")},
+				{healthy("plugin-a")},
+				{healthy("plugin-a"), healthy("plugin-b")},
+				{healthy("plugin-a"), healthy("plugin-b")},
+				{healthy("plugin-a"), healthy("plugin-b")},
+			},
+			consoleUnhealthySince: start.Add(time.Minute),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newQuarantineTracker()
+			now := start
+			for i, statuses := range tt.observations {
+				now = start.Add(time.Duration(i) * time.Minute)
+				tracker.observe(now, statuses)
+			}
+			quarantined := []string{}
+			for pluginName := range tracker.quarantine(now, tt.consoleUnhealthySince) {
+				quarantined = append(quarantined, pluginName)
+			}
+			if len(tt.want) == 0 {
+				tt.want = []string{}
+			}
+			if diff := deep.Equal(quarantined, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestQuarantineReset(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pluginStatus := &PluginStatus{Name: "plugin-a"}
+	pluginStatus.fail(CheckBackendService, "FailedGetService", errors.New("not found"))
+
+	tracker := newQuarantineTracker()
+	tracker.observe(start, []*PluginStatus{pluginStatus})
+	now := start.Add(backendUnreachableThreshold)
+	tracker.observe(now, []*PluginStatus{pluginStatus})
+	if records := tracker.quarantine(now, time.Time{}); len(records) != 1 {
+		t.Fatalf("expected plugin to be quarantined, got %v", records)
+	}
+
+	tracker.reset(now, "plugin-a")
+	tracker.observe(now.Add(time.Minute), []*PluginStatus{pluginStatus})
+	if records := tracker.quarantine(now.Add(time.Minute), time.Time{}); len(records) != 0 {
+		t.Errorf("expected cleared plugin to get a new threshold, got %v", records)
+	}
+}
+
+func TestQuarantineLift(t *testing.T) {
+	backendHealthy := &PluginStatus{Name: "plugin-a"}
+	backendHealthy.pass(CheckBackendService)
+	backendHealthy.pass(CheckEndpointsReady)
+	backendHealthy.pass(CheckManifestFetchable)
+	backendUnreachable := &PluginStatus{Name: "plugin-a"}
+	backendUnreachable.pass(CheckBackendService)
+	backendUnreachable.fail(CheckEndpointsReady, "NoReadyEndpoints", errors.New("no ready endpoints"))
+	consoleBreaking := &PluginStatus{Name: "plugin-b"}
+	consoleBreaking.pass(CheckManifestFetchable)
+
+	records := map[string]consoleplugin.QuarantineRecord{
+		"plugin-a": {Reason: "BackendUnreachable"},
+		"plugin-b": {Reason: "ConsoleUnhealthyAfterEnable"},
+	}
+	tracker := newQuarantineTracker()
+	for i := 1; i < backendHealthyChecksThreshold; i++ {
+		if lifted := tracker.lift(records, []*PluginStatus{backendHealthy, consoleBreaking}); len(lifted) != 0 {
+			t.Fatalf("expected no plugin to be lifted after %d checks, got %v", i, lifted)
+		}
+	}
+	// a failed check starts the count over
+	tracker.lift(records, []*PluginStatus{backendUnreachable, consoleBreaking})
+	for i := 1; i < backendHealthyChecksThreshold; i++ {
+		tracker.lift(records, []*PluginStatus{backendHealthy, consoleBreaking})
+	}
+	lifted := tracker.lift(records, []*PluginStatus{backendHealthy, consoleBreaking})
+	if diff := deep.Equal(lifted, []string{"plugin-a"}); diff != nil {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,134 @@
+package pluginstatus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	consolev1 "github.com/openshift/api/console/v1"
+
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleplugin"
+)
+
+const (
+	// CheckExists verifies that the enabled plugin has a ConsolePlugin resource.
+	CheckExists = "Exists"
+	// CheckBackendService verifies that the backend is of a supported type and its service exists.
+	CheckBackendService = "BackendService"
+	// CheckEndpointsReady verifies that the backend service has at least one ready endpoint.
+	CheckEndpointsReady = "EndpointsReady"
+	// CheckManifestFetchable verifies that the plugin-manifest.json is served by the backend.
+	CheckManifestFetchable = "ManifestFetchable"
+	// CheckLocalesServed verifies that the localization resources of preloaded plugins are served by the backend.
+	CheckLocalesServed = "LocalesServed"
+	// CheckProxyServicesResolvable verifies that the services of all proxy endpoints exist.
+	CheckProxyServicesResolvable = "ProxyServicesResolvable"
+	// CheckCSPAggregated verifies that the CSP directives of the plugin fit into the aggregated policy.
+	CheckCSPAggregated = "CSPAggregated"
+
+	// The aggregated CSP directives are sent in a single response header. Keep them within
+	// the header buffer sizes of common proxies, otherwise the console won't load at all.
+	maxAggregatedCSPSize = 16 * 1024
+)
+
+// Check is the result of a single check of a plugin.
+type Check struct {
+	Type    string `json:"type"`
+	Passed  bool   `json:"passed"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// PluginStatus holds the results of the checks of an enabled plugin. Checks which
+// depend on a failed one are not run.
+type PluginStatus struct {
+	Name   string  `json:"name"`
+	Checks []Check `json:"checks"`
+	// manifest served by the backend, nil if it couldn't be fetched
+	manifest *consoleplugin.Manifest
+}
+
+func (s *PluginStatus) pass(checkType string) {
+	s.Checks = append(s.Checks, Check{Type: checkType, Passed: true})
+}
+
+func (s *PluginStatus) fail(checkType, reason string, err error) {
+	s.Checks = append(s.Checks, Check{Type: checkType, Reason: reason, Message: err.Error()})
+}
+
+// Failed returns the failed checks of the plugin.
+func (s *PluginStatus) Failed() []Check {
+	failed := []Check{}
+	for _, check := range s.Checks {
+		if !check.Passed {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+// summarize returns the reason and the message aggregated from the failed checks of all
+// plugins, or the AllPluginsLoadable reason and an empty message if all the checks passed.
+func summarize(statuses []*PluginStatus) (string, string) {
+	messages := []string{}
+	for _, status := range statuses {
+		for _, check := range status.Failed() {
+			messages = append(messages, fmt.Sprintf("%s: %s: %s", status.Name, check.Type, check.Message))
+		}
+	}
+	if len(messages) == 0 {
+		return "AllPluginsLoadable", ""
+	}
+	return "PluginsNotLoadable", fmt.Sprintf("%d plugin check(s) failed: %s", len(messages), strings.Join(messages, "; "))
+}
+
+// metricResults converts the statuses into the form recorded by the console_plugin_status metric.
+func metricResults(statuses []*PluginStatus) map[string]map[string]bool {
+	results := map[string]map[string]bool{}
+	for _, status := range statuses {
+		results[status.Name] = map[string]bool{}
+		for _, check := range status.Checks {
+			results[status.Name][check.Type] = check.Passed
+		}
+	}
+	return results
+}
+
+// aggregatedCSPSize returns the size of the CSP directive values aggregated across plugins,
+// counting each unique value of a directive once, same as the console config does.
+func aggregatedCSPSize(plugins []*consolev1.ConsolePlugin) int {
+	aggregated := map[consolev1.DirectiveType]map[consolev1.CSPDirectiveValue]struct{}{}
+	for _, plugin := range plugins {
+		for _, csp := range plugin.Spec.ContentSecurityPolicy {
+			if aggregated[csp.Directive] == nil {
+				aggregated[csp.Directive] = map[consolev1.CSPDirectiveValue]struct{}{}
+			}
+			for _, value := range csp.Values {
+				aggregated[csp.Directive][value] = struct{}{}
+			}
+		}
+	}
+
+	size := 0
+	for _, values := range aggregated {
+		for value := range values {
+			// the value and the separating space
+			size += len(value) + 1
+		}
+	}
+	return size
+}
+
+// checkCSPAggregation fails for every plugin contributing to the aggregated CSP directives,
+// if they don't fit into the limit.
+func checkCSPAggregation(plugin *consolev1.ConsolePlugin, aggregatedSize int) (string, error) {
+	if len(plugin.Spec.ContentSecurityPolicy) == 0 || aggregatedSize <= maxAggregatedCSPSize {
+		return "", nil
+	}
+	directives := []string{}
+	for _, csp := range plugin.Spec.ContentSecurityPolicy {
+		directives = append(directives, string(csp.Directive))
+	}
+	sort.Strings(directives)
+	return "CSPTooLarge", fmt.Errorf("aggregated CSP directives of all plugins take %d bytes, exceeding the %d bytes limit, plugin contributes to %s", aggregatedSize, maxAggregatedCSPSize, strings.Join(directives, ", "))
+}
@@ -0,0 +1,87 @@
+package pluginstatus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	consolev1 "github.com/openshift/api/console/v1"
+)
+
+func TestCheckCSPAggregation(t *testing.T) {
+	pluginWithCSP := func(name string, values ...string) *consolev1.ConsolePlugin {
+		cspValues := []consolev1.CSPDirectiveValue{}
+		for _, value := range values {
+			cspValues = append(cspValues, consolev1.CSPDirectiveValue(value))
+		}
+		return &consolev1.ConsolePlugin{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: consolev1.ConsolePluginSpec{
+				ContentSecurityPolicy: []consolev1.ConsolePluginCSP{
+					{Directive: consolev1.ScriptSrc, Values: cspValues},
+				},
+			},
+		}
+	}
+	largeValue := "https://" + strings.Repeat("a", 1000) + ".com"
+	largeValues := []string{}
+	for i := 0; i < 16; i++ {
+		largeValues = append(largeValues, largeValue+string(rune('a'+i)))
+	}
+
+	tests := []struct {
+		name       string
+		plugins    []*consolev1.ConsolePlugin
+		wantReason string
+	}{
+		{
+			name: "Shared values are counted once",
+			plugins: []*consolev1.ConsolePlugin{
+				pluginWithCSP("plugin-a", "https://cdn.example.com"),
+				pluginWithCSP("plugin-b", "https://cdn.example.com"),
+			},
+		},
+		{
+			name: "Aggregated directives exceed the limit",
+			plugins: []*consolev1.ConsolePlugin{
+				pluginWithCSP("plugin-a", largeValues...),
+				pluginWithCSP("plugin-b", strings.Replace(largeValue, "https", "wss", 1)),
+			},
+			wantReason: "CSPTooLarge",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size := aggregatedCSPSize(tt.plugins)
+			for _, plugin := range tt.plugins {
+				reason, _ := checkCSPAggregation(plugin, size)
+				if diff := deep.Equal(reason, tt.wantReason); diff != nil {
+					t.Error(diff)
+				}
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	healthy := &PluginStatus{Name: "healthy"}
+	healthy.pass(CheckExists)
+	missing := &PluginStatus{Name: "missing"}
+	missing.fail(CheckExists, "NotFound", errors.New(`consoleplugin.console.openshift.io "missing" not found`))
+
+	reason, message := summarize([]*PluginStatus{healthy, missing})
+	if diff := deep.Equal(reason, "PluginsNotLoadable"); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(message, `1 plugin check(s) failed: missing: Exists: consoleplugin.console.openshift.io "missing" not found`); diff != nil {
+		t.Error(diff)
+	}
+
+	if reason, message := summarize([]*PluginStatus{healthy}); reason != "AllPluginsLoadable" || message != "" {
+		t.Errorf("expected no failure, got %q: %s", reason, message)
+	}
+}
@@ -10,10 +10,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	coreinformersv1 "k8s.io/client-go/informers/core/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
 	// openshift
@@ -50,8 +54,12 @@ type RouteSyncController struct {
 	ingressConfigLister        configlistersv1.IngressLister
 	ingressControllerLister    operatorv1listers.IngressControllerLister
 	secretLister               corev1listers.SecretLister
+	targetNSSecretLister       corev1listers.SecretLister
 	infrastructureConfigLister configlistersv1.InfrastructureLister
 	clusterVersionLister       configlistersv1.ClusterVersionLister
+	namespaceLister            corev1listers.NamespaceLister
+	// nil if cert-manager was not installed when the operator started
+	certificateLister cache.GenericLister
 }
 
 func NewRouteSyncController(
@@ -62,11 +70,15 @@ func NewRouteSyncController(
 	// clients
 	operatorClient v1helpers.OperatorClient,
 	routev1Client routeclientv1.RoutesGetter,
+	dynamicClient dynamic.Interface,
 	// informers
 	operatorConfigInformer v1.ConsoleInformer,
 	ingressControllerInformer v1.IngressControllerInformer,
+	namespaceInformer coreinformersv1.NamespaceInformer,
 	secretInformer coreinformersv1.SecretInformer,
+	targetNSSecretInformer coreinformersv1.SecretInformer,
 	routeInformer routesinformersv1.RouteInformer,
+	dynamicInformers dynamicinformer.DynamicSharedInformerFactory, // `openshift-console` namespace
 	// events
 	recorder events.Recorder,
 ) factory.Controller {
@@ -80,13 +92,15 @@ func NewRouteSyncController(
 		routeClient:                routev1Client,
 		routeLister:                routeInformer.Lister(),
 		secretLister:               secretInformer.Lister(),
+		targetNSSecretLister:       targetNSSecretInformer.Lister(),
 		infrastructureConfigLister: configInformer.Config().V1().Infrastructures().Lister(),
 		clusterVersionLister:       configInformer.Config().V1().ClusterVersions().Lister(),
+		namespaceLister:            namespaceInformer.Lister(),
 	}
 
 	configV1Informers := configInformer.Config().V1()
 
-	return factory.New().
+	controllerFactory := factory.New().
 		WithFilteredEventsInformers( // configs
 			util.IncludeNamesFilter(api.ConfigResourceName),
 			configV1Informers.Consoles().Informer(),
@@ -94,12 +108,27 @@ func NewRouteSyncController(
 			configV1Informers.Ingresses().Informer(),
 		).WithInformers(
 		secretInformer.Informer(),
-	).WithFilteredEventsInformers(
-		util.IncludeNamesFilter(api.DefaultIngressController),
+	).WithFilteredEventsInformers( // secrets issued by cert-manager, renewals are picked up immediately
+		ctrl.isTLSSecret,
+		targetNSSecretInformer.Informer(),
+	).WithInformers( // ingress controllers — the routes can be placed on any shard
 		ingressControllerInformer.Informer(),
+		namespaceInformer.Informer(),
 	).WithInformers( // routes — watch all routes in namespace for additional route discovery
 		routeInformer.Informer(),
-	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+	)
+
+	// cert-manager is optional, an informer for a missing resource would never sync
+	if found, _ := util.IsResourceEnabled(dynamicClient, routesub.CertificateGVR, api.OpenShiftConsoleNamespace); found {
+		certificateInformer := dynamicInformers.ForResource(routesub.CertificateGVR)
+		ctrl.certificateLister = certificateInformer.Lister()
+		controllerFactory = controllerFactory.WithFilteredEventsInformers(ctrl.isTLSSecretCertificate, certificateInformer.Informer())
+	} else {
+		klog.Infof("certificates resource does not exist in cluster, polling for cert-manager to be installed")
+		util.StartPollAndRestartIfResourceEnabled(dynamicClient, routesub.CertificateGVR, api.OpenShiftConsoleNamespace)
+	}
+
+	return controllerFactory.ResyncEvery(time.Minute).WithSync(ctrl.Sync).
 		ToController(fmt.Sprintf("%sRouteController", strings.Title(routeName)), recorder.WithComponentSuffix(fmt.Sprintf("%s-route-controller", routeName)))
 }
 
@@ -145,11 +174,6 @@ func (c *RouteSyncController) Sync(ctx context.Context, controllerContext factor
 		return statusHandler.FlushAndReturn(err)
 	}
 
-	ingressControllerConfig, err := c.ingressControllerLister.IngressControllers(api.IngressControllerNamespace).Get(api.DefaultIngressController)
-	if err != nil {
-		return statusHandler.FlushAndReturn(err)
-	}
-
 	clusterVersionConfig, err := c.clusterVersionLister.Get("version")
 	if err != nil {
 		return statusHandler.FlushAndReturn(err)
@@ -162,13 +186,31 @@ func (c *RouteSyncController) Sync(ctx context.Context, controllerContext factor
 		return statusHandler.FlushAndReturn(nil)
 	}
 
+	// The ExposureSyncController takes over if the console should be exposed through
+	// an Ingress or an HTTPRoute, the routes are not needed in that case.
+	if routesub.GetExposureMode(updatedOperatorConfig) != api.ExposureModeRoute {
+		if err = c.removeRoute(ctx, routesub.GetCustomRouteName(c.routeName)); err != nil {
+			return statusHandler.FlushAndReturn(err)
+		}
+		return statusHandler.FlushAndReturn(c.removeRoute(ctx, c.routeName))
+	}
+
 	ingressConfig, err := c.ingressConfigLister.Get(api.ConfigResourceName)
 	if err != nil {
 		return statusHandler.FlushAndReturn(err)
 	}
-	routeConfig := routesub.NewRouteConfig(updatedOperatorConfig, ingressConfig, c.routeName)
 
-	typePrefix := fmt.Sprintf("%sCustomRouteSync", strings.Title(c.routeName))
+	typePrefix := fmt.Sprintf("%sIngressShard", strings.Title(c.routeName))
+	// a shard which can't admit the routes is reported, the routes stay on the default IngressController
+	ingressControllerConfig, ingressShardErrReason, ingressShardErr := routesub.GetIngressController(c.ingressControllerLister, c.namespaceLister, updatedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded(typePrefix, ingressShardErrReason, ingressShardErr))
+	if ingressControllerConfig == nil {
+		return statusHandler.FlushAndReturn(ingressShardErr)
+	}
+
+	routeConfig := routesub.NewShardedRouteConfig(updatedOperatorConfig, ingressConfig, ingressControllerConfig, c.routeName)
+
+	typePrefix = fmt.Sprintf("%sCustomRouteSync", strings.Title(c.routeName))
 	// try to sync the custom route first. If the sync fails for any reason, error
 	// out the sync loop and inform about this fact instead of putting default
 	// route into inaccessible state.
@@ -180,7 +222,7 @@ func (c *RouteSyncController) Sync(ctx context.Context, controllerContext factor
 	}
 
 	typePrefix = fmt.Sprintf("%sDefaultRouteSync", strings.Title(c.routeName))
-	_, defaultRouteErrReason, defaultRouteErr := c.SyncDefaultRoute(ctx, routeConfig, ingressConfig, controllerContext)
+	_, defaultRouteErrReason, defaultRouteErr := c.SyncDefaultRoute(ctx, routeConfig, controllerContext)
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded(typePrefix, defaultRouteErrReason, defaultRouteErr))
 	statusHandler.AddCondition(status.HandleUpgradable(typePrefix, defaultRouteErrReason, defaultRouteErr))
 
@@ -205,7 +247,7 @@ func (c *RouteSyncController) removeRoute(ctx context.Context, routeName string)
 	return err
 }
 
-func (c *RouteSyncController) SyncDefaultRoute(ctx context.Context, routeConfig *routesub.RouteConfig, ingressConfig *configv1.Ingress, controllerContext factory.SyncContext) (*routev1.Route, string, error) {
+func (c *RouteSyncController) SyncDefaultRoute(ctx context.Context, routeConfig *routesub.RouteConfig, controllerContext factory.SyncContext) (*routev1.Route, string, error) {
 	customTLSSecret, configErr := c.GetDefaultRouteTLSSecret(ctx, routeConfig)
 	if configErr != nil {
 		return nil, "InvalidDefaultRouteConfig", configErr
@@ -215,7 +257,7 @@ func (c *RouteSyncController) SyncDefaultRoute(ctx context.Context, routeConfig
 		return nil, "InvalidCustomTLSSecret", secretValidationErr
 	}
 
-	requiredDefaultRoute := routeConfig.DefaultRoute(customTLSCert, ingressConfig)
+	requiredDefaultRoute := routeConfig.DefaultRoute(customTLSCert)
 
 	defaultRoute, _, defaultRouteError := routesub.ApplyRoute(c.routeClient, requiredDefaultRoute)
 	if defaultRouteError != nil {
@@ -277,7 +319,7 @@ func (c *RouteSyncController) SyncCustomRoute(ctx context.Context, routeConfig *
 
 func (c *RouteSyncController) GetCustomRouteTLSSecret(ctx context.Context, routeConfig *routesub.RouteConfig) (*corev1.Secret, error) {
 	if routeConfig.IsCustomTLSSecretSet() {
-		customTLSSecret, customTLSSecretErr := c.secretLister.Secrets(api.OpenShiftConfigNamespace).Get(routeConfig.GetCustomTLSSecretName())
+		customTLSSecret, customTLSSecretErr := c.getTLSSecret(ctx, routeConfig, routeConfig.GetCustomTLSSecretName())
 		if customTLSSecretErr != nil {
 			return nil, fmt.Errorf("failed to GET custom route TLS secret: %s", customTLSSecretErr)
 		}
@@ -297,13 +339,70 @@ func (c *RouteSyncController) GetDefaultRouteTLSSecret(ctx context.Context, rout
 		return nil, nil
 	}
 
-	secret, secretErr := c.secretLister.Secrets(api.OpenShiftConfigNamespace).Get(routeConfig.GetDefaultTLSSecretName())
+	secret, secretErr := c.getTLSSecret(ctx, routeConfig, routeConfig.GetDefaultTLSSecretName())
 	if secretErr != nil {
 		return nil, fmt.Errorf("failed to GET default route TLS secret: %s", secretErr)
 	}
 	return secret, nil
 }
 
+// getTLSSecret returns the custom TLS secret from the namespace configured for the routes.
+// Secrets in the openshift-console namespace can be issued by cert-manager, in which case
+// they are only used once the issuing Certificate is ready.
+func (c *RouteSyncController) getTLSSecret(ctx context.Context, routeConfig *routesub.RouteConfig, secretName string) (*corev1.Secret, error) {
+	switch namespace := routeConfig.GetTLSSecretNamespace(); namespace {
+	case api.OpenShiftConfigNamespace:
+		return c.secretLister.Secrets(namespace).Get(secretName)
+	case api.OpenShiftConsoleNamespace:
+		if err := routesub.CheckCertificateReady(c.certificateLister, secretName); err != nil {
+			return nil, err
+		}
+		return c.targetNSSecretLister.Secrets(namespace).Get(secretName)
+	default:
+		return nil, fmt.Errorf("custom TLS secrets can only be read from %q or %q namespace, %q is set in %q annotation", api.OpenShiftConfigNamespace, api.OpenShiftConsoleNamespace, namespace, api.RouteTLSSecretNamespaceAnnotation)
+	}
+}
+
+// isTLSSecret returns true for the custom TLS secrets of the routes, if they are read from
+// the openshift-console namespace.
+func (c *RouteSyncController) isTLSSecret(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	metaObj, ok := obj.(metav1.Object)
+	return ok && c.isTLSSecretName(metaObj.GetName())
+}
+
+// isTLSSecretCertificate returns true for the cert-manager Certificates issuing the custom
+// TLS secrets of the routes.
+func (c *RouteSyncController) isTLSSecretCertificate(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	certificate, ok := obj.(*unstructured.Unstructured)
+	return ok && c.isTLSSecretName(routesub.GetCertificateSecretName(certificate))
+}
+
+func (c *RouteSyncController) isTLSSecretName(name string) bool {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return false
+	}
+	ingressConfig, err := c.ingressConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return false
+	}
+	ingressController, err := routesub.GetServedIngressController(c.ingressControllerLister, c.namespaceLister, operatorConfig)
+	if err != nil {
+		return false
+	}
+	routeConfig := routesub.NewShardedRouteConfig(operatorConfig, ingressConfig, ingressController, c.routeName)
+	if routeConfig.GetTLSSecretNamespace() != api.OpenShiftConsoleNamespace || len(name) == 0 {
+		return false
+	}
+	return name == routeConfig.GetCustomTLSSecretName() || name == routeConfig.GetDefaultTLSSecretName()
+}
+
 func (c *RouteSyncController) ValidateCustomRouteConfig(ctx context.Context, routeConfig *routesub.RouteConfig, ingressControllerConfig *operatorsv1.IngressController) error {
 	// Check if the default cetrificate is set in the ingress controller config.
 	// If it is, then the custom route TLS secret is optional.
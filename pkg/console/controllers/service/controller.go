@@ -10,6 +10,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	coreinformersv1 "k8s.io/client-go/informers/core/v1"
 	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
 	operatorsv1 "github.com/openshift/api/operator/v1"
@@ -34,14 +35,16 @@ import (
 // the informers will automatically notify it of changes
 // and kick the sync loop
 type ServiceSyncController struct {
-	serviceName    string
-	operatorClient v1helpers.OperatorClient
-	serviceClient  coreclientv1.ServicesGetter
+	serviceName     string
+	operatorClient  v1helpers.OperatorClient
+	serviceClient   coreclientv1.ServicesGetter
+	namespaceLister corev1listers.NamespaceLister
 
 	operatorConfigLister       operatorv1listers.ConsoleLister
 	ingressConfigLister        configlistersv1.IngressLister
 	infrastructureConfigLister configlistersv1.InfrastructureLister
 	clusterVersionLister       configlistersv1.ClusterVersionLister
+	ingressControllerLister    operatorv1listers.IngressControllerLister
 }
 
 // factory func needs clients and informers
@@ -57,6 +60,8 @@ func NewServiceSyncController(
 	// informers
 	operatorConfigInformer operatorinformersv1.ConsoleInformer,
 	serviceInformer coreinformersv1.ServiceInformer,
+	ingressControllerInformer operatorinformersv1.IngressControllerInformer,
+	namespaceInformer coreinformersv1.NamespaceInformer,
 	// events
 	recorder events.Recorder,
 ) factory.Controller {
@@ -67,8 +72,10 @@ func NewServiceSyncController(
 		operatorConfigLister:       operatorConfigInformer.Lister(),
 		ingressConfigLister:        configInformer.Config().V1().Ingresses().Lister(),
 		serviceClient:              corev1Client,
+		namespaceLister:            namespaceInformer.Lister(),
 		infrastructureConfigLister: configInformer.Config().V1().Infrastructures().Lister(),
 		clusterVersionLister:       configInformer.Config().V1().ClusterVersions().Lister(),
+		ingressControllerLister:    ingressControllerInformer.Lister(),
 	}
 
 	configV1Informers := configInformer.Config().V1()
@@ -81,6 +88,9 @@ func NewServiceSyncController(
 		).WithFilteredEventsInformers( // console resources
 		util.IncludeNamesFilter(serviceName, ctrl.getRedirectServiceName()),
 		serviceInformer.Informer(),
+	).WithInformers( // ingress controllers — the routes can be placed on any shard
+		ingressControllerInformer.Informer(),
+		namespaceInformer.Informer(),
 	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
 		ToController("ConsoleServiceController", recorder.WithComponentSuffix("console-service-controller"))
 }
@@ -127,7 +137,11 @@ func (c *ServiceSyncController) Sync(ctx context.Context, controllerContext fact
 	ingressDisabled := util.IsExternalControlPlaneWithIngressDisabled(infrastructureConfig, clusterVersionConfig)
 
 	// Service name matches the Route's so it can be used as well, for creating RouteConfig
-	routeConfig := routesub.NewRouteConfig(updatedOperatorConfig, ingressConfig, c.serviceName)
+	ingressController, err := routesub.GetServedIngressController(c.ingressControllerLister, c.namespaceLister, updatedOperatorConfig)
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+	routeConfig := routesub.NewShardedRouteConfig(updatedOperatorConfig, ingressConfig, ingressController, c.serviceName)
 
 	requiredSvc := c.getDefaultService(ingressDisabled)
 	svcErr := util.RetryOnTransientError(func() error {
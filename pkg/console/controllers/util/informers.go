@@ -3,6 +3,8 @@ package util
 import (
 	"context"
 	"fmt"
+	"sync"
+	"syscall"
 	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -16,8 +18,12 @@ import (
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 )
@@ -138,3 +144,61 @@ func (s *InformerWithSwitch) sync(ctx context.Context, _ factory.SyncContext) er
 
 	return nil
 }
+
+// IsResourceEnabled returns false if the resource is not served, e.g. when an optional operator
+// like OLM or cert-manager, or the Gateway API CRDs are not installed. Informers must not be
+// created for such resources, they would never sync. Pass an empty namespace for cluster
+// scoped resources.
+func IsResourceEnabled(client dynamic.Interface, resource schema.GroupVersionResource, namespace string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+	_, err := client.Resource(resource).Namespace(namespace).List(ctx, metav1.ListOptions{Limit: 1})
+	// If List returns NotFound, then we know the resource does not exist
+	if err != nil && apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return true, err
+}
+
+var (
+	pollingResourcesLock sync.Mutex
+	pollingResources     = map[schema.GroupVersionResource]bool{}
+)
+
+// StartPollAndRestartIfResourceEnabled watches for an optional resource, which was not served
+// when the informers were created at start up, to show up later. The container is restarted
+// once it does, so the informer for the resource is created. Only one poll is started per
+// resource, no matter how many controllers ask for it.
+func StartPollAndRestartIfResourceEnabled(client dynamic.Interface, resource schema.GroupVersionResource, namespace string) {
+	pollingResourcesLock.Lock()
+	defer pollingResourcesLock.Unlock()
+	if pollingResources[resource] {
+		return
+	}
+	pollingResources[resource] = true
+
+	go func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var enabled bool
+		// Poll Resource to see if resource has been enabled
+		wait.PollInfiniteWithContext(ctx, time.Minute*5, func(ctx context.Context) (done bool, err error) {
+			enabled, err = IsResourceEnabled(client, resource, namespace)
+			if err != nil {
+				klog.Errorf("failed to find if resource is enabled, retrying in 5 minutes: %v", err)
+			}
+			return enabled, nil
+		})
+
+		// If we exit out of a poll and enabled is not set to true do not issue interrupt
+		if !enabled {
+			return
+		}
+
+		// This is a brute force technique that won't involve additional permissions
+		// TODO: investigate alternative approaches for re-attaching informer
+		klog.Infof("%s resource has been enabled, restarting container", resource.String())
+		syscall.Kill(syscall.Getpid(), syscall.SIGINT)
+	}()
+}
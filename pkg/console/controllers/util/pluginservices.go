@@ -0,0 +1,115 @@
+package util
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	// kube
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	discoveryclientv1 "k8s.io/client-go/kubernetes/typed/discovery/v1"
+	"k8s.io/utils/clock"
+)
+
+// PluginServicesTTL is how long the plugin services and their EndpointSlices are read from the
+// cache, it is short of the resync of the controllers checking the plugins.
+const PluginServicesTTL = 30 * time.Second
+
+// PluginServices reads the backend and proxy services of the console plugins, along with their
+// EndpointSlices. The plugins can be deployed to any namespace, so the services are read from the
+// API server instead of being watched cluster wide. The reads are cached for a short time, so the
+// controllers sharing it don't read the same services on every sync.
+type PluginServices struct {
+	serviceClient       coreclientv1.ServicesGetter
+	endpointSliceClient discoveryclientv1.EndpointSlicesGetter
+	clock               clock.PassiveClock
+
+	lock           sync.Mutex
+	services       map[types.NamespacedName]pluginServiceEntry
+	endpointSlices map[types.NamespacedName]pluginEndpointSlicesEntry
+}
+
+type pluginServiceEntry struct {
+	service *corev1.Service
+	err     error
+	expires time.Time
+}
+
+type pluginEndpointSlicesEntry struct {
+	endpointSlices []*discoveryv1.EndpointSlice
+	err            error
+	expires        time.Time
+}
+
+func NewPluginServices(serviceClient coreclientv1.ServicesGetter, endpointSliceClient discoveryclientv1.EndpointSlicesGetter) *PluginServices {
+	return &PluginServices{
+		serviceClient:       serviceClient,
+		endpointSliceClient: endpointSliceClient,
+		clock:               clock.RealClock{},
+		services:            map[types.NamespacedName]pluginServiceEntry{},
+		endpointSlices:      map[types.NamespacedName]pluginEndpointSlicesEntry{},
+	}
+}
+
+// GetService returns the service, a missing service is cached as its NotFound error.
+func (p *PluginServices) GetService(ctx context.Context, namespace, name string) (*corev1.Service, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.clock.Now()
+	if entry, ok := p.services[key]; ok && now.Before(entry.expires) {
+		return entry.service, entry.err
+	}
+	service, err := p.serviceClient.Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		service = nil
+	}
+	p.pruneExpired(now)
+	p.services[key] = pluginServiceEntry{service: service, err: err, expires: now.Add(PluginServicesTTL)}
+	return service, err
+}
+
+// ListEndpointSlices returns the EndpointSlices of the service.
+func (p *PluginServices) ListEndpointSlices(ctx context.Context, namespace, serviceName string) ([]*discoveryv1.EndpointSlice, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: serviceName}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.clock.Now()
+	if entry, ok := p.endpointSlices[key]; ok && now.Before(entry.expires) {
+		return entry.endpointSlices, entry.err
+	}
+	var endpointSlices []*discoveryv1.EndpointSlice
+	list, err := p.endpointSliceClient.EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: serviceName}).String(),
+	})
+	if err == nil {
+		for i := range list.Items {
+			endpointSlices = append(endpointSlices, &list.Items[i])
+		}
+	}
+	p.pruneExpired(now)
+	p.endpointSlices[key] = pluginEndpointSlicesEntry{endpointSlices: endpointSlices, err: err, expires: now.Add(PluginServicesTTL)}
+	return endpointSlices, err
+}
+
+// pruneExpired drops the entries of services which are no longer read, e.g. the ones of
+// removed plugins.
+func (p *PluginServices) pruneExpired(now time.Time) {
+	for key, entry := range p.services {
+		if !now.Before(entry.expires) {
+			delete(p.services, key)
+		}
+	}
+	for key, entry := range p.endpointSlices {
+		if !now.Before(entry.expires) {
+			delete(p.endpointSlices, key)
+		}
+	}
+}
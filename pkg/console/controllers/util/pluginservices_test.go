@@ -0,0 +1,61 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestPluginServices(t *testing.T) {
+	ctx := context.TODO()
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "plugin"}},
+		&discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Name: "backend-1", Namespace: "plugin", Labels: map[string]string{discoveryv1.LabelServiceName: "backend"}}},
+		&discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Name: "other-1", Namespace: "plugin", Labels: map[string]string{discoveryv1.LabelServiceName: "other"}}},
+	)
+	fakeClock := clocktesting.NewFakePassiveClock(time.Now())
+	pluginServices := NewPluginServices(kubeClient.CoreV1(), kubeClient.DiscoveryV1())
+	pluginServices.clock = fakeClock
+
+	if _, err := pluginServices.GetService(ctx, "plugin", "backend"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pluginServices.GetService(ctx, "plugin", "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected a NotFound error, got %v", err)
+	}
+	endpointSlices, err := pluginServices.ListEndpointSlices(ctx, "plugin", "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpointSlices) != 1 || endpointSlices[0].Name != "backend-1" {
+		t.Errorf("expected the backend-1 EndpointSlice, got %v", endpointSlices)
+	}
+
+	// cached reads don't reach the API server
+	reads := len(kubeClient.Actions())
+	_, _ = pluginServices.GetService(ctx, "plugin", "backend")
+	_, _ = pluginServices.GetService(ctx, "plugin", "missing")
+	_, _ = pluginServices.ListEndpointSlices(ctx, "plugin", "backend")
+	if got := len(kubeClient.Actions()); got != reads {
+		t.Errorf("expected %d API reads, got %d", reads, got)
+	}
+
+	// a created service is read once the cached NotFound expired
+	if _, err := kubeClient.CoreV1().Services("plugin").Create(ctx, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "plugin"}}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pluginServices.GetService(ctx, "plugin", "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the cached NotFound error, got %v", err)
+	}
+	fakeClock.SetTime(fakeClock.Now().Add(PluginServicesTTL))
+	if _, err := pluginServices.GetService(ctx, "plugin", "missing"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	// kube
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	consolev1 "github.com/openshift/api/console/v1"
+	consolev1listers "github.com/openshift/client-go/console/listers/console/v1"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleplugin"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+)
+
+const (
+	// PluginInventoryPath is served by the operator's metrics and health server.
+	PluginInventoryPath = "/debug/console/plugins"
+
+	pluginProxyEndpoint = "/api/proxy/plugin/"
+)
+
+// PluginInventory lists every ConsolePlugin along with the way it ended up in the
+// console-config, so plugin problems can be debugged without decoding the configmap.
+type PluginInventory struct {
+	Plugins []PluginInfo `json:"plugins"`
+}
+
+// PluginInfo is the console-config of a single plugin. Only the name is set for plugins
+// which are not enabled.
+type PluginInfo struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	// LoadOrder is the 1-based position of the plugin in the pluginsOrder.
+	LoadOrder             int                                  `json:"loadOrder,omitempty"`
+	BackendURL            string                               `json:"backendURL,omitempty"`
+	ProxyAliases          []string                             `json:"proxyAliases,omitempty"`
+	ContentSecurityPolicy map[consolev1.DirectiveType][]string `json:"contentSecurityPolicy,omitempty"`
+	I18nNamespace         string                               `json:"i18nNamespace,omitempty"`
+	I18nLoadType          consolev1.LoadType                   `json:"i18nLoadType,omitempty"`
+}
+
+type pluginInventoryHandler struct {
+	configMapLister     corev1listers.ConfigMapLister
+	consolePluginLister consolev1listers.ConsolePluginLister
+}
+
+// NewPluginInventoryHandler returns the handler serving the plugin inventory as JSON.
+func NewPluginInventoryHandler(configMapLister corev1listers.ConfigMapLister, consolePluginLister consolev1listers.ConsolePluginLister) http.Handler {
+	return &pluginInventoryHandler{
+		configMapLister:     configMapLister,
+		consolePluginLister: consolePluginLister,
+	}
+}
+
+func (h *pluginInventoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	inventory, err := h.getPluginInventory()
+	if apierrors.IsNotFound(err) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(inventory); err != nil {
+		klog.V(4).Infof("failed to write plugin inventory: %v", err)
+	}
+}
+
+func (h *pluginInventoryHandler) getPluginInventory() (*PluginInventory, error) {
+	consoleConfigMap, err := h.configMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleConfigMapName)
+	if err != nil {
+		return nil, err
+	}
+	consoleConfig, err := configmap.ReadConsoleConfig(consoleConfigMap)
+	if err != nil {
+		return nil, err
+	}
+	plugins, err := h.consolePluginLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plugins: %w", err)
+	}
+	return GetPluginInventory(consoleConfig, plugins), nil
+}
+
+// GetPluginInventory matches the plugins with the console-config. The CSP directive values
+// of a plugin are only listed if they ended up in the aggregated policy.
+func GetPluginInventory(consoleConfig *consoleserver.Config, plugins []*consolev1.ConsolePlugin) *PluginInventory {
+	inventory := &PluginInventory{Plugins: []PluginInfo{}}
+	for _, plugin := range plugins {
+		info := PluginInfo{Name: plugin.Name}
+		backendURL, enabled := consoleConfig.Plugins[plugin.Name]
+		if enabled {
+			info.Enabled = true
+			info.BackendURL = backendURL
+			info.LoadOrder = getLoadOrder(consoleConfig.PluginsOrder, plugin.Name)
+			info.ProxyAliases = getProxyAliases(consoleConfig.Proxy.Services, plugin.Name)
+			info.ContentSecurityPolicy = getContentSecurityPolicy(consoleConfig.ContentSecurityPolicy, plugin)
+			info.I18nNamespace, info.I18nLoadType = getI18nNamespace(consoleConfig, plugin.Name)
+		}
+		inventory.Plugins = append(inventory.Plugins, info)
+	}
+	sort.Slice(inventory.Plugins, func(i, j int) bool {
+		return inventory.Plugins[i].Name < inventory.Plugins[j].Name
+	})
+	return inventory
+}
+
+func getLoadOrder(pluginsOrder []string, pluginName string) int {
+	for i, name := range pluginsOrder {
+		if name == pluginName {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func getProxyAliases(services []consoleserver.ProxyService, pluginName string) []string {
+	prefix := pluginProxyEndpoint + pluginName + "/"
+	aliases := []string{}
+	for _, service := range services {
+		if alias, ok := strings.CutPrefix(service.ConsoleAPIPath, prefix); ok {
+			aliases = append(aliases, strings.TrimSuffix(alias, "/"))
+		}
+	}
+	if len(aliases) == 0 {
+		return nil
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+func getContentSecurityPolicy(aggregated map[consolev1.DirectiveType][]string, plugin *consolev1.ConsolePlugin) map[consolev1.DirectiveType][]string {
+	result := map[consolev1.DirectiveType][]string{}
+	for _, csp := range plugin.Spec.ContentSecurityPolicy {
+		for _, value := range csp.Values {
+			if slices.Contains(aggregated[csp.Directive], string(value)) && !slices.Contains(result[csp.Directive], string(value)) {
+				result[csp.Directive] = append(result[csp.Directive], string(value))
+			}
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func getI18nNamespace(consoleConfig *consoleserver.Config, pluginName string) (string, consolev1.LoadType) {
+	namespace := consoleplugin.GetI18nNamespace(pluginName)
+	switch {
+	case slices.Contains(consoleConfig.I18nNamespaces, namespace):
+		return namespace, consolev1.Preload
+	}
+	return "", ""
+}
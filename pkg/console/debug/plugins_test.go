@@ -0,0 +1,76 @@
+package debug
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	consolev1 "github.com/openshift/api/console/v1"
+
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+)
+
+func TestGetPluginInventory(t *testing.T) {
+	consoleConfig := &consoleserver.Config{
+		Plugins: map[string]string{
+			"plugin-a": "https://svc-a.ns-a.svc.cluster.local:9443/",
+			"plugin-b": "https://svc-b.ns-b.svc.cluster.local:9443/",
+		},
+		PluginsOrder:   []string{"plugin-b", "plugin-a"},
+		I18nNamespaces: []string{"plugin__plugin-a"},
+		Proxy: consoleserver.Proxy{
+			Services: []consoleserver.ProxyService{
+				{ConsoleAPIPath: "/api/proxy/plugin/plugin-a/backend/"},
+				{ConsoleAPIPath: "/api/proxy/plugin/plugin-a/api/"},
+				{ConsoleAPIPath: "/api/proxy/plugin/plugin-ab/other/"},
+			},
+		},
+		ContentSecurityPolicy: map[consolev1.DirectiveType][]string{
+			consolev1.ScriptSrc: {"https://cdn.example.com"},
+		},
+	}
+	plugins := []*consolev1.ConsolePlugin{
+		{ObjectMeta: metav1.ObjectMeta{Name: "plugin-c"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "plugin-b"}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "plugin-a"},
+			Spec: consolev1.ConsolePluginSpec{
+				ContentSecurityPolicy: []consolev1.ConsolePluginCSP{
+					{Directive: consolev1.ScriptSrc, Values: []consolev1.CSPDirectiveValue{"https://cdn.example.com"}},
+					// excluded by the CSP guardrails
+					{Directive: consolev1.ConnectSrc, Values: []consolev1.CSPDirectiveValue{"https://api.example.com"}},
+				},
+			},
+		},
+	}
+	want := &PluginInventory{
+		Plugins: []PluginInfo{
+			{
+				Name:         "plugin-a",
+				Enabled:      true,
+				LoadOrder:    2,
+				BackendURL:   "https://svc-a.ns-a.svc.cluster.local:9443/",
+				ProxyAliases: []string{"api", "backend"},
+				ContentSecurityPolicy: map[consolev1.DirectiveType][]string{
+					consolev1.ScriptSrc: {"https://cdn.example.com"},
+				},
+				I18nNamespace: "plugin__plugin-a",
+				I18nLoadType:  consolev1.Preload,
+			},
+			{
+				Name:       "plugin-b",
+				Enabled:    true,
+				LoadOrder:  1,
+				BackendURL: "https://svc-b.ns-b.svc.cluster.local:9443/",
+			},
+			{
+				Name: "plugin-c",
+			},
+		},
+	}
+	if diff := deep.Equal(GetPluginInventory(consoleConfig, plugins), want); diff != nil {
+		t.Error(diff)
+	}
+}
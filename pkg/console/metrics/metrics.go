@@ -22,10 +22,35 @@ var (
 		},
 		[]string{"major", "minor", "gitCommit", "gitVersion"},
 	)
+
+	consolePluginStatus = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Name: "console_plugin_status",
+			Help: "Result of the checks of the enabled console plugins, '1' if the check passes, '0' otherwise.",
+		},
+		[]string{"plugin", "check"},
+	)
 )
 
 func init() {
 	legacyregistry.MustRegister(consoleURL)
+	legacyregistry.MustRegister(consolePluginStatus)
+}
+
+// HandlePluginStatus records the results of the plugin checks. Plugins which are no
+// longer enabled are dropped, so the metric only reflects the current plugins.
+func HandlePluginStatus(results map[string]map[string]bool) {
+	defer recoverMetricPanic()
+	consolePluginStatus.Reset()
+	for plugin, checks := range results {
+		for check, passed := range checks {
+			value := 0.0
+			if passed {
+				value = 1
+			}
+			consolePluginStatus.WithLabelValues(plugin, check).Set(value)
+		}
+	}
 }
 
 func HandleConsoleURL(oldURL, newURL string) {
@@ -4,7 +4,6 @@ import (
 	// standard lib
 	"context"
 	"fmt"
-	"syscall"
 	"time"
 
 	// kube
@@ -12,7 +11,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	corev1 "k8s.io/client-go/informers/core/v1"
@@ -78,13 +76,16 @@ type consoleOperator struct {
 	managedNSConfigMapLister corev1listers.ConfigMapLister // for openshift-config-managed namespace
 	nodeLister               corev1listers.NodeLister
 	deploymentClient         appsclientv1.DeploymentsGetter
+	deploymentLister         appsv1listers.DeploymentLister
 	// openshift
 	operatorNSConfigMapLister corev1listers.ConfigMapLister //for openshift-console-operator namespace
 	configNSConfigMapLister   corev1listers.ConfigMapLister //for openshift-config namespace
 	oauthClientLister         oauthlistersv1.OAuthClientLister
 	consoleOperatorLister     operatorlistersv1.ConsoleLister
 	routeClient               routeclientv1.RoutesGetter
+	namespaceLister           corev1listers.NamespaceLister
 	routeLister               routev1listers.RouteLister
+	ingressControllerLister   operatorlistersv1.IngressControllerLister
 	versionGetter             status.VersionGetter
 	// lister
 	consolePluginLister listerv1.ConsolePluginLister
@@ -105,6 +106,10 @@ type trackables struct {
 	organizationID       string
 	accountMail          string
 	customLogoConfigMaps []string
+	// plugin CSP directives excluded by the guardrails, to record an event only when they change
+	cspViolations map[string]string
+	// when the plugins without a compatibility verdict were first held back
+	pendingPluginsSince map[string]time.Time
 }
 
 func NewConsoleOperator(
@@ -130,6 +135,8 @@ func NewConsoleOperator(
 	// routes
 	routeClient routeclientv1.RoutesGetter,
 	routeInformer routesinformersv1.RouteInformer,
+	ingressControllerInformer operatorinformerv1.IngressControllerInformer,
+	namespaceInformer corev1.NamespaceInformer,
 	// plugins
 	consolePluginInformer consoleinformersv1.ConsolePluginInformer,
 	// openshift config
@@ -174,6 +181,7 @@ func NewConsoleOperator(
 		configNSSecretLister: configSecretsInformer.Lister(),
 
 		configMapClient: corev1Client,
+		namespaceLister: namespaceInformer.Lister(),
 
 		targetNSConfigMapLister:   targetNSConfigMapInformer.Lister(),
 		operatorNSConfigMapLister: operatorNSConfigMapInformer.Lister(),
@@ -182,12 +190,15 @@ func NewConsoleOperator(
 
 		nodeLister:       nodeInformer.Lister(),
 		deploymentClient: deploymentClient,
+		deploymentLister: deploymentInformer.Lister(),
 		dynamicClient:    dynamicClient,
 		// openshift
 		oauthClientLister: oauthClientSwitchedInformer.Lister(),
 		routeClient:       routeClient,
 		routeLister:       routeInformer.Lister(),
-		versionGetter:     versionGetter,
+		// the console hosts are derived from the domain of the IngressController shard
+		ingressControllerLister: ingressControllerInformer.Lister(),
+		versionGetter:           versionGetter,
 		// plugins
 		consolePluginLister: consolePluginInformer.Lister(),
 		resourceSyncer:      resourceSyncer,
@@ -219,13 +230,13 @@ func NewConsoleOperator(
 		accountMail:    "",
 	}
 
-	if found, _ := isResourceEnabled(dynamicClient, olmGroupVersionResource); found {
+	if found, _ := util.IsResourceEnabled(dynamicClient, olmGroupVersionResource, ""); found {
 		olmConfigInformer := dynamicInformers.ForResource(olmGroupVersionResource)
 		informers = append(informers, olmConfigInformer.Informer())
 	} else {
 		klog.Info("olmconfigs resource does not exist in cluster, launching poll and disabling olmconfigs informer")
 		c.trackables.isOLMDisabled = true
-		c.startPollAndRestartIfResourceEnabled(olmGroupVersionResource)
+		util.StartPollAndRestartIfResourceEnabled(dynamicClient, olmGroupVersionResource, "")
 	}
 
 	return factory.New().
@@ -240,6 +251,8 @@ func NewConsoleOperator(
 	).WithInformers(
 		nodeInformer.Informer(),
 		consolePluginInformer.Informer(),
+		ingressControllerInformer.Informer(),
+		namespaceInformer.Informer(),
 	).WithInformers(
 		targetNSConfigMapInformer.Informer(),
 	).WithFilteredEventsInformers(
@@ -252,7 +265,7 @@ func NewConsoleOperator(
 		util.IncludeNamesFilter(deployment.ConsoleOauthConfigName, api.ConsoleServingCertName),
 		secretsInformer.Informer(),
 	).WithFilteredEventsInformers(
-		util.IncludeNamesFilter(telemetry.TelemetryConfigMapName),
+		util.IncludeNamesFilter(telemetry.TelemetryConfigMapName, api.PluginQuarantineConfigMapName, api.PluginCompatibilityConfigMapName, api.OIDCDiscoveryConfigMapName),
 		operatorNSConfigMapInformer.Informer(),
 	).WithFilteredEventsInformers(
 		util.IncludeNamesFilter(telemetry.TelemeterClientDeploymentName),
@@ -261,47 +274,6 @@ func NewConsoleOperator(
 		ToController("ConsoleOperator", recorder.WithComponentSuffix("console-operator"))
 }
 
-// startPollAndRestartIfResourceEnabled is a helper function to watch for the re-creation of a resource that is initiated
-// at start up, for example the OLMConfigs resource, because OLM is an optional operator and we initiate an informer at start up
-// this method tries to offer a way of trigger a container restart.
-func (c *consoleOperator) startPollAndRestartIfResourceEnabled(resource schema.GroupVersionResource) {
-	go func() {
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-
-		var enabled bool
-		// Poll Resource to see if resource has been re-enabled
-		wait.PollInfiniteWithContext(ctx, time.Minute*5, func(ctx context.Context) (done bool, err error) {
-			enabled, err = isResourceEnabled(c.dynamicClient, resource)
-			if err != nil {
-				klog.Errorf("failed to find if resource is enabled, retrying in 5 minutes: %v", err)
-			}
-			return enabled, nil
-		})
-
-		// If we exit out of a poll and enabled is not set to true do not issue interrupt
-		if !enabled {
-			return
-		}
-
-		// This is a brute force technique that won't involve additional permissions
-		// TODO: investigate alternative approaches for re-attaching informer
-		klog.Info("OLM has been re-enabled, restarting container")
-		syscall.Kill(syscall.Getpid(), syscall.SIGINT)
-	}()
-}
-
-func isResourceEnabled(client dynamic.Interface, resource schema.GroupVersionResource) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
-	defer cancel()
-	_, err := client.Resource(resource).List(ctx, metav1.ListOptions{})
-	// If List returns NotFound, then we know the resource does not exist
-	if err != nil && apierrors.IsNotFound(err) {
-		return false, nil
-	}
-	return true, err
-}
-
 type configSet struct {
 	Console        *configv1.Console
 	Operator       *operatorsv1.Console
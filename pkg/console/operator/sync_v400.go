@@ -39,7 +39,10 @@ import (
 	customerrors "github.com/openshift/console-operator/pkg/console/errors"
 	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
+	authnsub "github.com/openshift/console-operator/pkg/console/subresource/authentication"
 	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleplugin"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
 	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
 	oauthsub "github.com/openshift/console-operator/pkg/console/subresource/oauthclient"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
@@ -55,6 +58,11 @@ import (
 // outages are still reported promptly.
 const deploymentAvailableGracePeriod = 15 * time.Second
 
+// pluginCompatibilityCheckTimeout bounds how long a newly enabled plugin is held back until the
+// plugin status controller records a compatibility verdict, e.g. when its manifest can't be
+// fetched. The plugin is served afterwards, a negative verdict recorded later still excludes it.
+const pluginCompatibilityCheckTimeout = 5 * time.Minute
+
 // The sync loop starts from zero and works its way through the requirements for a running console.
 // If at any point something is missing, it creates/updates that piece and immediately dies.
 // The next loop will pick up where they previous left off and move the process forward one step.
@@ -69,9 +77,21 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 		consoleURL    *url.URL
 	)
 
+	// the IngressController is only needed to tell the route hosts
+	var ingressController *operatorv1.IngressController
 	if len(set.Operator.Spec.Ingress.ConsoleURL) == 0 {
+		var err error
+		ingressController, err = routesub.GetServedIngressController(co.ingressControllerLister, co.namespaceLister, updatedOperatorConfig)
+		if err != nil {
+			return statusHandler.FlushAndReturn(err)
+		}
+	}
+	if len(set.Operator.Spec.Ingress.ConsoleURL) == 0 && routesub.GetExposureMode(updatedOperatorConfig) != api.ExposureModeRoute {
+		// exposed through an Ingress or an HTTPRoute, their admission is reported by the ExposureSyncController
+		consoleURL = routesub.NewShardedRouteConfig(updatedOperatorConfig, set.Ingress, ingressController, api.OpenShiftConsoleRouteName).GetExposedURL()
+	} else if len(set.Operator.Spec.Ingress.ConsoleURL) == 0 {
 		routeName := api.OpenShiftConsoleRouteName
-		routeConfig := routesub.NewRouteConfig(updatedOperatorConfig, set.Ingress, routeName)
+		routeConfig := routesub.NewShardedRouteConfig(updatedOperatorConfig, set.Ingress, ingressController, routeName)
 		if routeConfig.IsCustomHostnameSet() {
 			routeName = api.OpenshiftConsoleCustomRouteName
 		}
@@ -103,14 +123,33 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 		return statusHandler.FlushAndReturn(err)
 	}
 
+	// until a switch of the authentication type passes its preflight checks, the auth config of the
+	// console config and the auth resources of the deployment keep serving the previous type, the
+	// rest of the console is synced as usual
+	servedConsoleConfig, authTransitionErrReason, authTransitionErr := co.CheckAuthTransition(ctx, authnConfig, controllerContext.Recorder())
+	statusHandler.AddCondition(status.HandleProgressing("AuthenticationTransition", authTransitionErrReason, authTransitionErr))
+	servesOIDC := authnConfig.Spec.Type == configv1.AuthenticationTypeOIDC
+	if servedConsoleConfig != nil {
+		servesOIDC = !servesOIDC
+	}
+
 	var (
 		targetNamespaceAuthServerCA *corev1.ConfigMap
 		sessionSecret               *corev1.Secret
+		oidcProvider                *configv1.OIDCProvider
+		oidcClientConfig            *configv1.OIDCClientConfig
 	)
-	switch authnConfig.Spec.Type {
-	case configv1.AuthenticationTypeOIDC:
-		if len(authnConfig.Spec.OIDCProviders) > 0 {
-			oidcProvider := authnConfig.Spec.OIDCProviders[0]
+	switch {
+	case servesOIDC && servedConsoleConfig != nil:
+		// the OIDC provider may be gone from the authentication config already
+		targetNamespaceAuthServerCA, sessionSecret, err = co.getServedOIDCResources()
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OIDCProviderTrustedAuthorityConfigGet", "FailedGet", err))
+		if err != nil {
+			return statusHandler.FlushAndReturn(err)
+		}
+	case servesOIDC:
+		oidcProvider, oidcClientConfig = authnsub.GetOIDCClientConfig(authnConfig, api.TargetNamespace, api.OpenShiftConsoleName)
+		if oidcProvider != nil {
 			certAuthorityName := oidcProvider.Issuer.CertificateAuthority.Name
 			if certAuthorityName != "" {
 				targetNamespaceAuthServerCA, err = co.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(certAuthorityName)
@@ -145,7 +184,40 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 		return statusHandler.FlushAndReturn(olmLifecycleMetadataErr)
 	}
 
+	serviceCAConfigMap, serviceCAErrReason, serviceCAErr := co.SyncServiceCAConfigMap(ctx, set.Operator)
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("ServiceCASync", serviceCAErrReason, serviceCAErr))
+	if serviceCAErr != nil {
+		return statusHandler.FlushAndReturn(serviceCAErr)
+	}
+
 	additionalHosts := routesub.GetAdditionalRouteHostnames(set.Ingress)
+	enabledPluginNames, pluginAutoEnableErr := co.GetEnabledPluginNames(set.Operator)
+	statusHandler.AddCondition(status.HandleWarning("PluginAutoEnableInvalid", "InvalidPolicy", pluginAutoEnableErr))
+	availablePlugins := co.GetAvailablePlugins(enabledPluginNames)
+	// quarantined plugins are reported by the plugin status controller
+	availablePlugins = co.RemoveQuarantinedPlugins(availablePlugins)
+	// incompatible plugins are only reported, the compatible ones are still enabled
+	availablePlugins, pendingPluginNames, pluginCompatibilityErrReason, pluginCompatibilityErr := co.GetCompatiblePlugins(availablePlugins)
+	statusHandler.AddCondition(status.HandleWarning("PluginsIncompatible", pluginCompatibilityErrReason, pluginCompatibilityErr))
+	if len(pendingPluginNames) != 0 {
+		statusHandler.AddCondition(status.HandleInformational("PluginCompatibilityCheck", "PluginsPending", fmt.Sprintf("plugin(s) held back until the plugin status controller checks their manifest: %s", strings.Join(pendingPluginNames, ", "))))
+	} else {
+		statusHandler.AddCondition(status.HandleInformational("PluginCompatibilityCheck", "AllPluginsChecked", ""))
+	}
+	availablePlugins, pluginCSPGuardrailsErrReason, pluginCSPGuardrailsErr := co.ApplyCSPGuardrails(set.Operator, availablePlugins, controllerContext.Recorder())
+	statusHandler.AddCondition(status.HandleDegraded("CSPGuardrails", pluginCSPGuardrailsErrReason, pluginCSPGuardrailsErr))
+	for pluginName, violation := range co.trackables.cspViolations {
+		statusHandler.AddCondition(status.HandleWarning(pluginCSPDirectivesExcludedConditionPrefix+pluginName, "DirectivesExcluded", fmt.Errorf("%s", violation)))
+	}
+	statusHandler.RemoveStaleConditions(pluginCSPDirectivesExcludedConditionPrefix)
+
+	// the plugins are still enabled, their order is resolved as far as possible
+	pluginsOrder, pluginDependencyMissingErr, pluginDependencyCycleErr := consoleplugin.OrderPlugins(enabledPluginNames, availablePlugins)
+	statusHandler.AddCondition(status.HandleWarning("PluginDependencyMissing", "MissingDependencies", pluginDependencyMissingErr))
+	statusHandler.AddCondition(status.HandleWarning("PluginDependencyCycle", "DependencyCycle", pluginDependencyCycleErr))
+
+	oidcLogoutRedirect, oidcLogoutRedirectErrReason, oidcLogoutRedirectErr := co.GetOIDCLogoutRedirect(set.Operator, set.Console, oidcProvider, oidcClientConfig, consoleURL, additionalHosts)
+	statusHandler.AddCondition(status.HandleDegraded("OIDCLogoutRedirect", oidcLogoutRedirectErrReason, oidcLogoutRedirectErr))
 
 	cm, cmErrReason, cmErr := co.SyncConfigMap(
 		ctx,
@@ -154,24 +226,22 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 		set.Infrastructure,
 		set.OAuth,
 		authnConfig,
+		servedConsoleConfig,
+		oidcLogoutRedirect,
 		consoleRoute,
 		controllerContext.Recorder(),
 		consoleURL.Hostname(),
 		techPreviewEnabled,
 		olmLifecycleMetadataEnabled,
 		additionalHosts,
+		availablePlugins,
+		pluginsOrder,
 	)
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("ConfigMapSync", cmErrReason, cmErr))
 	if cmErr != nil {
 		return statusHandler.FlushAndReturn(cmErr)
 	}
 
-	serviceCAConfigMap, serviceCAErrReason, serviceCAErr := co.SyncServiceCAConfigMap(ctx, set.Operator)
-	statusHandler.AddConditions(status.HandleProgressingOrDegraded("ServiceCASync", serviceCAErrReason, serviceCAErr))
-	if serviceCAErr != nil {
-		return statusHandler.FlushAndReturn(serviceCAErr)
-	}
-
 	trustedCAConfigMap, trustedCAErrReason, trustedCAErr := co.SyncTrustedCAConfigMap(ctx, set.Operator)
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("TrustedCASync", trustedCAErrReason, trustedCAErr))
 	if trustedCAErr != nil {
@@ -179,9 +249,8 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 	}
 
 	var oauthServingCertConfigMap *corev1.ConfigMap
-	switch authnConfig.Spec.Type {
 	// We don't disable auth since the internal OAuth server is not disabled even with auth type 'None'.
-	case "", configv1.AuthenticationTypeIntegratedOAuth, configv1.AuthenticationTypeNone:
+	if !servesOIDC {
 		var oauthServingCertErrReason string
 		var oauthServingCertErr error
 
@@ -197,6 +266,13 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 	if secErr != nil {
 		return statusHandler.FlushAndReturn(secErr)
 	}
+	if servedConsoleConfig == nil {
+		clientSecret, secErr = co.promotePendingClientSecret(ctx, clientSecret, controllerContext.Recorder())
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretPromotion", "FailedApply", secErr))
+		if secErr != nil {
+			return statusHandler.FlushAndReturn(secErr)
+		}
+	}
 
 	consoleServingCertSecret, servingCertErr := co.secretsLister.Secrets(api.TargetNamespace).Get(api.ConsoleServingCertName)
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("ConsoleServingCertSecretGet", "FailedGet", servingCertErr))
@@ -381,12 +457,16 @@ func (co *consoleOperator) SyncConfigMap(
 	infrastructureConfig *configv1.Infrastructure,
 	oauthConfig *configv1.OAuth,
 	authConfig *configv1.Authentication,
+	servedConsoleConfig *consoleserver.Config,
+	oidcLogoutRedirect string,
 	activeConsoleRoute *routev1.Route,
 	recorder events.Recorder,
 	consoleHost string,
 	techPreviewEnabled bool,
 	olmLifecycleMetadataEnabled bool,
 	additionalHosts []string,
+	availablePlugins []*v1.ConsolePlugin,
+	pluginsOrder []string,
 ) (consoleConfigMap *corev1.ConfigMap, reason string, err error) {
 
 	managedConfig, mcErr := co.managedNSConfigMapLister.ConfigMaps(api.OpenShiftConfigManagedNamespace).Get(api.OpenShiftConsoleConfigMapName)
@@ -406,21 +486,17 @@ func (co *consoleOperator) SyncConfigMap(
 	inactivityTimeoutSeconds := 0
 	switch authConfig.Spec.Type {
 	case "", configv1.AuthenticationTypeIntegratedOAuth:
+		if servedConsoleConfig != nil {
+			// the served auth config is kept, the OAuthClient may not exist yet
+			break
+		}
 		oauthClient, oacErr := co.oauthClientLister.Get(oauthsub.Stub().Name)
 		if oacErr != nil {
 			return nil, "FailedGetOAuthClient", oacErr
 		}
-		if oauthClient.AccessTokenInactivityTimeoutSeconds != nil {
-			inactivityTimeoutSeconds = int(*oauthClient.AccessTokenInactivityTimeoutSeconds)
-		} else {
-			if oauthConfig.Spec.TokenConfig.AccessTokenInactivityTimeout != nil {
-				inactivityTimeoutSeconds = int(oauthConfig.Spec.TokenConfig.AccessTokenInactivityTimeout.Seconds())
-			}
-		}
+		inactivityTimeoutSeconds = oauthsub.GetInactivityTimeoutSeconds(oauthClient, oauthConfig)
 	}
 
-	availablePlugins := co.GetAvailablePlugins(operatorConfig.Spec.Plugins)
-
 	monitoringSharedConfig, mscErr := co.managedNSConfigMapLister.ConfigMaps(api.OpenShiftConfigManagedNamespace).Get(api.OpenShiftMonitoringConfigMapName)
 	if mscErr != nil {
 		if !apierrors.IsNotFound(mscErr) {
@@ -454,12 +530,14 @@ func (co *consoleOperator) SyncConfigMap(
 		operatorConfig,
 		consoleConfig,
 		authConfig,
+		oidcLogoutRedirect,
 		managedConfig,
 		monitoringSharedConfig,
 		infrastructureConfig,
 		activeConsoleRoute,
 		inactivityTimeoutSeconds,
 		availablePlugins,
+		pluginsOrder,
 		nodeArchitectures,
 		nodeOperatingSystems,
 		copiedCSVsDisabled,
@@ -474,6 +552,15 @@ func (co *consoleOperator) SyncConfigMap(
 	if err != nil {
 		return nil, "FailedConsoleConfigBuilder", err
 	}
+	if servedConsoleConfig != nil {
+		servedConsoleConfigMap, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleConfigMapName)
+		if err != nil {
+			return nil, "FailedGetConsoleConfig", err
+		}
+		if err := keepServedAuthConfig(defaultConfigmap, servedConsoleConfigMap); err != nil {
+			return nil, "FailedConsoleConfigBuilder", err
+		}
+	}
 	var cm *corev1.ConfigMap
 	var cmChanged bool
 	cmErr := controllersutil.RetryOnTransientError(func() error {
@@ -491,6 +578,50 @@ func (co *consoleOperator) SyncConfigMap(
 	return cm, "ConsoleConfigBuilder", cmErr
 }
 
+// GetOIDCLogoutRedirect derives the logout redirect of the console from the end_session_endpoint
+// of the OIDC provider, so that logging out of the console also ends the user's session
+// at the issuer. The issuer returns the user to the console, or to the post-logout redirect URI
+// set on the operator config, which has to be one of the console hosts. A logout redirect set
+// in the console config takes precedence. The discovery is the one the OIDCSetupController last
+// validated for the issuer, an unavailable issuer doesn't flap the console config.
+func (co *consoleOperator) GetOIDCLogoutRedirect(
+	operatorConfig *operatorv1.Console,
+	consoleConfig *configv1.Console,
+	oidcProvider *configv1.OIDCProvider,
+	oidcClientConfig *configv1.OIDCClientConfig,
+	consoleURL *url.URL,
+	additionalHosts []string,
+) (string, string, error) {
+	if len(consoleConfig.Spec.Authentication.LogoutRedirect) > 0 || oidcClientConfig == nil {
+		return "", "", nil
+	}
+
+	postLogoutRedirectURI := consoleURL.JoinPath("/").String()
+	if value, ok := operatorConfig.Annotations[api.OIDCPostLogoutRedirectAnnotation]; ok {
+		postLogoutRedirectURI = value
+	}
+	consoleHosts := append([]string{consoleURL.Hostname()}, additionalHosts...)
+	if err := authnsub.ValidatePostLogoutRedirectURI(postLogoutRedirectURI, consoleHosts); err != nil {
+		return "", "InvalidPostLogoutRedirectURI", err
+	}
+
+	discoveryConfigMap, err := co.operatorNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleOperatorNamespace).Get(api.OIDCDiscoveryConfigMapName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", "FailedGetDiscovery", err
+	}
+	discovery := authnsub.GetDiscovery(discoveryConfigMap, oidcProvider.Issuer.URL)
+	if discovery == nil {
+		// the issuer is not validated yet, the configmap update triggers another sync
+		return "", "", nil
+	}
+
+	logoutRedirect, err := authnsub.GetLogoutRedirect(discovery, oidcClientConfig.ClientID, postLogoutRedirectURI)
+	if err != nil {
+		return "", "InvalidEndSessionEndpoint", err
+	}
+	return logoutRedirect, "", nil
+}
+
 // Build telemetry configuration in following order:
 //  1. check if the telemetry client is available and set the "TELEMETER_CLIENT_DISABLED" annotation accordingly
 //  2. get telemetry annotation from console-operator config
@@ -844,6 +975,16 @@ func (co *consoleOperator) UpdateCustomLogoSyncSource(targetName string, unsync
 	return co.resourceSyncer.SyncConfigMap(target, source)
 }
 
+// GetEnabledPluginNames returns the plugins listed in the operator config and the plugins
+// enabled by the auto-enable policy.
+func (co *consoleOperator) GetEnabledPluginNames(operatorConfig *operatorv1.Console) ([]string, error) {
+	plugins, err := co.consolePluginLister.List(labels.Everything())
+	if err != nil {
+		return utilsub.RemoveDuplicateStr(operatorConfig.Spec.Plugins), err
+	}
+	return consoleplugin.GetEnabledPluginNames(operatorConfig, plugins)
+}
+
 func (co *consoleOperator) GetAvailablePlugins(enabledPluginsNames []string) []*v1.ConsolePlugin {
 	var availablePlugins []*v1.ConsolePlugin
 	for _, pluginName := range utilsub.RemoveDuplicateStr(enabledPluginsNames) {
@@ -861,6 +1002,122 @@ func (co *consoleOperator) GetAvailablePlugins(enabledPluginsNames []string) []*
 	return availablePlugins
 }
 
+// RemoveQuarantinedPlugins filters out the plugins quarantined by the plugin status controller.
+func (co *consoleOperator) RemoveQuarantinedPlugins(availablePlugins []*v1.ConsolePlugin) []*v1.ConsolePlugin {
+	quarantineConfigMap, err := co.operatorNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleOperatorNamespace).Get(api.PluginQuarantineConfigMapName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.Errorf("failed to get %s configmap: %v", api.PluginQuarantineConfigMapName, err)
+		}
+		return availablePlugins
+	}
+	return consoleplugin.RemoveQuarantinedPlugins(availablePlugins, consoleplugin.GetQuarantineRecords(quarantineConfigMap))
+}
+
+// pluginCSPDirectivesExcludedConditionPrefix prefixes the type of the per-plugin conditions
+// listing the CSP directives left out by the guardrails.
+const pluginCSPDirectivesExcludedConditionPrefix = "PluginCSPDirectivesExcluded-"
+
+// ApplyCSPGuardrails leaves the plugin CSP directives violating the guardrails configured by
+// the admin out of the console config. The plugins themselves stay enabled, an event naming
+// the dropped directives is emitted per restricted plugin whenever its violations change, and
+// the sync reports them through a per-plugin warning condition, as the console itself is not
+// affected. If the guardrails can't be parsed, the plugins are
+// returned as they are along with the error.
+func (co *consoleOperator) ApplyCSPGuardrails(operatorConfig *operatorv1.Console, availablePlugins []*v1.ConsolePlugin, recorder events.Recorder) ([]*v1.ConsolePlugin, string, error) {
+	guardrails, err := consoleplugin.GetCSPGuardrails(operatorConfig)
+	if err != nil {
+		co.trackables.cspViolations = nil
+		return availablePlugins, "InvalidGuardrails", err
+	}
+	if guardrails == nil {
+		co.trackables.cspViolations = nil
+		return availablePlugins, "", nil
+	}
+
+	restrictedPlugins, violations := guardrails.Apply(availablePlugins)
+	cspViolations := map[string]string{}
+	for _, plugin := range restrictedPlugins {
+		violation, ok := violations[plugin.Name]
+		if !ok {
+			if _, restricted := co.trackables.cspViolations[plugin.Name]; restricted {
+				recorder.Eventf("PluginCSPDirectivesRestored", "CSP directives of %q plugin no longer restricted by the CSP guardrails", plugin.Name)
+			}
+			continue
+		}
+		cspViolations[plugin.Name] = violation.Error()
+		if co.trackables.cspViolations[plugin.Name] != violation.Error() {
+			recorder.Warningf("PluginCSPDirectivesExcluded", "%v", violation)
+		}
+	}
+	co.trackables.cspViolations = cspViolations
+	return restrictedPlugins, "", nil
+}
+
+// GetCompatiblePlugins filters out the plugins the plugin status controller found
+// incompatible with the running release, based on the manifests served by their backends.
+// Newly enabled plugins are held back until their verdict is recorded, for at most
+// pluginCompatibilityCheckTimeout, and returned as pending. Plugins the console already serves
+// keep being served until then, so the console config doesn't flap when the verdicts are not
+// recorded yet, e.g. right after an upgrade.
+func (co *consoleOperator) GetCompatiblePlugins(availablePlugins []*v1.ConsolePlugin) ([]*v1.ConsolePlugin, []string, string, error) {
+	compatibilityConfigMap, err := co.operatorNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleOperatorNamespace).Get(api.PluginCompatibilityConfigMapName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return availablePlugins, nil, "FailedGetCompatibility", fmt.Errorf("failed to get %s configmap: %w", api.PluginCompatibilityConfigMapName, err)
+		}
+		compatibilityConfigMap = nil
+	}
+	verdicts := consoleplugin.GetCompatibilityVerdicts(compatibilityConfigMap)
+	servedPluginNames := co.getServedPluginNames()
+
+	now := time.Now()
+	pendingPluginsSince := map[string]time.Time{}
+	compatiblePlugins := []*v1.ConsolePlugin{}
+	pendingPluginNames := []string{}
+	messages := []string{}
+	for _, plugin := range availablePlugins {
+		message, checked := verdicts[plugin.Name]
+		if !checked && !servedPluginNames.Has(plugin.Name) {
+			since, ok := co.trackables.pendingPluginsSince[plugin.Name]
+			if !ok {
+				since = now
+			}
+			pendingPluginsSince[plugin.Name] = since
+			if now.Sub(since) < pluginCompatibilityCheckTimeout {
+				pendingPluginNames = append(pendingPluginNames, plugin.Name)
+				continue
+			}
+			klog.V(2).Infof("plugin %q has no compatibility verdict after %s, it is no longer held back", plugin.Name, pluginCompatibilityCheckTimeout)
+		}
+		switch {
+		case len(message) != 0:
+			messages = append(messages, message)
+		default:
+			compatiblePlugins = append(compatiblePlugins, plugin)
+		}
+	}
+	co.trackables.pendingPluginsSince = pendingPluginsSince
+	if len(messages) == 0 {
+		return compatiblePlugins, pendingPluginNames, "", nil
+	}
+	return compatiblePlugins, pendingPluginNames, "IncompatiblePlugins", fmt.Errorf("%d plugin(s) excluded from the console config: %s", len(messages), strings.Join(messages, "; "))
+}
+
+// getServedPluginNames returns the plugins in the console config the console currently serves.
+func (co *consoleOperator) getServedPluginNames() sets.Set[string] {
+	consoleConfigMap, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleConfigMapName)
+	if err != nil {
+		return sets.New[string]()
+	}
+	consoleConfig, err := configmapsub.ReadConsoleConfig(consoleConfigMap)
+	if err != nil {
+		klog.V(4).Infof("failed to read the served console config: %v", err)
+		return sets.New[string]()
+	}
+	return sets.KeySet(consoleConfig.Plugins)
+}
+
 // checkDeploymentRolloutStatus checks whether a deployment's rollout has completed
 // by examining the deployment controller's own status conditions. This follows
 // the 3CMO pattern (openshift/cluster-cloud-controller-manager-operator#488).
@@ -921,6 +1178,163 @@ func (co *consoleOperator) isCopiedCSVsDisabled(ctx context.Context) (bool, erro
 	return copiedCSVsDisabled, nil
 }
 
+// CheckAuthTransition runs the preflight checks of a switch of the authentication type before the
+// console config and deployment switch to it. The controllers preparing the new type react to the
+// switch at the same time, so the console keeps serving the previous type until the client config,
+// the client secret and the CAs of the new type are in place, rather than locking everyone out
+// with a half-configured provider. While the switch is held back, the served console config is
+// returned with the error. Without a console config there is no previous type to keep serving and
+// the switch is not checked.
+func (co *consoleOperator) CheckAuthTransition(
+	ctx context.Context,
+	authnConfig *configv1.Authentication,
+	recorder events.Recorder,
+) (*consoleserver.Config, string, error) {
+	consoleConfigMap, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleConfigMapName)
+	if apierrors.IsNotFound(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "FailedGetConsoleConfig", err
+	}
+	consoleConfig, err := configmapsub.ReadConsoleConfig(consoleConfigMap)
+	if err != nil {
+		return nil, "InvalidConsoleConfig", err
+	}
+
+	// the console serves the "openshift" auth type for every type but OIDC
+	servedAuthType := consoleConfig.Auth.AuthType
+	requestedOIDC := authnConfig.Spec.Type == configv1.AuthenticationTypeOIDC
+	if len(servedAuthType) == 0 || (servedAuthType != "openshift") == requestedOIDC {
+		return nil, "", nil
+	}
+
+	requestedAuthType := authnConfig.Spec.Type
+	if len(requestedAuthType) == 0 {
+		requestedAuthType = configv1.AuthenticationTypeIntegratedOAuth
+	}
+	var reason string
+	if requestedOIDC {
+		reason, err = co.oidcTransitionPreflight(authnConfig)
+	} else {
+		reason, err = co.oauthTransitionPreflight(ctx)
+	}
+	if err != nil {
+		return consoleConfig, reason, fmt.Errorf("the console keeps serving the %q auth type until the switch to %s is complete: %w", servedAuthType, requestedAuthType, err)
+	}
+
+	recorder.Eventf("AuthenticationTypeSwitched", "The console switches from the %q auth type to %s", servedAuthType, requestedAuthType)
+	return nil, "", nil
+}
+
+func (co *consoleOperator) oidcTransitionPreflight(authnConfig *configv1.Authentication) (string, error) {
+	oidcProvider, oidcClientConfig := authnsub.GetOIDCClientConfig(authnConfig, api.TargetNamespace, api.OpenShiftConsoleName)
+	if oidcClientConfig == nil {
+		return "MissingClientConfig", fmt.Errorf("the OIDC provider doesn't configure a client for the console")
+	}
+
+	clientSecret, err := co.secretsLister.Secrets(api.TargetNamespace).Get(secretsub.Stub().Name)
+	if err != nil {
+		return "MissingClientSecret", err
+	}
+	if len(secretsub.GetSecretStringForAuthType(clientSecret, configv1.AuthenticationTypeOIDC)) == 0 {
+		return "MissingClientSecret", fmt.Errorf("the client secret of the OIDC provider %q is not synced yet", oidcProvider.Name)
+	}
+
+	if name := oidcProvider.Issuer.CertificateAuthority.Name; len(name) > 0 {
+		caConfigMap, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(name)
+		if err != nil {
+			return "MissingCA", err
+		}
+		if len(caConfigMap.Data[api.AuthServerCAFileName]) == 0 {
+			return "MissingCA", fmt.Errorf("%s configmap is missing %s data", name, api.AuthServerCAFileName)
+		}
+	}
+	return "", nil
+}
+
+func (co *consoleOperator) oauthTransitionPreflight(ctx context.Context) (string, error) {
+	oauthClient, err := co.oauthClientLister.Get(oauthsub.Stub().Name)
+	if err != nil {
+		return "MissingOAuthClient", err
+	}
+
+	clientSecret, err := co.secretsLister.Secrets(api.TargetNamespace).Get(secretsub.Stub().Name)
+	if err != nil {
+		return "MissingClientSecret", err
+	}
+	secretString := secretsub.GetSecretStringForAuthType(clientSecret, configv1.AuthenticationTypeIntegratedOAuth)
+	if len(secretString) == 0 {
+		return "MissingClientSecret", fmt.Errorf("the client secret of the console OAuthClient is not synced yet")
+	}
+	if oauthsub.GetSecretString(oauthClient) != secretString {
+		return "UnregisteredClientSecret", fmt.Errorf("the client secret is not registered with the console OAuthClient yet")
+	}
+
+	if _, reason, err := co.ValidateOAuthServingCertConfigMap(ctx); err != nil {
+		return reason, err
+	}
+	return "", nil
+}
+
+// getServedOIDCResources returns the CA configmap of the OIDC provider and the session secret the
+// console deployment mounts, while a switch away from OIDC is held back.
+func (co *consoleOperator) getServedOIDCResources() (*corev1.ConfigMap, *corev1.Secret, error) {
+	deployment, err := co.deploymentLister.Deployments(api.TargetNamespace).Get(api.OpenShiftConsoleDeploymentName)
+	if err != nil {
+		return nil, nil, err
+	}
+	var authServerCA *corev1.ConfigMap
+	if name := deploymentsub.GetAuthServerCAConfigMapName(deployment); len(name) > 0 {
+		authServerCA, err = co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(name)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	sessionSecret, err := co.secretsLister.Secrets(api.TargetNamespace).Get(api.SessionSecretName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, nil, err
+	}
+	return authServerCA, sessionSecret, nil
+}
+
+// promotePendingClientSecret switches the client secret over to the auth type the console config
+// switches to, the client secret controller stages it until then.
+func (co *consoleOperator) promotePendingClientSecret(ctx context.Context, clientSecret *corev1.Secret, recorder events.Recorder) (*corev1.Secret, error) {
+	required := clientSecret.DeepCopy()
+	if !secretsub.PromotePendingSecretString(required) {
+		return clientSecret, nil
+	}
+	var secret *corev1.Secret
+	err := controllersutil.RetryOnTransientError(func() error {
+		var e error
+		secret, _, e = resourceapply.ApplySecret(ctx, co.secretsClient, recorder, required)
+		return e
+	})
+	return secret, err
+}
+
+// keepServedAuthConfig replaces the auth and session config of the console config with the served
+// ones. Only these keys are patched, the rest of the merged config is kept as it is.
+func keepServedAuthConfig(consoleConfigMap *corev1.ConfigMap, servedConsoleConfigMap *corev1.ConfigMap) error {
+	consoleConfig, err := configmapsub.ReadConsoleConfigFields(consoleConfigMap)
+	if err != nil {
+		return err
+	}
+	servedConsoleConfig, err := configmapsub.ReadConsoleConfigFields(servedConsoleConfigMap)
+	if err != nil {
+		return err
+	}
+	for _, key := range []string{"auth", "session"} {
+		if value, ok := servedConsoleConfig[key]; ok {
+			consoleConfig[key] = value
+		} else {
+			delete(consoleConfig, key)
+		}
+	}
+	return configmapsub.WriteConsoleConfigFields(consoleConfigMap, consoleConfig)
+}
+
 func (co *consoleOperator) syncSessionSecret(
 	ctx context.Context,
 	operatorConfig *operatorv1.Console,
@@ -11,8 +11,10 @@ import (
 	"github.com/go-test/deep"
 
 	configv1 "github.com/openshift/api/config/v1"
+	consolev1 "github.com/openshift/api/console/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -20,6 +22,7 @@ import (
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
 
 	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/console-operator/pkg/console/telemetry"
@@ -844,3 +847,179 @@ func TestGetTLSConfigFromObservedConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckAuthTransition(t *testing.T) {
+	consoleConfigMap := func(authType string) *v1.ConfigMap {
+		return &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: api.OpenShiftConsoleConfigMapName, Namespace: api.TargetNamespace},
+			Data:       map[string]string{"console-config.yaml": "auth:\n  authType: " + authType + "\n"},
+		}
+	}
+	oauthSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "console-oauth-config",
+			Namespace:   api.TargetNamespace,
+			Annotations: map[string]string{"console.openshift.io/authentication-type": string(configv1.AuthenticationTypeIntegratedOAuth)},
+		},
+		Data: map[string][]byte{"clientSecret": []byte("generated")},
+	}
+	stagedOIDCSecret := oauthSecret.DeepCopy()
+	stagedOIDCSecret.Annotations["console.openshift.io/pending-authentication-type"] = string(configv1.AuthenticationTypeOIDC)
+	stagedOIDCSecret.Data["pendingClientSecret"] = []byte("oidc")
+	oidcConfig := &configv1.Authentication{
+		Spec: configv1.AuthenticationSpec{
+			Type: configv1.AuthenticationTypeOIDC,
+			OIDCProviders: []configv1.OIDCProvider{{
+				Name:   "idp",
+				Issuer: configv1.TokenIssuer{URL: "https://idp.example.com"},
+				OIDCClients: []configv1.OIDCClientConfig{{
+					ComponentNamespace: api.TargetNamespace,
+					ComponentName:      api.OpenShiftConsoleName,
+					ClientID:           "console",
+				}},
+			}},
+		},
+	}
+	oidcConfigWithoutClients := &configv1.Authentication{
+		Spec: configv1.AuthenticationSpec{Type: configv1.AuthenticationTypeOIDC},
+	}
+
+	tests := []struct {
+		name          string
+		configMap     *v1.ConfigMap
+		secret        *v1.Secret
+		authnConfig   *configv1.Authentication
+		wantReason    string
+		wantErrSubstr string
+	}{
+		{
+			name:        "No console config yet",
+			authnConfig: oidcConfigWithoutClients,
+		},
+		{
+			name:        "No transition",
+			configMap:   consoleConfigMap("openshift"),
+			authnConfig: &configv1.Authentication{},
+		},
+		{
+			name:        "No transition between OIDC configs",
+			configMap:   consoleConfigMap("oidc"),
+			authnConfig: oidcConfigWithoutClients,
+		},
+		{
+			name:          "Switch to OIDC without a console client",
+			configMap:     consoleConfigMap("openshift"),
+			authnConfig:   oidcConfigWithoutClients,
+			wantReason:    "MissingClientConfig",
+			wantErrSubstr: `keeps serving the "openshift" auth type`,
+		},
+		{
+			name:          "Switch to OIDC before the client secret is synced",
+			configMap:     consoleConfigMap("openshift"),
+			authnConfig:   oidcConfig,
+			wantReason:    "MissingClientSecret",
+			wantErrSubstr: "not synced yet",
+		},
+		{
+			name:        "Switch to OIDC with the staged client secret",
+			configMap:   consoleConfigMap("openshift"),
+			secret:      stagedOIDCSecret,
+			authnConfig: oidcConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmIndexer := newIndexer(cache.MetaNamespaceKeyFunc)
+			if tt.configMap != nil {
+				if err := cmIndexer.Add(tt.configMap); err != nil {
+					t.Fatal(err)
+				}
+			}
+			secret := oauthSecret
+			if tt.secret != nil {
+				secret = tt.secret
+			}
+			secretIndexer := newIndexer(cache.MetaNamespaceKeyFunc)
+			if err := secretIndexer.Add(secret); err != nil {
+				t.Fatal(err)
+			}
+			co := &consoleOperator{
+				targetNSConfigMapLister: corev1listers.NewConfigMapLister(cmIndexer),
+				secretsLister:           corev1listers.NewSecretLister(secretIndexer),
+			}
+
+			recorder := events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(time.Now()))
+			servedConsoleConfig, reason, err := co.CheckAuthTransition(context.TODO(), tt.authnConfig, recorder)
+			if diff := deep.Equal(reason, tt.wantReason); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != (len(tt.wantErrSubstr) > 0) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantErrSubstr) {
+				t.Errorf("error %q does not contain %q", err.Error(), tt.wantErrSubstr)
+			}
+			// the served console config is only returned while the switch is held back
+			if (servedConsoleConfig != nil) != (err != nil) {
+				t.Errorf("unexpected served console config %v for error %v", servedConsoleConfig, err)
+			}
+		})
+	}
+}
+
+func TestGetCompatiblePlugins(t *testing.T) {
+	plugin := func(name string) *consolev1.ConsolePlugin {
+		return &consolev1.ConsolePlugin{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	compatibilityConfigMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: api.PluginCompatibilityConfigMapName, Namespace: api.OpenShiftConsoleOperatorNamespace},
+		Data: map[string]string{
+			"compatible":   "",
+			"incompatible": `"incompatible" plugin requires console ~4.15.0, running 4.16.0`,
+		},
+	}
+	consoleConfigMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: api.OpenShiftConsoleConfigMapName, Namespace: api.TargetNamespace},
+		Data:       map[string]string{"console-config.yaml": "plugins:\n  served: https://served.example.com/\n"},
+	}
+
+	operatorNSIndexer := newIndexer(cache.MetaNamespaceKeyFunc)
+	if err := operatorNSIndexer.Add(compatibilityConfigMap); err != nil {
+		t.Fatal(err)
+	}
+	targetNSIndexer := newIndexer(cache.MetaNamespaceKeyFunc)
+	if err := targetNSIndexer.Add(consoleConfigMap); err != nil {
+		t.Fatal(err)
+	}
+	co := &consoleOperator{
+		operatorNSConfigMapLister: corev1listers.NewConfigMapLister(operatorNSIndexer),
+		targetNSConfigMapLister:   corev1listers.NewConfigMapLister(targetNSIndexer),
+	}
+
+	compatiblePlugins, pendingPluginNames, reason, err := co.GetCompatiblePlugins([]*consolev1.ConsolePlugin{
+		plugin("compatible"), plugin("incompatible"), plugin("served"), plugin("new"),
+	})
+	compatiblePluginNames := []string{}
+	for _, plugin := range compatiblePlugins {
+		compatiblePluginNames = append(compatiblePluginNames, plugin.Name)
+	}
+	if diff := deep.Equal(compatiblePluginNames, []string{"compatible", "served"}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(pendingPluginNames, []string{"new"}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(reason, "IncompatiblePlugins"); diff != nil {
+		t.Error(diff)
+	}
+	if err == nil || !strings.Contains(err.Error(), "1 plugin(s) excluded") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// a plugin without a verdict is only held back for a bounded time
+	co.trackables.pendingPluginsSince["new"] = time.Now().Add(-pluginCompatibilityCheckTimeout)
+	compatiblePlugins, pendingPluginNames, _, _ = co.GetCompatiblePlugins([]*consolev1.ConsolePlugin{plugin("new")})
+	if len(compatiblePlugins) != 1 || len(pendingPluginNames) != 0 {
+		t.Errorf("expected the plugin to be released after %s, got compatible %v and pending %v", pluginCompatibilityCheckTimeout, compatiblePlugins, pendingPluginNames)
+	}
+}
@@ -13,8 +13,10 @@ import (
 	apiexensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	policyv1client "k8s.io/client-go/kubernetes/typed/policy/v1"
@@ -28,14 +30,19 @@ import (
 	"github.com/openshift/console-operator/pkg/api"
 
 	"github.com/openshift/console-operator/pkg/console/configobservation/configobservercontroller"
+	"github.com/openshift/console-operator/pkg/console/controllers/breakglass"
 	"github.com/openshift/console-operator/pkg/console/controllers/clidownloads"
 	"github.com/openshift/console-operator/pkg/console/controllers/clioidcclientstatus"
 	"github.com/openshift/console-operator/pkg/console/controllers/downloadsdeployment"
+	"github.com/openshift/console-operator/pkg/console/controllers/exposure"
 	"github.com/openshift/console-operator/pkg/console/controllers/healthcheck"
+	"github.com/openshift/console-operator/pkg/console/controllers/impersonation"
 	"github.com/openshift/console-operator/pkg/console/controllers/migration"
 	"github.com/openshift/console-operator/pkg/console/controllers/oauthclients"
 	"github.com/openshift/console-operator/pkg/console/controllers/oauthclientsecret"
 	"github.com/openshift/console-operator/pkg/console/controllers/oidcsetup"
+	"github.com/openshift/console-operator/pkg/console/controllers/pluginnetworkpolicy"
+	"github.com/openshift/console-operator/pkg/console/controllers/pluginstatus"
 	pdb "github.com/openshift/console-operator/pkg/console/controllers/poddisruptionbudget"
 	"github.com/openshift/console-operator/pkg/console/controllers/route"
 	"github.com/openshift/console-operator/pkg/console/controllers/service"
@@ -43,6 +50,7 @@ import (
 	"github.com/openshift/console-operator/pkg/console/controllers/storageversionmigration"
 	upgradenotification "github.com/openshift/console-operator/pkg/console/controllers/upgradenotification"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/debug"
 	"github.com/openshift/library-go/pkg/controller/controllercmd"
 	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
 	"github.com/openshift/library-go/pkg/operator/genericoperatorclient"
@@ -158,6 +166,24 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		informers.WithNamespace(telemetry.TelemeterClientDeploymentNamespace),
 	)
 
+	// the console namespace alone, its labels are matched against the namespace selector of the
+	// IngressController shard
+	kubeInformersConsoleNamespace := informers.NewSharedInformerFactoryWithOptions(
+		kubeClient,
+		resync,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", api.OpenShiftConsoleNamespace).String()
+		}),
+	)
+
+	// HTTPRoutes, BackendTLSPolicies and cert-manager Certificates of the console
+	dynamicInformersNamespaced := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+		dynamicClient,
+		resync,
+		api.OpenShiftConsoleNamespace,
+		nil,
+	)
+
 	//configs are all named "cluster", but our clusteroperator is named "console"
 	configInformers := configinformers.NewSharedInformerFactoryWithOptions(
 		configClient,
@@ -261,6 +287,8 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		// routes
 		routesClient.RouteV1(),
 		routesInformersNamespaced.Route().V1().Routes(), // Route
+		operatorConfigInformers.Operator().V1().IngressControllers(),
+		kubeInformersConsoleNamespace.Core().V1().Namespaces(), // shard namespace selector
 		// plugins
 		consoleInformers.Console().V1().ConsolePlugins(),
 		// openshift
@@ -289,6 +317,8 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		routesInformersNamespaced.Route().V1().Routes(),
 		configInformers.Config().V1().Ingresses(),
 		kubeInformersNamespaced.Core().V1().Secrets(),
+		operatorConfigInformers.Operator().V1().IngressControllers(),
+		kubeInformersConsoleNamespace.Core().V1().Namespaces(), // shard namespace selector
 		oauthClientsSwitchedInformer,
 		recorder,
 	)
@@ -299,7 +329,9 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		configInformers.Config().V1().Authentications(),
 		operatorConfigInformers.Operator().V1().Consoles(),
 		kubeInformersConfigNamespaced.Core().V1().Secrets(),
+		kubeInformersConfigNamespaced.Core().V1().ConfigMaps(),
 		kubeInformersNamespaced.Core().V1().Secrets(),
+		kubeInformersNamespaced.Apps().V1().Deployments(),
 		recorder,
 	)
 
@@ -323,10 +355,40 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		configInformers.Config().V1().Authentications(),
 		configClient.ConfigV1().Authentications(),
 		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersConfigNamespaced.Core().V1().ConfigMaps(),
 		externalOIDCEnabled,
 		recorder,
 	)
 
+	breakGlassController := breakglass.NewBreakGlassController(
+		// clients
+		operatorClient,
+		kubeClient.CoreV1(),
+		kubeClient.NetworkingV1(),
+		kubeClient.AppsV1(),
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		configInformers.Config().V1().Authentications(),
+		kubeInformersNamespaced.Core().V1().ConfigMaps(),
+		kubeInformersNamespaced.Core().V1().Services(),
+		kubeInformersNamespaced.Networking().V1().NetworkPolicies(),
+		kubeInformersNamespaced.Apps().V1().Deployments(),
+		externalOIDCEnabled,
+		// events
+		recorder,
+	)
+
+	impersonationController := impersonation.NewImpersonationController(
+		// clients
+		operatorClient,
+		dynamicClient, // user.openshift.io groups
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		configInformers.Config().V1().Authentications(),
+		// events
+		recorder,
+	)
+
 	consoleServiceAccountController := serviceaccounts.NewServiceAccountSyncController(
 		// clients
 		operatorClient,
@@ -382,6 +444,8 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		configInformers, // Config
 		consoleInformers.Console().V1().ConsoleCLIDownloads(), // ConsoleCliDownloads
 		routesInformersNamespaced.Route().V1().Routes(),       // Routes
+		operatorConfigInformers.Operator().V1().IngressControllers(),
+		kubeInformersConsoleNamespace.Core().V1().Namespaces(), // shard namespace selector
 		// events
 		recorder,
 	)
@@ -397,6 +461,8 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		// informers
 		operatorConfigInformers.Operator().V1().Consoles(), // OperatorConfig
 		kubeInformersNamespaced.Core().V1().Services(),     // Services
+		operatorConfigInformers.Operator().V1().IngressControllers(),
+		kubeInformersConsoleNamespace.Core().V1().Namespaces(), // shard namespace selector
 		// events
 		recorder,
 	)
@@ -412,6 +478,8 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		// informers
 		operatorConfigInformers.Operator().V1().Consoles(), // OperatorConfig
 		kubeInformersNamespaced.Core().V1().Services(),     // Services
+		operatorConfigInformers.Operator().V1().IngressControllers(),
+		kubeInformersConsoleNamespace.Core().V1().Namespaces(), // shard namespace selector
 		// events
 		recorder,
 	)
@@ -425,11 +493,15 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		// clients
 		operatorClient,
 		routesClient.RouteV1(),
+		dynamicClient, // cert-manager resource discovery
 		// route
 		operatorConfigInformers.Operator().V1().Consoles(),
 		operatorConfigInformers.Operator().V1().IngressControllers(),
-		kubeInformersConfigNamespaced.Core().V1().Secrets(), // `openshift-config` namespace informers
+		kubeInformersConsoleNamespace.Core().V1().Namespaces(), // shard namespace selector
+		kubeInformersConfigNamespaced.Core().V1().Secrets(),    // `openshift-config` namespace informers
+		kubeInformersNamespaced.Core().V1().Secrets(),          // `openshift-console` namespace informers
 		routesInformersNamespaced.Route().V1().Routes(),
+		dynamicInformersNamespaced, // cert-manager certificates
 		// events
 		recorder,
 	)
@@ -443,11 +515,63 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		// clients
 		operatorClient,
 		routesClient.RouteV1(),
+		dynamicClient, // cert-manager resource discovery
 		// route
 		operatorConfigInformers.Operator().V1().Consoles(),
 		operatorConfigInformers.Operator().V1().IngressControllers(),
-		kubeInformersConfigNamespaced.Core().V1().Secrets(), // `openshift-config` namespace informers
+		kubeInformersConsoleNamespace.Core().V1().Namespaces(), // shard namespace selector
+		kubeInformersConfigNamespaced.Core().V1().Secrets(),    // `openshift-config` namespace informers
+		kubeInformersNamespaced.Core().V1().Secrets(),          // `openshift-console` namespace informers
 		routesInformersNamespaced.Route().V1().Routes(),
+		dynamicInformersNamespaced, // cert-manager certificates
+		// events
+		recorder,
+	)
+
+	consoleExposureController := exposure.NewExposureSyncController(
+		api.OpenShiftConsoleRouteName,
+		// top level config
+		configInformers,
+		// clients
+		operatorClient,
+		kubeClient.NetworkingV1(),
+		kubeClient.CoreV1(),
+		kubeClient.CoreV1(),
+		dynamicClient, // HTTPRoutes
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersNamespaced.Networking().V1().Ingresses(),
+		kubeInformersNamespaced.Networking().V1().IngressClasses(),
+		kubeInformersConfigNamespaced.Core().V1().Secrets(), // `openshift-config` namespace informers
+		kubeInformersNamespaced.Core().V1().Secrets(),       // `openshift-console` namespace informers
+		kubeInformersNamespaced.Core().V1().ConfigMaps(),
+		operatorConfigInformers.Operator().V1().IngressControllers(),
+		kubeInformersConsoleNamespace.Core().V1().Namespaces(), // shard namespace selector
+		dynamicInformersNamespaced,
+		// events
+		recorder,
+	)
+
+	downloadsExposureController := exposure.NewExposureSyncController(
+		api.OpenShiftConsoleDownloadsRouteName,
+		// top level config
+		configInformers,
+		// clients
+		operatorClient,
+		kubeClient.NetworkingV1(),
+		kubeClient.CoreV1(),
+		kubeClient.CoreV1(),
+		dynamicClient, // HTTPRoutes
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersNamespaced.Networking().V1().Ingresses(),
+		kubeInformersNamespaced.Networking().V1().IngressClasses(),
+		kubeInformersConfigNamespaced.Core().V1().Secrets(), // `openshift-config` namespace informers
+		kubeInformersNamespaced.Core().V1().Secrets(),       // `openshift-console` namespace informers
+		kubeInformersNamespaced.Core().V1().ConfigMaps(),
+		operatorConfigInformers.Operator().V1().IngressControllers(),
+		kubeInformersConsoleNamespace.Core().V1().Namespaces(), // shard namespace selector
+		dynamicInformersNamespaced,
 		// events
 		recorder,
 	)
@@ -457,11 +581,62 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		configClient.ConfigV1(),
 		// clients
 		operatorClient,
+		kubeClient.CoreV1(), // shard default certificates in `openshift-ingress`
 		// route
 		operatorConfigInformers.Operator().V1().Consoles(),
-		configInformers,                     // Config
-		kubeInformersNamespaced.Core().V1(), // `openshift-console` namespace informers
+		configInformers,                                     // Config
+		kubeInformersNamespaced.Core().V1(),                 // `openshift-console` namespace informers
+		kubeInformersConfigNamespaced.Core().V1().Secrets(), // `openshift-config` namespace informers
+		routesInformersNamespaced.Route().V1().Routes(),
+		operatorConfigInformers.Operator().V1().IngressControllers(),
+		kubeInformersConsoleNamespace.Core().V1().Namespaces(), // shard namespace selector
+		// events
+		recorder,
+	)
+
+	downloadsHealthCheckController := healthcheck.NewDownloadsHealthCheckController(
+		// clients
+		operatorClient,
+		kubeClient.CoreV1(), // shard default certificates in `openshift-ingress`
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		configInformers,                                     // Config
+		kubeInformersNamespaced.Core().V1(),                 // `openshift-console` namespace informers
+		kubeInformersConfigNamespaced.Core().V1().Secrets(), // `openshift-config` namespace informers
 		routesInformersNamespaced.Route().V1().Routes(),
+		consoleInformers.Console().V1().ConsoleCLIDownloads(),
+		operatorConfigInformers.Operator().V1().IngressControllers(),
+		kubeInformersConsoleNamespace.Core().V1().Namespaces(), // shard namespace selector
+		// events
+		recorder,
+	)
+
+	// the plugins can be deployed to any namespace, their services are read instead of watched
+	pluginServices := util.NewPluginServices(kubeClient.CoreV1(), kubeClient.DiscoveryV1())
+
+	pluginStatusController := pluginstatus.NewPluginStatusController(
+		// clients
+		operatorClient,
+		kubeClient.CoreV1(), // plugin quarantine configmap
+		pluginServices,
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		consoleInformers.Console().V1().ConsolePlugins(),
+		kubeInformersNamespaced.Core().V1().ConfigMaps(),               // `openshift-console` namespace informers
+		kubeInformersOperatorConfigNamespaced.Core().V1().ConfigMaps(), // `openshift-console-operator` namespace informers
+		// events
+		recorder,
+	)
+
+	pluginNetworkPolicyController := pluginnetworkpolicy.NewPluginNetworkPolicyController(
+		// clients
+		operatorClient,
+		kubeClient.NetworkingV1(),
+		pluginServices,
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		consoleInformers.Console().V1().ConsolePlugins(),
+		kubeInformersNamespaced.Networking().V1().NetworkPolicies(), // `openshift-console` namespace informers
 		// events
 		recorder,
 	)
@@ -594,6 +769,16 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		recorder,
 	)
 
+	pluginI18nMigrationController := migration.NewPluginI18nMigrationController(
+		// clients
+		consoleClient.ConsoleV1(),
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		consoleInformers.Console().V1().ConsolePlugins(),
+		// events
+		recorder,
+	)
+
 	// instantiate pdb client
 	policyClient, err := policyv1client.NewForConfig(controllerContext.KubeConfig)
 	if err != nil {
@@ -644,6 +829,14 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		recorder,
 	)
 
+	// serve the plugin inventory next to the metrics, the server is not set up without serving info
+	if controllerContext.Server != nil {
+		controllerContext.Server.Handler.NonGoRestfulMux.Handle(debug.PluginInventoryPath, debug.NewPluginInventoryHandler(
+			kubeInformersNamespaced.Core().V1().ConfigMaps().Lister(),
+			consoleInformers.Console().V1().ConsolePlugins().Lister(),
+		))
+	}
+
 	for _, informer := range []interface {
 		Start(stopCh <-chan struct{})
 	}{
@@ -653,12 +846,14 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		kubeInformersConfigNamespaced,
 		kubeInformersManagedNamespaced,
 		kubeInformersMonitoringNamespaced,
+		kubeInformersConsoleNamespace,
 		kubeInformersOperatorConfigNamespaced,
 		resourceSyncerInformers,
 		operatorConfigInformers,
 		consoleInformers,
 		routesInformersNamespaced,
 		dynamicInformers,
+		dynamicInformersNamespaced,
 		oauthClientsSwitchedInformer,
 	} {
 		informer.Start(ctx.Done())
@@ -668,6 +863,7 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		Run(ctx context.Context, workers int)
 	}{
 		migrationCleanupController,
+		pluginI18nMigrationController,
 		resourceSyncer,
 		clusterOperatorStatus,
 		logLevelController,
@@ -680,16 +876,23 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		consoleRouteController,
 		downloadsServiceController,
 		downloadsRouteController,
+		consoleExposureController,
+		downloadsExposureController,
 		consoleOperator,
 		cliDownloadsController,
 		downloadsDeploymentController,
 		consoleRouteHealthCheckController,
+		downloadsHealthCheckController,
+		pluginStatusController,
+		pluginNetworkPolicyController,
 		consolePDBController,
 		downloadsPDBController,
 		oauthClientController,
 		oauthClientSecretController,
 		oidcSetupController,
 		cliOIDCClientStatusController,
+		breakGlassController,
+		impersonationController,
 		upgradeNotificationController,
 		staleConditionsController,
 		storageversionmigrationController,
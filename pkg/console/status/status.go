@@ -76,6 +76,44 @@ func HandleUpgradable(typePrefix string, reason string, err error) ConditionUpda
 	}
 }
 
+// HandleInformational reports a condition without a Degraded, Progressing, Available or
+// Upgradeable suffix, which is not aggregated into the ClusterOperator conditions. It
+// exposes state which doesn't fit into the other conditions, e.g. the current config
+// of a resource the operator doesn't own.
+func HandleInformational(conditionType string, reason string, message string) ConditionUpdate {
+	condition := operatorsv1.OperatorCondition{
+		Type:    conditionType,
+		Status:  operatorsv1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	}
+	return ConditionUpdate{
+		ConditionType:  conditionType,
+		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+	}
+}
+
+// HandleWarning reports a problem which doesn't affect the availability of the console, e.g.
+// a misconfigured plugin, through a condition without a Degraded, Progressing, Available or
+// Upgradeable suffix, which is not aggregated into the ClusterOperator conditions. The
+// condition is True while the problem persists and False once it is gone.
+func HandleWarning(conditionType string, reason string, err error) ConditionUpdate {
+	condition := operatorsv1.OperatorCondition{
+		Type:   conditionType,
+		Status: operatorsv1.ConditionFalse,
+	}
+	if err != nil {
+		klog.Errorln(conditionType, reason, err.Error())
+		condition.Status = operatorsv1.ConditionTrue
+		condition.Reason = reason
+		condition.Message = err.Error()
+	}
+	return ConditionUpdate{
+		ConditionType:  conditionType,
+		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+	}
+}
+
 func (c *StatusHandler) ResetConditions(conditions []operatorsv1.OperatorCondition) []ConditionUpdate {
 	updateStatusFuncs := []ConditionUpdate{}
 	for _, condition := range conditions {
@@ -179,6 +217,24 @@ func (c *StatusHandler) AddConditions(conditionUpdates []ConditionUpdate) {
 	}
 }
 
+// RemoveStaleConditions removes the conditions whose type starts with the prefix and which
+// weren't added to the handler, e.g. the per-plugin conditions of plugins which are gone.
+func (c *StatusHandler) RemoveStaleConditions(conditionTypePrefix string) {
+	removeFunc := func(oldStatus *operatorsv1.OperatorStatus) error {
+		staleConditionTypes := []string{}
+		for _, condition := range oldStatus.Conditions {
+			if _, ok := c.conditionUpdates[condition.Type]; !ok && strings.HasPrefix(condition.Type, conditionTypePrefix) {
+				staleConditionTypes = append(staleConditionTypes, condition.Type)
+			}
+		}
+		for _, conditionType := range staleConditionTypes {
+			v1helpers.RemoveOperatorCondition(&oldStatus.Conditions, conditionType)
+		}
+		return nil
+	}
+	c.statusFuncs = append(c.statusFuncs, removeFunc)
+}
+
 func (c *StatusHandler) FlushAndReturn(returnErr error) error {
 	allStatusFns := []v1helpers.UpdateStatusFunc{}
 	for i := range c.statusFuncs {
@@ -0,0 +1,376 @@
+package authentication
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+	// discovery documents and key sets are small, don't read whatever the issuer returns
+	maxDiscoveryResponseSize = 1 << 20
+	// sent as the authorization code when probing the token endpoint, no issuer ever issued it
+	probeAuthorizationCode = "console-operator-client-credentials-probe"
+)
+
+// ProviderDiscovery holds the fields of the issuer's discovery document the console relies on.
+type ProviderDiscovery struct {
+	Issuer          string   `json:"issuer"`
+	JWKSURI         string   `json:"jwks_uri"`
+	TokenEndpoint   string   `json:"token_endpoint,omitempty"`
+	ScopesSupported []string `json:"scopes_supported,omitempty"`
+	// EndSessionEndpoint is only served by issuers supporting RP-initiated logout
+	EndSessionEndpoint            string   `json:"end_session_endpoint,omitempty"`
+	ResponseTypesSupported        []string `json:"response_types_supported,omitempty"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported,omitempty"`
+	// TokenEndpointAuthMethodsSupported defaults to client_secret_basic if the issuer doesn't list them
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+}
+
+// tokenErrorResponse is the error response of the token endpoint, RFC 6749 section 5.2.
+type tokenErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	Use     string `json:"use,omitempty"`
+}
+
+// NewIssuerClient returns a client trusting the CA bundle the provider is configured with, the
+// system trust store is used if the provider doesn't configure a CA, the same way the console does.
+func NewIssuerClient(caBundle string) (*http.Client, error) {
+	var caPool *x509.CertPool
+	if len(caBundle) > 0 {
+		caPool = x509.NewCertPool()
+		if ok := caPool.AppendCertsFromPEM([]byte(caBundle)); !ok {
+			return nil, fmt.Errorf("failed to parse the OIDC provider CA bundle")
+		}
+	}
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			// a new client is created on every sync, don't leave the connections behind
+			DisableKeepAlives: true,
+			TLSClientConfig: &tls.Config{
+				RootCAs: caPool,
+			},
+		},
+	}, nil
+}
+
+// NewProviderIssuerClient reads the CA bundle the provider is configured with from its configMap in
+// openshift-config and returns a client trusting it, together with the CA bundle. The returned
+// reason is empty on success.
+func NewProviderIssuerClient(configMapLister corev1listers.ConfigMapLister, provider *configv1.OIDCProvider) (*http.Client, string, string, error) {
+	var caBundle string
+	if caCMName := provider.Issuer.CertificateAuthority.Name; len(caCMName) > 0 {
+		caCM, err := configMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(caCMName)
+		if err != nil {
+			return nil, "", "CAGetFailed", fmt.Errorf("failed to get the CA configMap %q configured for the OIDC provider %q: %w", caCMName, provider.Name, err)
+		}
+		caBundle = caCM.Data[api.AuthServerCAFileName]
+	}
+
+	client, err := NewIssuerClient(caBundle)
+	if err != nil {
+		return nil, "", "InvalidCA", fmt.Errorf("OIDC provider %q: %w", provider.Name, err)
+	}
+	return client, caBundle, "", nil
+}
+
+// ValidateIssuer verifies the console can log in with the client through the provider: the
+// discovery document has to be served for the issuer URL, the JWKS has to hold signing keys, the
+// token endpoint has to accept client secrets and the extra scopes of the client have to be
+// supported. The client credentials themselves are checked by CheckClientCredentials. The
+// discovery is returned for a valid issuer, the returned reason is empty on success.
+func ValidateIssuer(client *http.Client, provider *configv1.OIDCProvider, clientConfig *configv1.OIDCClientConfig) (*ProviderDiscovery, string, error) {
+	discovery, reason, err := FetchDiscovery(client, provider.Issuer.URL)
+	if err != nil {
+		return nil, reason, err
+	}
+	if reason, err := ValidateJWKS(client, discovery.JWKSURI); err != nil {
+		return nil, reason, err
+	}
+	if err := CheckClientAuthentication(discovery); err != nil {
+		return nil, "UnsupportedClientAuthentication", err
+	}
+	if err := CheckScopes(discovery, clientConfig.ExtraScopes); err != nil {
+		return nil, "UnsupportedScopes", err
+	}
+	return discovery, "", nil
+}
+
+// GetClientCredentialsHash returns the hash of the inputs of CheckClientCredentials, so that the
+// credentials are only probed again once one of them changed.
+func GetClientCredentialsHash(issuerURL, caBundle, clientID, clientSecret string) string {
+	hash := sha256.New()
+	for _, input := range []string{issuerURL, caBundle, clientID, clientSecret} {
+		// the length keeps the inputs apart
+		fmt.Fprintf(hash, "%d:%s", len(input), input)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// DefaultDiscoveryConfigMap returns the configmap holding the discovery of the last validated
+// issuer, which the console config is derived from without fetching the discovery again.
+func DefaultDiscoveryConfigMap(discovery *ProviderDiscovery) *corev1.ConfigMap {
+	meta := util.SharedMeta()
+	meta.Name = api.OIDCDiscoveryConfigMapName
+	meta.Namespace = api.OpenShiftConsoleOperatorNamespace
+	return &corev1.ConfigMap{
+		ObjectMeta: meta,
+		Data: map[string]string{
+			"issuer":             discovery.Issuer,
+			"endSessionEndpoint": discovery.EndSessionEndpoint,
+		},
+	}
+}
+
+// GetDiscovery returns the discovery held by the configmap if it belongs to the issuer, nil otherwise.
+func GetDiscovery(configMap *corev1.ConfigMap, issuerURL string) *ProviderDiscovery {
+	if configMap == nil || configMap.Data["issuer"] != issuerURL {
+		return nil
+	}
+	return &ProviderDiscovery{
+		Issuer:             configMap.Data["issuer"],
+		EndSessionEndpoint: configMap.Data["endSessionEndpoint"],
+	}
+}
+
+// CheckClientAuthentication verifies, based on the discovery document alone, the console can redeem
+// authorization codes as a confidential client: the issuer has to serve a token endpoint, support
+// the authorization code flow and accept the client secret in the basic auth header or the request
+// body. The client credentials are checked by CheckClientCredentials.
+func CheckClientAuthentication(discovery *ProviderDiscovery) error {
+	if len(discovery.TokenEndpoint) == 0 {
+		return fmt.Errorf("issuer %q has no token_endpoint", discovery.Issuer)
+	}
+	if len(discovery.ResponseTypesSupported) > 0 && !slices.Contains(discovery.ResponseTypesSupported, "code") {
+		return fmt.Errorf("issuer %q doesn't support the authorization code flow", discovery.Issuer)
+	}
+	authMethods := discovery.TokenEndpointAuthMethodsSupported
+	if len(authMethods) > 0 && !slices.Contains(authMethods, "client_secret_basic") && !slices.Contains(authMethods, "client_secret_post") {
+		return fmt.Errorf("issuer %q doesn't accept client secrets at its token endpoint, only %s", discovery.Issuer, strings.Join(authMethods, ", "))
+	}
+	return nil
+}
+
+// CheckClientCredentials verifies the issuer accepts the client ID and secret at its token endpoint,
+// by redeeming an authorization code the issuer never issued. The issuer authenticates the client
+// before it looks at the code, so valid credentials get the code rejected with invalid_grant, while
+// invalid ones are rejected with invalid_client. The returned reason is empty on success.
+func CheckClientCredentials(client *http.Client, discovery *ProviderDiscovery, clientID, clientSecret string) (string, error) {
+	form := url.Values{
+		"grant_type": {"authorization_code"},
+		"code":       {probeAuthorizationCode},
+	}
+	authMethods := discovery.TokenEndpointAuthMethodsSupported
+	basicAuth := len(authMethods) == 0 || slices.Contains(authMethods, "client_secret_basic")
+	if !basicAuth {
+		form.Set("client_id", clientID)
+		form.Set("client_secret", clientSecret)
+	}
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "InvalidTokenEndpoint", fmt.Errorf("invalid token_endpoint %q: %v", discovery.TokenEndpoint, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if basicAuth {
+		// the credentials are form-encoded before they are put into the header, RFC 6749 section 2.3.1
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "FailedTokenRequest", fmt.Errorf("failed to reach %s: %v", discovery.TokenEndpoint, err)
+	}
+	defer resp.Body.Close()
+
+	tokenErr := &tokenErrorResponse{}
+	// a response which is not an OAuth error is reported below
+	_ = json.NewDecoder(io.LimitReader(resp.Body, maxDiscoveryResponseSize)).Decode(tokenErr)
+	switch {
+	case tokenErr.Error == "invalid_client" || resp.StatusCode == http.StatusUnauthorized:
+		return "InvalidClientCredentials", fmt.Errorf("issuer %q rejects the secret of the %q client: %s", discovery.Issuer, clientID, describeTokenError(resp, tokenErr))
+	case tokenErr.Error == "unauthorized_client":
+		return "UnauthorizedClient", fmt.Errorf("issuer %q doesn't allow the %q client to use the authorization code flow: %s", discovery.Issuer, clientID, describeTokenError(resp, tokenErr))
+	case len(tokenErr.Error) > 0:
+		// the client was authenticated, only the made up code was rejected
+		return "", nil
+	default:
+		return "UnexpectedTokenResponse", fmt.Errorf("%s returns '%s' without an OAuth error for an invalid authorization code", discovery.TokenEndpoint, resp.Status)
+	}
+}
+
+func describeTokenError(resp *http.Response, tokenErr *tokenErrorResponse) string {
+	if len(tokenErr.ErrorDescription) > 0 {
+		return fmt.Sprintf("%s: %s", tokenErr.Error, tokenErr.ErrorDescription)
+	}
+	if len(tokenErr.Error) > 0 {
+		return tokenErr.Error
+	}
+	return resp.Status
+}
+
+// ValidateCLIClient verifies oc can log in with the client through the provider. oc is a public
+// client, it can't keep a client secret and receives the authorization code on a localhost
+// callback, so the issuer has to support the authorization code flow with PKCE. The returned
+// reason is empty on success.
+func ValidateCLIClient(client *http.Client, provider *configv1.OIDCProvider, clientConfig *configv1.OIDCClientConfig) (string, error) {
+	if len(clientConfig.ClientID) == 0 {
+		return "MissingClientID", fmt.Errorf("no ID set on the CLI client of the OIDC provider %q", provider.Name)
+	}
+	if len(clientConfig.ClientSecret.Name) > 0 {
+		return "ConfidentialClient", fmt.Errorf("the CLI client %q of the OIDC provider %q references the client secret %q, oc logs in as a public client", clientConfig.ClientID, provider.Name, clientConfig.ClientSecret.Name)
+	}
+	if issuerURL, err := url.Parse(provider.Issuer.URL); err != nil || issuerURL.Scheme != "https" {
+		return "InvalidIssuer", fmt.Errorf("the issuer %q of the OIDC provider %q has to be an https URL", provider.Issuer.URL, provider.Name)
+	}
+
+	discovery, reason, err := FetchDiscovery(client, provider.Issuer.URL)
+	if err != nil {
+		return reason, err
+	}
+	if err := CheckCodeFlowWithPKCE(discovery); err != nil {
+		return "UnsupportedLocalhostCallback", err
+	}
+	if err := CheckScopes(discovery, clientConfig.ExtraScopes); err != nil {
+		return "UnsupportedScopes", err
+	}
+	return "", nil
+}
+
+// CheckCodeFlowWithPKCE verifies the issuer supports the authorization code flow with S256 PKCE
+// challenges. Only the response types are required in the discovery document, the challenge
+// methods are accepted if the issuer doesn't list them.
+func CheckCodeFlowWithPKCE(discovery *ProviderDiscovery) error {
+	if len(discovery.ResponseTypesSupported) > 0 && !slices.Contains(discovery.ResponseTypesSupported, "code") {
+		return fmt.Errorf("issuer %q doesn't support the authorization code flow", discovery.Issuer)
+	}
+	if len(discovery.CodeChallengeMethodsSupported) > 0 && !slices.Contains(discovery.CodeChallengeMethodsSupported, "S256") {
+		return fmt.Errorf("issuer %q doesn't support S256 PKCE code challenges", discovery.Issuer)
+	}
+	return nil
+}
+
+// FetchDiscovery fetches the discovery document of the issuer, which has to name the issuer
+// exactly the way it is configured.
+func FetchDiscovery(client *http.Client, issuerURL string) (*ProviderDiscovery, string, error) {
+	discoveryURL := strings.TrimSuffix(issuerURL, "/") + discoveryPath
+	discovery := &ProviderDiscovery{}
+	if reason, err := getJSON(client, discoveryURL, discovery, "FailedGetDiscovery", "InvalidDiscovery"); err != nil {
+		return nil, reason, err
+	}
+	if discovery.Issuer != issuerURL {
+		return nil, "IssuerMismatch", fmt.Errorf("%s names issuer %q instead of %q", discoveryURL, discovery.Issuer, issuerURL)
+	}
+	if len(discovery.JWKSURI) == 0 {
+		return nil, "InvalidDiscovery", fmt.Errorf("%s has no jwks_uri", discoveryURL)
+	}
+	return discovery, "", nil
+}
+
+// ValidateJWKS verifies the key set the issuer signs its tokens with holds at least one signing key.
+func ValidateJWKS(client *http.Client, jwksURI string) (string, error) {
+	keySet := &jsonWebKeySet{}
+	if reason, err := getJSON(client, jwksURI, keySet, "FailedGetJWKS", "InvalidJWKS"); err != nil {
+		return reason, err
+	}
+	for _, key := range keySet.Keys {
+		if len(key.KeyType) > 0 && (len(key.Use) == 0 || key.Use == "sig") {
+			return "", nil
+		}
+	}
+	return "InvalidJWKS", fmt.Errorf("%s holds no signing keys", jwksURI)
+}
+
+// CheckScopes verifies the issuer supports the extra scopes. Issuers are not required to list
+// their scopes, in which case every scope is accepted.
+func CheckScopes(discovery *ProviderDiscovery, extraScopes []string) error {
+	if len(discovery.ScopesSupported) == 0 {
+		return nil
+	}
+	unsupported := []string{}
+	for _, scope := range extraScopes {
+		if !slices.Contains(discovery.ScopesSupported, scope) {
+			unsupported = append(unsupported, scope)
+		}
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("issuer %q doesn't support the extra scopes %s", discovery.Issuer, strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+func getJSON(client *http.Client, url string, into interface{}, getReason, decodeReason string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return getReason, fmt.Errorf("failed to fetch %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return getReason, fmt.Errorf("%s returns '%s'", url, resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDiscoveryResponseSize)).Decode(into); err != nil {
+		return decodeReason, fmt.Errorf("failed to decode %s: %v", url, err)
+	}
+	return "", nil
+}
+
+// GetLogoutRedirect returns the URL the console redirects to after logging the user out, which
+// ends the user's session at the issuer and returns them to the post-logout redirect URI. An
+// empty string is returned if the issuer doesn't support RP-initiated logout.
+func GetLogoutRedirect(discovery *ProviderDiscovery, clientID, postLogoutRedirectURI string) (string, error) {
+	if len(discovery.EndSessionEndpoint) == 0 {
+		return "", nil
+	}
+	endSessionURL, err := url.Parse(discovery.EndSessionEndpoint)
+	if err != nil || endSessionURL.Scheme != "https" {
+		return "", fmt.Errorf("invalid end_session_endpoint %q of issuer %q", discovery.EndSessionEndpoint, discovery.Issuer)
+	}
+	query := endSessionURL.Query()
+	query.Set("client_id", clientID)
+	query.Set("post_logout_redirect_uri", postLogoutRedirectURI)
+	endSessionURL.RawQuery = query.Encode()
+	return endSessionURL.String(), nil
+}
+
+// ValidatePostLogoutRedirectURI verifies the issuer returns users to one of the console hosts
+// after logout, and not to an arbitrary site.
+func ValidatePostLogoutRedirectURI(postLogoutRedirectURI string, consoleHosts []string) error {
+	redirectURL, err := url.Parse(postLogoutRedirectURI)
+	if err != nil {
+		return fmt.Errorf("invalid post-logout redirect URI %q: %w", postLogoutRedirectURI, err)
+	}
+	if redirectURL.Scheme != "https" || !slices.Contains(consoleHosts, redirectURL.Hostname()) {
+		return fmt.Errorf("post-logout redirect URI %q has to be an https URL of the console hosts %s", postLogoutRedirectURI, strings.Join(consoleHosts, ", "))
+	}
+	return nil
+}
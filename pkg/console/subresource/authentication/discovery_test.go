@@ -0,0 +1,321 @@
+package authentication
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-test/deep"
+	config "github.com/openshift/api/config/v1"
+)
+
+func TestValidateIssuer(t *testing.T) {
+	tests := []struct {
+		name        string
+		issuerPath  string
+		discovery   map[string]interface{}
+		jwks        string
+		extraScopes []string
+		// secret the token endpoint accepts, defaults to the secret of the client
+		acceptedSecret string
+		// response of the token endpoint to a valid client, defaults to invalid_grant
+		tokenError string
+		wantReason string
+	}{
+		{
+			name:        "Valid issuer",
+			discovery:   map[string]interface{}{"scopes_supported": []string{"openid", "email", "groups"}},
+			jwks:        `{"keys":[{"kty":"RSA","use":"sig"}]}`,
+			extraScopes: []string{"email"},
+		},
+		{
+			name:        "Issuer without scopes_supported",
+			discovery:   map[string]interface{}{},
+			jwks:        `{"keys":[{"kty":"EC"}]}`,
+			extraScopes: []string{"email"},
+		},
+		{
+			name:       "Missing discovery",
+			issuerPath: "/missing",
+			wantReason: "FailedGetDiscovery",
+		},
+		{
+			name:       "Issuer mismatch",
+			discovery:  map[string]interface{}{"issuer": "https://other.example.com"},
+			jwks:       `{"keys":[{"kty":"RSA"}]}`,
+			wantReason: "IssuerMismatch",
+		},
+		{
+			name:       "No signing keys",
+			discovery:  map[string]interface{}{},
+			jwks:       `{"keys":[{"kty":"RSA","use":"enc"}]}`,
+			wantReason: "InvalidJWKS",
+		},
+		{
+			name:       "No token endpoint",
+			discovery:  map[string]interface{}{"token_endpoint": ""},
+			jwks:       `{"keys":[{"kty":"RSA"}]}`,
+			wantReason: "UnsupportedClientAuthentication",
+		},
+		{
+			name:       "Client secrets not accepted",
+			discovery:  map[string]interface{}{"token_endpoint_auth_methods_supported": []string{"private_key_jwt"}},
+			jwks:       `{"keys":[{"kty":"RSA"}]}`,
+			wantReason: "UnsupportedClientAuthentication",
+		},
+		{
+			name:      "Client secret in the request body",
+			discovery: map[string]interface{}{"token_endpoint_auth_methods_supported": []string{"client_secret_post", "private_key_jwt"}},
+			jwks:      `{"keys":[{"kty":"RSA"}]}`,
+		},
+		{
+			name:           "Client secret rejected",
+			discovery:      map[string]interface{}{},
+			jwks:           `{"keys":[{"kty":"RSA"}]}`,
+			acceptedSecret: "rotated",
+			wantReason:     "InvalidClientCredentials",
+		},
+		{
+			name:       "Client not allowed to use the authorization code flow",
+			discovery:  map[string]interface{}{"token_endpoint_auth_methods_supported": []string{"client_secret_post"}},
+			jwks:       `{"keys":[{"kty":"RSA"}]}`,
+			tokenError: "unauthorized_client",
+			wantReason: "UnauthorizedClient",
+		},
+		{
+			name:       "Token endpoint without an OAuth error",
+			discovery:  map[string]interface{}{},
+			jwks:       `{"keys":[{"kty":"RSA"}]}`,
+			tokenError: "-",
+			wantReason: "UnexpectedTokenResponse",
+		},
+		{
+			name:        "Unsupported scope",
+			discovery:   map[string]interface{}{"scopes_supported": []string{"openid"}},
+			jwks:        `{"keys":[{"kty":"RSA"}]}`,
+			extraScopes: []string{"groups"},
+			wantReason:  "UnsupportedScopes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/issuer" + discoveryPath:
+					discovery := map[string]interface{}{
+						"issuer":         server.URL + "/issuer",
+						"jwks_uri":       server.URL + "/jwks",
+						"token_endpoint": server.URL + "/token",
+					}
+					for k, v := range tt.discovery {
+						discovery[k] = v
+					}
+					_ = json.NewEncoder(w).Encode(discovery)
+				case "/jwks":
+					_, _ = w.Write([]byte(tt.jwks))
+				case "/token":
+					acceptedSecret := tt.acceptedSecret
+					if len(acceptedSecret) == 0 {
+						acceptedSecret = "secret"
+					}
+					clientID, clientSecret, basicAuth := r.BasicAuth()
+					if !basicAuth {
+						clientID, clientSecret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+					}
+					if clientID != "console" || clientSecret != acceptedSecret || r.PostFormValue("code") != probeAuthorizationCode {
+						w.WriteHeader(http.StatusUnauthorized)
+						_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+						return
+					}
+					switch tt.tokenError {
+					case "-":
+						w.WriteHeader(http.StatusInternalServerError)
+					case "":
+						w.WriteHeader(http.StatusBadRequest)
+						_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+					default:
+						w.WriteHeader(http.StatusBadRequest)
+						_, _ = w.Write([]byte(`{"error":"` + tt.tokenError + `"}`))
+					}
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+			client, err := NewIssuerClient(string(caBundle))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			issuerPath := tt.issuerPath
+			if len(issuerPath) == 0 {
+				issuerPath = "/issuer"
+			}
+			provider := &config.OIDCProvider{Issuer: config.TokenIssuer{URL: server.URL + issuerPath}}
+			clientConfig := &config.OIDCClientConfig{ClientID: "console", ExtraScopes: tt.extraScopes}
+
+			discovery, reason, err := ValidateIssuer(client, provider, clientConfig)
+			if err == nil {
+				reason, err = CheckClientCredentials(client, discovery, clientConfig.ClientID, "secret")
+			}
+			if diff := deep.Equal(reason, tt.wantReason); diff != nil {
+				t.Error(diff, err)
+			}
+			if (err != nil) != (len(tt.wantReason) > 0) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetLogoutRedirect(t *testing.T) {
+	tests := []struct {
+		name      string
+		discovery *ProviderDiscovery
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "Issuer without end_session_endpoint",
+			discovery: &ProviderDiscovery{Issuer: "https://idp.example.com"},
+		},
+		{
+			name:      "Issuer with end_session_endpoint",
+			discovery: &ProviderDiscovery{Issuer: "https://idp.example.com", EndSessionEndpoint: "https://idp.example.com/logout?ui_locales=en"},
+			want:      "https://idp.example.com/logout?client_id=console&post_logout_redirect_uri=https%3A%2F%2Fconsole.example.com%2F&ui_locales=en",
+		},
+		{
+			name:      "Insecure end_session_endpoint",
+			discovery: &ProviderDiscovery{Issuer: "https://idp.example.com", EndSessionEndpoint: "http://idp.example.com/logout"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetLogoutRedirect(tt.discovery, "console", "https://console.example.com/")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestValidatePostLogoutRedirectURI(t *testing.T) {
+	consoleHosts := []string{"console.example.com", "console.apps.example.com"}
+	tests := []struct {
+		name    string
+		uri     string
+		wantErr bool
+	}{
+		{
+			name: "Console host",
+			uri:  "https://console.example.com/",
+		},
+		{
+			name: "Additional console host",
+			uri:  "https://console.apps.example.com/logged-out",
+		},
+		{
+			name:    "Foreign host",
+			uri:     "https://evil.example.com/",
+			wantErr: true,
+		},
+		{
+			name:    "Insecure URI",
+			uri:     "http://console.example.com/",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePostLogoutRedirectURI(tt.uri, consoleHosts); (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateCLIClient(t *testing.T) {
+	tests := []struct {
+		name         string
+		discovery    map[string]interface{}
+		clientConfig config.OIDCClientConfig
+		wantReason   string
+	}{
+		{
+			name:         "Public client with PKCE",
+			discovery:    map[string]interface{}{"response_types_supported": []string{"code", "id_token"}, "code_challenge_methods_supported": []string{"plain", "S256"}},
+			clientConfig: config.OIDCClientConfig{ClientID: "oc"},
+		},
+		{
+			name:         "Issuer without code challenge methods",
+			discovery:    map[string]interface{}{"response_types_supported": []string{"code"}},
+			clientConfig: config.OIDCClientConfig{ClientID: "oc"},
+		},
+		{
+			name:         "Missing client ID",
+			wantReason:   "MissingClientID",
+			clientConfig: config.OIDCClientConfig{},
+		},
+		{
+			name:         "Confidential client",
+			clientConfig: config.OIDCClientConfig{ClientID: "oc", ClientSecret: config.SecretNameReference{Name: "oc-secret"}},
+			wantReason:   "ConfidentialClient",
+		},
+		{
+			name:         "Issuer without PKCE S256",
+			discovery:    map[string]interface{}{"code_challenge_methods_supported": []string{"plain"}},
+			clientConfig: config.OIDCClientConfig{ClientID: "oc"},
+			wantReason:   "UnsupportedLocalhostCallback",
+		},
+		{
+			name:         "Issuer without code flow",
+			discovery:    map[string]interface{}{"response_types_supported": []string{"id_token"}},
+			clientConfig: config.OIDCClientConfig{ClientID: "oc"},
+			wantReason:   "UnsupportedLocalhostCallback",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != discoveryPath {
+					http.NotFound(w, r)
+					return
+				}
+				discovery := map[string]interface{}{
+					"issuer":   server.URL,
+					"jwks_uri": server.URL + "/jwks",
+				}
+				for k, v := range tt.discovery {
+					discovery[k] = v
+				}
+				_ = json.NewEncoder(w).Encode(discovery)
+			}))
+			defer server.Close()
+
+			caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+			client, err := NewIssuerClient(string(caBundle))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			provider := &config.OIDCProvider{Name: "idp", Issuer: config.TokenIssuer{URL: server.URL}}
+			reason, err := ValidateCLIClient(client, provider, &tt.clientConfig)
+			if diff := deep.Equal(reason, tt.wantReason); diff != nil {
+				t.Error(diff, err)
+			}
+			if (err != nil) != (len(tt.wantReason) > 0) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
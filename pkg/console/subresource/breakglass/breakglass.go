@@ -0,0 +1,174 @@
+package breakglass
+
+import (
+	"fmt"
+	"time"
+
+	// kube
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	// console-operator
+	"github.com/openshift/console-operator/bindata"
+	"github.com/openshift/console-operator/pkg/api"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+)
+
+const (
+	// MaxDuration limits how far ahead break-glass access can be requested, so it can't be
+	// left enabled by accident.
+	MaxDuration = 24 * time.Hour
+	// LocalPort is the port the break-glass console is forwarded to and served at on the
+	// machine of the admin.
+	LocalPort = 8443
+	// authType disables the login of the break-glass console, the console has no login which
+	// keeps working while the OIDC provider is broken. It is only reachable through a port
+	// forward instead, which requires the kubeconfig of an admin.
+	authType = "disabled"
+	// component labels the break-glass pods apart from the console pods.
+	component = "ui-break-glass"
+
+	configMapResourceVersionAnnotation = "console.openshift.io/console-config-version"
+)
+
+// GetUntil returns the time break-glass access was requested until. The zero time is returned
+// if it is not requested.
+func GetUntil(operatorConfig *operatorv1.Console, now time.Time) (time.Time, error) {
+	value, ok := operatorConfig.Annotations[api.BreakGlassUntilAnnotation]
+	if !ok {
+		return time.Time{}, nil
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s annotation %q, an RFC 3339 time is expected: %w", api.BreakGlassUntilAnnotation, value, err)
+	}
+	if until.After(now.Add(MaxDuration)) {
+		return time.Time{}, fmt.Errorf("invalid %s annotation %q, break-glass access can be requested for at most %s", api.BreakGlassUntilAnnotation, value, MaxDuration)
+	}
+	return until, nil
+}
+
+// IsConsoleClientDegraded returns whether the authentication status reports the OIDC client
+// of the console Degraded.
+func IsConsoleClientDegraded(authnConfig *configv1.Authentication) bool {
+	for _, client := range authnConfig.Status.OIDCClients {
+		if client.ComponentNamespace != api.TargetNamespace || client.ComponentName != api.OpenShiftConsoleName {
+			continue
+		}
+		for _, condition := range client.Conditions {
+			if condition.Type == "Degraded" && condition.Status == metav1.ConditionTrue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DefaultService returns the service of the break-glass console pods.
+func DefaultService() *corev1.Service {
+	service := resourceread.ReadServiceV1OrDie(bindata.MustAsset("assets/services/console-service.yaml"))
+	service.Name = api.BreakGlassName
+	service.Annotations["service.beta.openshift.io/serving-cert-secret-name"] = api.BreakGlassServingCertName
+	service.Spec.Selector["component"] = component
+	return service
+}
+
+// GetPortForwardCommand returns the command admins reach the break-glass console with.
+func GetPortForwardCommand() string {
+	return fmt.Sprintf("oc port-forward -n %s service/%s %d:443", api.TargetNamespace, api.BreakGlassName, LocalPort)
+}
+
+// DefaultNetworkPolicy returns the policy denying all ingress to the break-glass console pods,
+// as their login is disabled. A port forward connects to the pod itself and is not subject to it.
+func DefaultNetworkPolicy() *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      api.BreakGlassName,
+			Namespace: api.TargetNamespace,
+			Labels:    map[string]string{"app": api.OpenShiftConsoleName},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"component": component}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// DefaultConfigMap returns the config of the break-glass console, which is the console config
+// served at the forwarded local port with the OIDC login disabled.
+func DefaultConfigMap(consoleConfigMap *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	config, err := configmapsub.ReadConsoleConfigFields(consoleConfigMap)
+	if err != nil {
+		return nil, err
+	}
+	// only the login and the address are patched, the rest of the config is served as it is
+	config["auth"] = map[string]interface{}{"authType": authType}
+	delete(config, "session")
+	if err := unstructured.SetNestedField(config, fmt.Sprintf("https://localhost:%d", LocalPort), "clusterInfo", "consoleBaseAddress"); err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(config, "clusterInfo", "additionalConsoleBaseAddresses")
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      api.BreakGlassName,
+			Namespace: api.TargetNamespace,
+			Labels:    consoleConfigMap.Labels,
+		},
+	}
+	if err := configmapsub.WriteConsoleConfigFields(configMap, config); err != nil {
+		return nil, err
+	}
+	return configMap, nil
+}
+
+// DefaultDeployment returns a single replica of the console deployment, serving the break-glass
+// config with the certificate of the break-glass service.
+func DefaultDeployment(consoleDeployment *appsv1.Deployment, configMap *corev1.ConfigMap) *appsv1.Deployment {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            api.BreakGlassName,
+			Namespace:       api.TargetNamespace,
+			Labels:          withComponent(consoleDeployment.Labels),
+			OwnerReferences: consoleDeployment.OwnerReferences,
+		},
+		Spec: *consoleDeployment.Spec.DeepCopy(),
+	}
+
+	replicas := int32(1)
+	deployment.Spec.Replicas = &replicas
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: withComponent(consoleDeployment.Spec.Selector.MatchLabels)}
+	deployment.Spec.Template.Labels = withComponent(consoleDeployment.Spec.Template.Labels)
+	// the anti-affinity only spreads the console replicas
+	deployment.Spec.Template.Spec.Affinity = nil
+	deployment.Spec.Template.Annotations = map[string]string{
+		configMapResourceVersionAnnotation: configMap.GetResourceVersion(),
+	}
+
+	for i, volume := range deployment.Spec.Template.Spec.Volumes {
+		switch volume.Name {
+		case api.OpenShiftConsoleConfigMapName:
+			deployment.Spec.Template.Spec.Volumes[i].ConfigMap.Name = configMap.Name
+		case api.ConsoleServingCertName:
+			deployment.Spec.Template.Spec.Volumes[i].Secret.SecretName = api.BreakGlassServingCertName
+		}
+	}
+	return deployment
+}
+
+func withComponent(labels map[string]string) map[string]string {
+	componentLabels := map[string]string{}
+	for k, v := range labels {
+		componentLabels[k] = v
+	}
+	componentLabels["component"] = component
+	return componentLabels
+}
@@ -0,0 +1,174 @@
+package breakglass
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+)
+
+func TestGetUntil(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        time.Time
+		wantErr     bool
+	}{
+		{
+			name: "Not requested",
+		},
+		{
+			name:        "Requested for an hour",
+			annotations: map[string]string{api.BreakGlassUntilAnnotation: "2024-01-01T13:00:00Z"},
+			want:        now.Add(time.Hour),
+		},
+		{
+			name:        "Requested for too long",
+			annotations: map[string]string{api.BreakGlassUntilAnnotation: "2024-01-03T12:00:00Z"},
+			wantErr:     true,
+		},
+		{
+			name:        "Invalid time",
+			annotations: map[string]string{api.BreakGlassUntilAnnotation: "tomorrow"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			got, err := GetUntil(operatorConfig, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("GetUntil() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsConsoleClientDegraded(t *testing.T) {
+	clientStatus := func(componentName string, status metav1.ConditionStatus) configv1.OIDCClientStatus {
+		return configv1.OIDCClientStatus{
+			ComponentNamespace: api.TargetNamespace,
+			ComponentName:      componentName,
+			Conditions:         []metav1.Condition{{Type: "Degraded", Status: status}},
+		}
+	}
+	tests := []struct {
+		name    string
+		clients []configv1.OIDCClientStatus
+		want    bool
+	}{
+		{
+			name: "No client status",
+		},
+		{
+			name:    "Console client degraded",
+			clients: []configv1.OIDCClientStatus{clientStatus(api.CLIOIDCClientComponentName, metav1.ConditionFalse), clientStatus(api.OpenShiftConsoleName, metav1.ConditionTrue)},
+			want:    true,
+		},
+		{
+			name:    "Only the CLI client degraded",
+			clients: []configv1.OIDCClientStatus{clientStatus(api.CLIOIDCClientComponentName, metav1.ConditionTrue), clientStatus(api.OpenShiftConsoleName, metav1.ConditionFalse)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authnConfig := &configv1.Authentication{Status: configv1.AuthenticationStatus{OIDCClients: tt.clients}}
+			if got := IsConsoleClientDegraded(authnConfig); got != tt.want {
+				t.Errorf("IsConsoleClientDegraded() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultConfigMapAndDeployment(t *testing.T) {
+	consoleConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: api.OpenShiftConsoleConfigMapName, Namespace: api.TargetNamespace},
+		Data: map[string]string{"console-config.yaml": `auth:
+  authType: oidc
+  oidcIssuer: https://idp.example.com
+clusterInfo:
+  consoleBaseAddress: https://console-openshift-console.apps.example.com
+  additionalConsoleBaseAddresses:
+  - https://console.example.com
+customization:
+  unmodeledOverride: kept
+`},
+	}
+	configMap, err := DefaultConfigMap(consoleConfigMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := configmapsub.ReadConsoleConfig(configMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(config.Auth.AuthType, authType); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(config.Auth.OIDCIssuer, ""); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(config.ClusterInfo.ConsoleBaseAddress, "https://localhost:8443"); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(config.ClusterInfo.AdditionalConsoleBaseAddresses, []string(nil)); diff != nil {
+		t.Error(diff)
+	}
+	if !strings.Contains(configMap.Data["console-config.yaml"], "unmodeledOverride: kept") {
+		t.Errorf("expected the keys the console server config doesn't model to be kept, got:\n%s", configMap.Data["console-config.yaml"])
+	}
+
+	labels := map[string]string{"app": "console", "component": "ui"}
+	replicas := int32(2)
+	consoleDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: api.OpenShiftConsoleDeploymentName, Namespace: api.TargetNamespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Affinity: &corev1.Affinity{},
+					Volumes: []corev1.Volume{
+						{Name: api.OpenShiftConsoleConfigMapName, VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: api.OpenShiftConsoleConfigMapName}}}},
+						{Name: api.ConsoleServingCertName, VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: api.ConsoleServingCertName}}},
+					},
+				},
+			},
+		},
+	}
+	deployment := DefaultDeployment(consoleDeployment, configMap)
+	if diff := deep.Equal(*deployment.Spec.Replicas, int32(1)); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(deployment.Spec.Selector.MatchLabels, map[string]string{"app": "console", "component": component}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(deployment.Spec.Template.Spec.Volumes[0].ConfigMap.Name, api.BreakGlassName); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(deployment.Spec.Template.Spec.Volumes[1].Secret.SecretName, api.BreakGlassServingCertName); diff != nil {
+		t.Error(diff)
+	}
+	// the console deployment is left as it is
+	if diff := deep.Equal(consoleDeployment.Spec.Template.Spec.Volumes[0].ConfigMap.Name, api.OpenShiftConsoleConfigMapName); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(consoleDeployment.Spec.Selector.MatchLabels["component"], "ui"); diff != nil {
+		t.Error(diff)
+	}
+}
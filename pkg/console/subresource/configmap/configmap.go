@@ -5,6 +5,9 @@ import (
 	"net/url"
 	"sort"
 
+	yaml2 "github.com/ghodss/yaml"
+	"gopkg.in/yaml.v2"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 
@@ -14,6 +17,7 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 	"github.com/openshift/console-operator/bindata"
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleplugin"
 	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
 	infrastructuresub "github.com/openshift/console-operator/pkg/console/subresource/infrastructure"
 	"github.com/openshift/console-operator/pkg/console/subresource/util"
@@ -26,6 +30,52 @@ const (
 	pluginProxyEndpoint   = "/api/proxy/plugin/"
 )
 
+// ReadConsoleConfig decodes the console server config of the console-config configmap.
+func ReadConsoleConfig(configMap *corev1.ConfigMap) (*consoleserver.Config, error) {
+	config := &consoleserver.Config{}
+	if err := yaml.Unmarshal([]byte(configMap.Data[consoleConfigYamlFile]), config); err != nil {
+		return nil, fmt.Errorf("failed to decode %s of %s configmap: %w", consoleConfigYamlFile, configMap.Name, err)
+	}
+	return config, nil
+}
+
+// WriteConsoleConfig encodes the console server config into the configmap.
+func WriteConsoleConfig(configMap *corev1.ConfigMap, config *consoleserver.Config) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s of %s configmap: %w", consoleConfigYamlFile, configMap.Name, err)
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[consoleConfigYamlFile] = string(data)
+	return nil
+}
+
+// ReadConsoleConfigFields decodes the console-config.yaml of the configmap without the schema of
+// the console server config, so that keys it doesn't model, e.g. the ones set through
+// unsupportedConfigOverrides, are kept when the fields are written back.
+func ReadConsoleConfigFields(configMap *corev1.ConfigMap) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if err := yaml2.Unmarshal([]byte(configMap.Data[consoleConfigYamlFile]), &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode %s of %s configmap: %w", consoleConfigYamlFile, configMap.Name, err)
+	}
+	return fields, nil
+}
+
+// WriteConsoleConfigFields encodes the fields read by ReadConsoleConfigFields into the configmap.
+func WriteConsoleConfigFields(configMap *corev1.ConfigMap, fields map[string]interface{}) error {
+	data, err := yaml2.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s of %s configmap: %w", consoleConfigYamlFile, configMap.Name, err)
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[consoleConfigYamlFile] = string(data)
+	return nil
+}
+
 func statusPageId(operatorConfig *operatorv1.Console) string {
 	if operatorConfig.Spec.Providers.Statuspage != nil {
 		return operatorConfig.Spec.Providers.Statuspage.PageID
@@ -37,12 +87,14 @@ func DefaultConfigMap(
 	operatorConfig *operatorv1.Console,
 	consoleConfig *configv1.Console,
 	authConfig *configv1.Authentication,
+	oidcLogoutRedirect string,
 	managedConfig *corev1.ConfigMap,
 	monitoringSharedConfig *corev1.ConfigMap,
 	infrastructureConfig *configv1.Infrastructure,
 	activeConsoleRoute *routev1.Route,
 	inactivityTimeoutSeconds int,
 	availablePlugins []*v1.ConsolePlugin,
+	pluginsOrder []string,
 	nodeArchitectures []string,
 	nodeOperatingSystems []string,
 	copiedCSVsDisabled bool,
@@ -84,14 +136,14 @@ func DefaultConfigMap(
 		userDefinedBuilder = userDefinedBuilder.CustomHostnameRedirectPort(isCustomRoute(activeConsoleRoute))
 	}
 	userDefinedConfig, err := userDefinedBuilder.Host(consoleHost).
-		LogoutURL(consoleConfig.Spec.Authentication.LogoutRedirect).
+		LogoutURL(getLogoutRedirect(consoleConfig, oidcLogoutRedirect)).
 		Brand(operatorConfig.Spec.Customization.Brand).
 		DocURL(operatorConfig.Spec.Customization.DocumentationBaseURL).
 		APIServerURL(apiServerURL).
 		TopologyMode(infrastructureConfig.Status.ControlPlaneTopology).
 		Monitoring(monitoringSharedConfig).
 		Plugins(getPluginsEndpointMap(availablePlugins)).
-		PluginsOrder(availablePlugins, operatorConfig).
+		PluginsOrder(pluginsOrder).
 		I18nNamespaces(pluginsWithI18nNamespace(availablePlugins)).
 		ContentSecurityPolicies(aggregateCSPDirectives(availablePlugins)).
 		Proxy(getPluginsProxyServices(availablePlugins)).
@@ -146,6 +198,15 @@ func DefaultConfigMap(
 	return configMap, willMergeConfigOverrides, nil
 }
 
+// getLogoutRedirect returns the logout redirect set in the console config, or the one derived
+// from the end_session_endpoint of the OIDC provider.
+func getLogoutRedirect(consoleConfig *configv1.Console, oidcLogoutRedirect string) string {
+	if logoutRedirect := consoleConfig.Spec.Authentication.LogoutRedirect; len(logoutRedirect) > 0 {
+		return logoutRedirect
+	}
+	return oidcLogoutRedirect
+}
+
 func aggregateCSPDirectives(plugins []*v1.ConsolePlugin) map[v1.DirectiveType][]string {
 	aggregated := make(map[v1.DirectiveType]map[string]struct{}) // Use a map to ensure uniqueness
 
@@ -179,11 +240,13 @@ func aggregateCSPDirectives(plugins []*v1.ConsolePlugin) map[v1.DirectiveType][]
 	return result
 }
 
+// pluginsWithI18nNamespace returns the i18n namespaces of the plugins preloading their
+// localization resources, plugins not migrated from the v1alpha1 annotation included.
 func pluginsWithI18nNamespace(availablePlugins []*v1.ConsolePlugin) []string {
 	i18nNamespaces := []string{}
 	for _, plugin := range availablePlugins {
-		if plugin.Spec.I18n.LoadType == v1.Preload {
-			i18nNamespaces = append(i18nNamespaces, fmt.Sprintf("plugin__%s", plugin.Name))
+		if consoleplugin.GetI18nLoadType(plugin) == v1.Preload {
+			i18nNamespaces = append(i18nNamespaces, consoleplugin.GetI18nNamespace(plugin.Name))
 		}
 	}
 	// Sort to ensure deterministic YAML output
@@ -214,13 +277,12 @@ func getPluginsProxyServices(availablePlugins []*v1.ConsolePlugin) []consoleserv
 			// currently we only supprot 'Service' backend type for proxy
 			switch proxy.Endpoint.Type {
 			case v1.ProxyTypeService:
-				proxyService := consoleserver.ProxyService{
+				proxyServices = append(proxyServices, consoleserver.ProxyService{
 					ConsoleAPIPath: getConsoleAPIPath(plugin.Name, &proxy),
 					Endpoint:       getProxyServiceURL(proxy.Endpoint.Service),
 					CACertificate:  proxy.CACertificate,
 					Authorize:      getProxyAuthorization(proxy.Authorization),
-				}
-				proxyServices = append(proxyServices, proxyService)
+				})
 			default:
 				klog.Errorf("unknown proxy service type for %q plugin: %q. Currently only %q proxy endpoint type is supported.", plugin.Name, proxy.Endpoint.Type, v1.ProxyTypeService)
 			}
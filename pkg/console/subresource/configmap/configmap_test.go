@@ -19,6 +19,7 @@ import (
 	operatorv1 "github.com/openshift/api/operator/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleplugin"
 )
 
 const (
@@ -1289,16 +1290,20 @@ providers: {}
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			enabledPluginNames, _ := consoleplugin.GetEnabledPluginNames(tt.args.operatorConfig, tt.args.availablePlugins)
+			pluginsOrder, _, _ := consoleplugin.OrderPlugins(enabledPluginNames, tt.args.availablePlugins)
 			cm, _, _ := DefaultConfigMap(
 				tt.args.operatorConfig,
 				tt.args.consoleConfig,
 				tt.args.authConfig,
+				"",
 				tt.args.managedConfig,
 				tt.args.monitoringSharedConfig,
 				tt.args.infrastructureConfig,
 				tt.args.rt,
 				tt.args.inactivityTimeoutSeconds,
 				tt.args.availablePlugins,
+				pluginsOrder,
 				tt.args.nodeArchitectures,
 				tt.args.nodeOperatingSystems,
 				tt.args.copiedCSVsDisabled,
@@ -1397,6 +1402,12 @@ func testPluginsWithI18nPreloadType(pluginName, serviceName, serviceNamespace st
 	return plugin
 }
 
+func testPluginsWithI18nAnnotation(pluginName, serviceName, serviceNamespace string) *consolev1.ConsolePlugin {
+	plugin := testPlugins(pluginName, serviceName, serviceNamespace)
+	plugin.Annotations = map[string]string{api.V1Alpha1PluginI18nAnnotation: "true"}
+	return plugin
+}
+
 func TestStub(t *testing.T) {
 	tests := []struct {
 		name string
@@ -1664,6 +1675,17 @@ func TestPluginsWithI18nNamespaceSorting(t *testing.T) {
 				"plugin__zeta-plugin",
 			},
 		},
+		{
+			name: "Includes plugins with the v1alpha1 i18n annotation",
+			input: []*consolev1.ConsolePlugin{
+				testPluginsWithI18nAnnotation("zeta-plugin", "svc-z", "ns-z"),
+				testPluginsWithI18nPreloadType("alpha-plugin", "svc-a", "ns-a"),
+			},
+			output: []string{
+				"plugin__alpha-plugin",
+				"plugin__zeta-plugin",
+			},
+		},
 		{
 			name:   "Returns empty slice for no plugins",
 			input:  []*consolev1.ConsolePlugin{},
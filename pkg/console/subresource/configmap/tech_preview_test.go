@@ -46,12 +46,14 @@ func TestTechPreviewEnabled(t *testing.T) {
 				minimalOperatorConfig(),
 				minimalConsoleConfig(),
 				minimalAuthConfig(),
+				"", // oidcLogoutRedirect
 				&corev1.ConfigMap{},
 				&corev1.ConfigMap{},
 				minimalInfrastructureConfig(),
 				minimalRoute(),
 				0,                            // inactivityTimeoutSeconds
 				[]*consolev1.ConsolePlugin{}, // availablePlugins
+				[]string{},                   // pluginsOrder
 				[]string{"amd64"},            // nodeArchitectures
 				[]string{"linux"},            // nodeOperatingSystems
 				false,                        // copiedCSVsDisabled
@@ -114,12 +116,14 @@ func TestOLMLifecycleMetadataEnabled(t *testing.T) {
 				minimalOperatorConfig(),
 				minimalConsoleConfig(),
 				minimalAuthConfig(),
+				"", // oidcLogoutRedirect
 				&corev1.ConfigMap{},
 				&corev1.ConfigMap{},
 				minimalInfrastructureConfig(),
 				minimalRoute(),
 				0,                            // inactivityTimeoutSeconds
 				[]*consolev1.ConsolePlugin{}, // availablePlugins
+				[]string{},                   // pluginsOrder
 				[]string{"amd64"},            // nodeArchitectures
 				[]string{"linux"},            // nodeOperatingSystems
 				false,                        // copiedCSVsDisabled
@@ -43,12 +43,14 @@ func TestTLSConfigInjection(t *testing.T) {
 				minimalOperatorConfig(),
 				minimalConsoleConfig(),
 				minimalAuthConfig(),
+				"", // oidcLogoutRedirect
 				&corev1.ConfigMap{},
 				&corev1.ConfigMap{},
 				minimalInfrastructureConfig(),
 				minimalRoute(),
 				0,                            // inactivityTimeoutSeconds
 				[]*consolev1.ConsolePlugin{}, // availablePlugins
+				[]string{},                   // pluginsOrder
 				[]string{"amd64"},            // nodeArchitectures
 				[]string{"linux"},            // nodeOperatingSystems
 				false,                        // copiedCSVsDisabled
@@ -0,0 +1,103 @@
+package consoleplugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	// kube
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	// openshift
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// AutoEnablePolicy enables the plugins matching a label selector, or served from a trusted
+// namespace, without listing them in spec.plugins of the operator config. The policy is
+// opt-in, configured through annotations on the operator config:
+//   - console.openshift.io/plugin-auto-enable-selector, a label selector of the plugins
+//   - console.openshift.io/plugin-auto-enable-namespaces, a comma separated list of the
+//     namespaces the plugin backends are trusted in
+//   - console.openshift.io/plugin-auto-enable-deny, a comma separated list of the plugins
+//     which are never enabled automatically
+//
+// The deny list doesn't apply to the plugins listed in spec.plugins.
+type AutoEnablePolicy struct {
+	selector   labels.Selector
+	namespaces sets.Set[string]
+	denied     sets.Set[string]
+}
+
+// GetAutoEnablePolicy returns the auto-enable policy of the operator config, or nil if
+// the admin didn't opt in.
+func GetAutoEnablePolicy(operatorConfig *operatorv1.Console) (*AutoEnablePolicy, error) {
+	selector, hasSelector := operatorConfig.Annotations[api.PluginAutoEnableSelectorAnnotation]
+	namespaces := splitList(operatorConfig.Annotations[api.PluginAutoEnableNamespaceAnnotation])
+	if !hasSelector && len(namespaces) == 0 {
+		return nil, nil
+	}
+
+	policy := &AutoEnablePolicy{
+		namespaces: sets.New(namespaces...),
+		denied:     sets.New(splitList(operatorConfig.Annotations[api.PluginAutoEnableDenyAnnotation])...),
+	}
+	if hasSelector {
+		parsedSelector, err := labels.Parse(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation %q: %w", api.PluginAutoEnableSelectorAnnotation, selector, err)
+		}
+		if parsedSelector.Empty() {
+			return nil, fmt.Errorf("invalid %s annotation: an empty selector would enable every plugin", api.PluginAutoEnableSelectorAnnotation)
+		}
+		policy.selector = parsedSelector
+	}
+	return policy, nil
+}
+
+// Matches returns true if the plugin is enabled by the policy.
+func (p *AutoEnablePolicy) Matches(plugin *consolev1.ConsolePlugin) bool {
+	if p.denied.Has(plugin.Name) {
+		return false
+	}
+	if p.selector != nil && p.selector.Matches(labels.Set(plugin.Labels)) {
+		return true
+	}
+	backend := plugin.Spec.Backend.Service
+	return backend != nil && p.namespaces.Has(backend.Namespace)
+}
+
+// GetEnabledPluginNames returns the plugins listed in spec.plugins of the operator config,
+// followed by the plugins enabled by the auto-enable policy sorted by name. If the policy
+// is invalid, only the listed plugins are returned along with the error.
+func GetEnabledPluginNames(operatorConfig *operatorv1.Console, plugins []*consolev1.ConsolePlugin) ([]string, error) {
+	enabledPluginNames := util.RemoveDuplicateStr(operatorConfig.Spec.Plugins)
+	policy, err := GetAutoEnablePolicy(operatorConfig)
+	if policy == nil {
+		return enabledPluginNames, err
+	}
+
+	listed := sets.New(enabledPluginNames...)
+	autoEnabled := []string{}
+	for _, plugin := range plugins {
+		if plugin != nil && !listed.Has(plugin.Name) && policy.Matches(plugin) {
+			autoEnabled = append(autoEnabled, plugin.Name)
+		}
+	}
+	sort.Strings(autoEnabled)
+	return append(enabledPluginNames, autoEnabled...), nil
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
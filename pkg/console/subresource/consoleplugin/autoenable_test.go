@@ -0,0 +1,102 @@
+package consoleplugin
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetEnabledPluginNames(t *testing.T) {
+	plugin := func(name, namespace string, labels map[string]string) *consolev1.ConsolePlugin {
+		return &consolev1.ConsolePlugin{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec: consolev1.ConsolePluginSpec{
+				Backend: consolev1.ConsolePluginBackend{
+					Type:    consolev1.Service,
+					Service: &consolev1.ConsolePluginService{Name: name, Namespace: namespace, Port: 9443},
+				},
+			},
+		}
+	}
+	plugins := []*consolev1.ConsolePlugin{
+		plugin("listed", "other", nil),
+		plugin("team-b", "other", map[string]string{"console.example.com/team": "b"}),
+		plugin("team-a", "other", map[string]string{"console.example.com/team": "a"}),
+		plugin("trusted", "trusted-ns", nil),
+		plugin("untrusted", "other", nil),
+	}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []string
+		wantErr     bool
+	}{
+		{
+			name: "Only listed plugins without a policy",
+			want: []string{"listed"},
+		},
+		{
+			name: "Plugins matching the selector",
+			annotations: map[string]string{
+				api.PluginAutoEnableSelectorAnnotation: "console.example.com/team",
+			},
+			want: []string{"listed", "team-a", "team-b"},
+		},
+		{
+			name: "Plugins served from a trusted namespace",
+			annotations: map[string]string{
+				api.PluginAutoEnableNamespaceAnnotation: "trusted-ns, another-ns",
+			},
+			want: []string{"listed", "trusted"},
+		},
+		{
+			name: "Denied plugins are not enabled automatically",
+			annotations: map[string]string{
+				api.PluginAutoEnableSelectorAnnotation:  "console.example.com/team in (a,b)",
+				api.PluginAutoEnableNamespaceAnnotation: "trusted-ns",
+				api.PluginAutoEnableDenyAnnotation:      "team-b,trusted,listed",
+			},
+			want: []string{"listed", "team-a"},
+		},
+		{
+			name: "Invalid selector",
+			annotations: map[string]string{
+				api.PluginAutoEnableSelectorAnnotation: "console.example.com/team in (a",
+			},
+			want:    []string{"listed"},
+			wantErr: true,
+		},
+		{
+			name: "Empty selector",
+			annotations: map[string]string{
+				api.PluginAutoEnableSelectorAnnotation: "",
+			},
+			want:    []string{"listed"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec: operatorv1.ConsoleSpec{
+					Plugins: []string{"listed"},
+				},
+			}
+			got, err := GetEnabledPluginNames(operatorConfig, plugins)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetEnabledPluginNames() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
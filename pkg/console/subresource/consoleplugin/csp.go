@@ -0,0 +1,105 @@
+package consoleplugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	// openshift
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// CSPGuardrails caps the Content-Security-Policy directive values plugins can add to the
+// console. The guardrails are configured as JSON in the console.openshift.io/plugin-csp-guardrails
+// annotation of the operator config, for example:
+//
+//	{"allowedSources": {"ScriptSrc": ["https://*.example.com"]}, "forbiddenValues": ["'unsafe-eval'"]}
+//
+// A plugin directive with a value which is forbidden, or doesn't match any of the allowed
+// source patterns of the directive, is left out of the console config as a whole.
+type CSPGuardrails struct {
+	// AllowedSources are the source patterns allowed per directive, matched with path.Match.
+	// A "*" doesn't match "/", so "https://*.example.com" allows any subdomain but none of
+	// its paths, which need a pattern of their own, e.g. "https://*.example.com/*".
+	// Directives which are not listed are not restricted.
+	AllowedSources map[consolev1.DirectiveType][]string `json:"allowedSources,omitempty"`
+	// ForbiddenValues are not allowed in any directive.
+	ForbiddenValues []string `json:"forbiddenValues,omitempty"`
+}
+
+// GetCSPGuardrails returns the CSP guardrails of the operator config, or nil if there are none.
+func GetCSPGuardrails(operatorConfig *operatorv1.Console) (*CSPGuardrails, error) {
+	value, ok := operatorConfig.Annotations[api.PluginCSPGuardrailsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	guardrails := &CSPGuardrails{}
+	if err := json.Unmarshal([]byte(value), guardrails); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", api.PluginCSPGuardrailsAnnotation, err)
+	}
+	for directive, patterns := range guardrails.AllowedSources {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid %s annotation: %s pattern %q: %w", api.PluginCSPGuardrailsAnnotation, directive, pattern, err)
+			}
+		}
+	}
+	return guardrails, nil
+}
+
+// Apply returns the plugins with the directives violating the guardrails removed, along
+// with the violations keyed by the plugin name. The plugins are not modified, the ones
+// with violations are copied.
+func (g *CSPGuardrails) Apply(plugins []*consolev1.ConsolePlugin) ([]*consolev1.ConsolePlugin, map[string]error) {
+	result := []*consolev1.ConsolePlugin{}
+	violations := map[string]error{}
+	for _, plugin := range plugins {
+		allowed := []consolev1.ConsolePluginCSP{}
+		messages := []string{}
+		for _, csp := range plugin.Spec.ContentSecurityPolicy {
+			if message := g.check(csp); message != "" {
+				messages = append(messages, message)
+				continue
+			}
+			allowed = append(allowed, csp)
+		}
+		if len(messages) == 0 {
+			result = append(result, plugin)
+			continue
+		}
+		violations[plugin.Name] = fmt.Errorf("CSP directives of %q plugin dropped: %s", plugin.Name, strings.Join(messages, "; "))
+		restricted := plugin.DeepCopy()
+		restricted.Spec.ContentSecurityPolicy = allowed
+		result = append(result, restricted)
+	}
+	return result, violations
+}
+
+// check returns the dropped directive and why it violates the guardrails, or an empty string.
+func (g *CSPGuardrails) check(csp consolev1.ConsolePluginCSP) string {
+	patterns, restricted := g.AllowedSources[csp.Directive]
+	for _, value := range csp.Values {
+		for _, forbidden := range g.ForbiddenValues {
+			if string(value) == forbidden {
+				return fmt.Sprintf("%s, value %q is forbidden", csp.Directive, value)
+			}
+		}
+		if restricted && !matchesAny(patterns, string(value)) {
+			return fmt.Sprintf("%s, value %q is not allowed", csp.Directive, value)
+		}
+	}
+	return ""
+}
+
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
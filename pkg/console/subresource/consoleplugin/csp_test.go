@@ -0,0 +1,91 @@
+package consoleplugin
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestApplyCSPGuardrails(t *testing.T) {
+	plugin := &consolev1.ConsolePlugin{
+		ObjectMeta: metav1.ObjectMeta{Name: "plugin"},
+		Spec: consolev1.ConsolePluginSpec{
+			ContentSecurityPolicy: []consolev1.ConsolePluginCSP{
+				{Directive: consolev1.ScriptSrc, Values: []consolev1.CSPDirectiveValue{"https://cdn.example.com"}},
+				{Directive: consolev1.ConnectSrc, Values: []consolev1.CSPDirectiveValue{"https://api.example.com", "wss://stream.example.org"}},
+				{Directive: consolev1.ImgSrc, Values: []consolev1.CSPDirectiveValue{"data:"}},
+			},
+		},
+	}
+	tests := []struct {
+		name           string
+		annotation     string
+		wantDirectives []consolev1.DirectiveType
+		wantViolation  string
+		wantErr        bool
+	}{
+		{
+			name:           "Directives allowed by the patterns",
+			annotation:     `{"allowedSources": {"ScriptSrc": ["https://*.example.com"], "ConnectSrc": ["https://*.example.com", "wss://*.example.org"]}}`,
+			wantDirectives: []consolev1.DirectiveType{consolev1.ScriptSrc, consolev1.ConnectSrc, consolev1.ImgSrc},
+		},
+		{
+			name:           "Directive with a value not matching the patterns is excluded",
+			annotation:     `{"allowedSources": {"ConnectSrc": ["https://*.example.com"]}}`,
+			wantDirectives: []consolev1.DirectiveType{consolev1.ScriptSrc, consolev1.ImgSrc},
+			wantViolation:  `CSP directives of "plugin" plugin dropped: ConnectSrc, value "wss://stream.example.org" is not allowed`,
+		},
+		{
+			name:           "Directive with a forbidden value is excluded",
+			annotation:     `{"forbiddenValues": ["data:"]}`,
+			wantDirectives: []consolev1.DirectiveType{consolev1.ScriptSrc, consolev1.ConnectSrc},
+			wantViolation:  `CSP directives of "plugin" plugin dropped: ImgSrc, value "data:" is forbidden`,
+		},
+		{
+			name:       "Invalid pattern",
+			annotation: `{"allowedSources": {"ScriptSrc": ["https://[.example.com"]}}`,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{api.PluginCSPGuardrailsAnnotation: tt.annotation},
+				},
+			}
+			guardrails, err := GetCSPGuardrails(operatorConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetCSPGuardrails() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			plugins, violations := guardrails.Apply([]*consolev1.ConsolePlugin{plugin})
+			directives := []consolev1.DirectiveType{}
+			for _, csp := range plugins[0].Spec.ContentSecurityPolicy {
+				directives = append(directives, csp.Directive)
+			}
+			if diff := deep.Equal(directives, tt.wantDirectives); diff != nil {
+				t.Error(diff)
+			}
+			violation := ""
+			if err := violations["plugin"]; err != nil {
+				violation = err.Error()
+			}
+			if diff := deep.Equal(violation, tt.wantViolation); diff != nil {
+				t.Error(diff)
+			}
+			if len(plugin.Spec.ContentSecurityPolicy) != 3 {
+				t.Error("the original plugin was modified")
+			}
+		})
+	}
+}
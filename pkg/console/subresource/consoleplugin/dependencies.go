@@ -0,0 +1,91 @@
+package consoleplugin
+
+import (
+	"fmt"
+	"strings"
+
+	// openshift
+	consolev1 "github.com/openshift/api/console/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// GetDependencies returns the names of the plugins the plugin declares to depend on, as a
+// comma separated list in the console.openshift.io/plugin-dependencies annotation.
+func GetDependencies(plugin *consolev1.ConsolePlugin) []string {
+	return util.RemoveDuplicateStr(splitList(plugin.Annotations[api.PluginDependenciesAnnotation]))
+}
+
+// OrderPlugins returns the names of the available plugins in the order they are enabled,
+// moving every plugin after the plugins it depends on. Plugins with missing dependencies
+// keep their position, plugins in a dependency cycle, or depending on one, are loaded last.
+// Both are returned as errors, the order is usable regardless.
+func OrderPlugins(enabledPluginNames []string, availablePlugins []*consolev1.ConsolePlugin) ([]string, error, error) {
+	pluginsByName := map[string]*consolev1.ConsolePlugin{}
+	for _, plugin := range availablePlugins {
+		if plugin != nil {
+			pluginsByName[plugin.Name] = plugin
+		}
+	}
+	names := []string{}
+	for _, name := range util.RemoveDuplicateStr(enabledPluginNames) {
+		if _, ok := pluginsByName[name]; ok {
+			names = append(names, name)
+		}
+	}
+
+	missing := []string{}
+	dependencies := map[string][]string{}
+	for _, name := range names {
+		for _, dependency := range GetDependencies(pluginsByName[name]) {
+			if _, ok := pluginsByName[dependency]; !ok {
+				missing = append(missing, fmt.Sprintf("%s depends on %s", name, dependency))
+				continue
+			}
+			dependencies[name] = append(dependencies[name], dependency)
+		}
+	}
+
+	ordered := []string{}
+	loaded := map[string]bool{}
+	for len(ordered) < len(names) {
+		next := ""
+		for _, name := range names {
+			if !loaded[name] && allLoaded(dependencies[name], loaded) {
+				next = name
+				break
+			}
+		}
+		if next == "" {
+			break
+		}
+		ordered = append(ordered, next)
+		loaded[next] = true
+	}
+	unresolved := []string{}
+	for _, name := range names {
+		if !loaded[name] {
+			unresolved = append(unresolved, name)
+		}
+	}
+	ordered = append(ordered, unresolved...)
+
+	var missingErr, cycleErr error
+	if len(missing) > 0 {
+		missingErr = fmt.Errorf("plugin dependencies are not enabled or not available: %s", strings.Join(missing, ", "))
+	}
+	if len(unresolved) > 0 {
+		cycleErr = fmt.Errorf("plugin dependencies can't be resolved because of a cycle between: %s", strings.Join(unresolved, ", "))
+	}
+	return ordered, missingErr, cycleErr
+}
+
+func allLoaded(dependencies []string, loaded map[string]bool) bool {
+	for _, dependency := range dependencies {
+		if !loaded[dependency] {
+			return false
+		}
+	}
+	return true
+}
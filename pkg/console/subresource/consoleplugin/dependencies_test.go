@@ -0,0 +1,87 @@
+package consoleplugin
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	consolev1 "github.com/openshift/api/console/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestOrderPlugins(t *testing.T) {
+	pluginWithDependencies := func(name, dependencies string) *consolev1.ConsolePlugin {
+		plugin := &consolev1.ConsolePlugin{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if dependencies != "" {
+			plugin.Annotations = map[string]string{api.PluginDependenciesAnnotation: dependencies}
+		}
+		return plugin
+	}
+	tests := []struct {
+		name             string
+		enabledPlugins   []string
+		availablePlugins []*consolev1.ConsolePlugin
+		wantOrder        []string
+		wantMissing      bool
+		wantCycle        bool
+	}{
+		{
+			name:           "Order of enabled plugins is kept without dependencies",
+			enabledPlugins: []string{"plugin-b", "plugin-a", "plugin-c"},
+			availablePlugins: []*consolev1.ConsolePlugin{
+				pluginWithDependencies("plugin-a", ""),
+				pluginWithDependencies("plugin-b", ""),
+			},
+			wantOrder: []string{"plugin-b", "plugin-a"},
+		},
+		{
+			name:           "Dependencies are loaded first",
+			enabledPlugins: []string{"plugin-c", "plugin-b", "plugin-a"},
+			availablePlugins: []*consolev1.ConsolePlugin{
+				pluginWithDependencies("plugin-a", ""),
+				pluginWithDependencies("plugin-b", "plugin-a"),
+				pluginWithDependencies("plugin-c", "plugin-b, plugin-a"),
+			},
+			wantOrder: []string{"plugin-a", "plugin-b", "plugin-c"},
+		},
+		{
+			name:           "Missing dependency",
+			enabledPlugins: []string{"plugin-b", "plugin-a"},
+			availablePlugins: []*consolev1.ConsolePlugin{
+				pluginWithDependencies("plugin-a", ""),
+				pluginWithDependencies("plugin-b", "plugin-x"),
+			},
+			wantOrder:   []string{"plugin-b", "plugin-a"},
+			wantMissing: true,
+		},
+		{
+			name:           "Dependency cycle",
+			enabledPlugins: []string{"plugin-a", "plugin-b", "plugin-c", "plugin-d"},
+			availablePlugins: []*consolev1.ConsolePlugin{
+				pluginWithDependencies("plugin-a", "plugin-b"),
+				pluginWithDependencies("plugin-b", "plugin-a"),
+				pluginWithDependencies("plugin-c", ""),
+				pluginWithDependencies("plugin-d", "plugin-a"),
+			},
+			wantOrder: []string{"plugin-c", "plugin-a", "plugin-b", "plugin-d"},
+			wantCycle: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, missingErr, cycleErr := OrderPlugins(tt.enabledPlugins, tt.availablePlugins)
+			if diff := deep.Equal(order, tt.wantOrder); diff != nil {
+				t.Error(diff)
+			}
+			if (missingErr != nil) != tt.wantMissing {
+				t.Errorf("missing dependencies error = %v, want %v", missingErr, tt.wantMissing)
+			}
+			if (cycleErr != nil) != tt.wantCycle {
+				t.Errorf("dependency cycle error = %v, want %v", cycleErr, tt.wantCycle)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package consoleplugin
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	// openshift
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// the language the console falls back to, it is validated unless the operator config
+// lists the languages of the cluster
+const defaultI18nLanguage = "en"
+
+// GetI18nLoadType returns how the localization resources of the plugin are loaded. Plugins
+// created through v1alpha1 declare preloading with the console.openshift.io/use-i18n
+// annotation, which is honored until the plugin is migrated to the v1 field.
+func GetI18nLoadType(plugin *consolev1.ConsolePlugin) consolev1.LoadType {
+	switch plugin.Spec.I18n.LoadType {
+	case consolev1.Preload, consolev1.Lazy:
+		return plugin.Spec.I18n.LoadType
+	}
+	if useI18n, err := strconv.ParseBool(plugin.Annotations[api.V1Alpha1PluginI18nAnnotation]); err == nil && useI18n {
+		return consolev1.Preload
+	}
+	return consolev1.Lazy
+}
+
+// MigrateI18nAnnotation moves the v1alpha1 console.openshift.io/use-i18n annotation of the
+// plugin to the v1 i18n load type. It returns nil if the plugin has no annotation, an
+// already set load type is kept.
+func MigrateI18nAnnotation(plugin *consolev1.ConsolePlugin) *consolev1.ConsolePlugin {
+	if _, ok := plugin.Annotations[api.V1Alpha1PluginI18nAnnotation]; !ok {
+		return nil
+	}
+	migrated := plugin.DeepCopy()
+	migrated.Spec.I18n.LoadType = GetI18nLoadType(plugin)
+	delete(migrated.Annotations, api.V1Alpha1PluginI18nAnnotation)
+	return migrated
+}
+
+// GetI18nNamespace returns the i18next namespace of the plugin's localization resources.
+func GetI18nNamespace(pluginName string) string {
+	return fmt.Sprintf("plugin__%s", pluginName)
+}
+
+// GetI18nLanguages returns the languages the localization resources of the preloaded
+// plugins are validated for, listed in the console.openshift.io/plugin-i18n-languages
+// annotation of the operator config.
+func GetI18nLanguages(operatorConfig *operatorv1.Console) []string {
+	languages := splitList(operatorConfig.Annotations[api.PluginI18nLanguagesAnnotation])
+	if len(languages) == 0 {
+		return []string{defaultI18nLanguage}
+	}
+	return languages
+}
+
+// GetLocalesURL returns the URL the console fetches the plugin's localization resource
+// of the language from.
+func GetLocalesURL(backend *consolev1.ConsolePluginService, pluginName, language string) string {
+	localesURL := &url.URL{
+		Scheme: "https",
+		Host:   fmt.Sprintf("%s.%s.svc.cluster.local:%d", backend.Name, backend.Namespace, backend.Port),
+	}
+	return localesURL.JoinPath(backend.BasePath, "locales", language, GetI18nNamespace(pluginName)+".json").String()
+}
+
+// CheckLocales verifies the plugin serves its localization resources for every language.
+func CheckLocales(client *http.Client, plugin *consolev1.ConsolePlugin, languages []string) (string, error) {
+	backend := plugin.Spec.Backend.Service
+	for _, language := range languages {
+		localesURL := GetLocalesURL(backend, plugin.Name, language)
+		resp, err := client.Get(localesURL)
+		if err != nil {
+			return "FailedGetLocales", fmt.Errorf("failed to fetch %s: %v", localesURL, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "MissingLocales", fmt.Errorf("%q plugin preloads its localization resources, but %s returns '%s'", plugin.Name, localesURL, resp.Status)
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,92 @@
+package consoleplugin
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	consolev1 "github.com/openshift/api/console/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetI18nLoadType(t *testing.T) {
+	tests := []struct {
+		name        string
+		loadType    consolev1.LoadType
+		annotations map[string]string
+		want        consolev1.LoadType
+	}{
+		{
+			name:     "Preload",
+			loadType: consolev1.Preload,
+			want:     consolev1.Preload,
+		},
+		{
+			name: "Empty load type is lazy",
+			want: consolev1.Lazy,
+		},
+		{
+			name:        "v1alpha1 annotation preloads",
+			annotations: map[string]string{api.V1Alpha1PluginI18nAnnotation: "true"},
+			want:        consolev1.Preload,
+		},
+		{
+			name:        "Load type takes precedence over the v1alpha1 annotation",
+			loadType:    consolev1.Lazy,
+			annotations: map[string]string{api.V1Alpha1PluginI18nAnnotation: "true"},
+			want:        consolev1.Lazy,
+		},
+		{
+			name:        "Disabled v1alpha1 annotation",
+			annotations: map[string]string{api.V1Alpha1PluginI18nAnnotation: "false"},
+			want:        consolev1.Lazy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &consolev1.ConsolePlugin{
+				ObjectMeta: metav1.ObjectMeta{Name: "plugin", Annotations: tt.annotations},
+				Spec:       consolev1.ConsolePluginSpec{I18n: consolev1.ConsolePluginI18n{LoadType: tt.loadType}},
+			}
+			if diff := deep.Equal(GetI18nLoadType(plugin), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestMigrateI18nAnnotation(t *testing.T) {
+	plugin := &consolev1.ConsolePlugin{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "plugin",
+			Annotations: map[string]string{api.V1Alpha1PluginI18nAnnotation: "true", "other": "value"},
+		},
+	}
+	migrated := MigrateI18nAnnotation(plugin)
+	if migrated == nil {
+		t.Fatal("expected the plugin to be migrated")
+	}
+	if diff := deep.Equal(migrated.Spec.I18n.LoadType, consolev1.Preload); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(migrated.Annotations, map[string]string{"other": "value"}); diff != nil {
+		t.Error(diff)
+	}
+	if _, ok := plugin.Annotations[api.V1Alpha1PluginI18nAnnotation]; !ok {
+		t.Error("the original plugin was modified")
+	}
+	if MigrateI18nAnnotation(migrated) != nil {
+		t.Error("expected the migrated plugin to be left alone")
+	}
+}
+
+func TestGetLocalesURL(t *testing.T) {
+	backend := &consolev1.ConsolePluginService{Name: "svc", Namespace: "ns", Port: 9443, BasePath: "/plugin/"}
+	want := "https://svc.ns.svc.cluster.local:9443/plugin/locales/ja/plugin__my-plugin.json"
+	if diff := deep.Equal(GetLocalesURL(backend, "my-plugin", "ja"), want); diff != nil {
+		t.Error(diff)
+	}
+}
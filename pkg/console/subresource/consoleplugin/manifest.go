@@ -0,0 +1,320 @@
+package consoleplugin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+
+	// kube
+	corev1 "k8s.io/api/core/v1"
+
+	// openshift
+	consolev1 "github.com/openshift/api/console/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+const (
+	// PluginAPIDependency is the manifest dependency declaring the console versions
+	// the plugin was built against, as an npm style semver range.
+	PluginAPIDependency = "@console/pluginAPI"
+
+	pluginManifestFile = "plugin-manifest.json"
+	maxManifestSize    = 1024 * 1024
+)
+
+// Manifest is the part of the plugin-manifest.json the operator is interested in.
+type Manifest struct {
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+}
+
+// GetManifestURL returns the URL the console fetches the plugin manifest from.
+func GetManifestURL(backend *consolev1.ConsolePluginService) string {
+	manifestURL := &url.URL{
+		Scheme: "https",
+		Host:   fmt.Sprintf("%s.%s.svc.cluster.local:%d", backend.Name, backend.Namespace, backend.Port),
+	}
+	return manifestURL.JoinPath(backend.BasePath, pluginManifestFile).String()
+}
+
+// NewServiceCAClient returns a client trusting the service CA, which signs the serving
+// certificates of the plugin backends.
+func NewServiceCAClient(serviceCAConfigMap *corev1.ConfigMap) (*http.Client, error) {
+	caPool := x509.NewCertPool()
+	if ok := caPool.AppendCertsFromPEM([]byte(serviceCAConfigMap.Data["service-ca.crt"])); !ok {
+		return nil, fmt.Errorf("failed to parse %s configmap", api.ServiceCAConfigMapName)
+	}
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			// a new client is created on every sync, don't leave the connections behind
+			DisableKeepAlives: true,
+			TLSClientConfig: &tls.Config{
+				RootCAs: caPool,
+			},
+		},
+	}, nil
+}
+
+// FetchManifest fetches and decodes the plugin manifest.
+func FetchManifest(client *http.Client, manifestURL string) (*Manifest, string, error) {
+	resp, err := client.Get(manifestURL)
+	if err != nil {
+		return nil, "FailedGetManifest", fmt.Errorf("failed to fetch %s: %v", manifestURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "FailedGetManifest", fmt.Errorf("%s returns '%s'", manifestURL, resp.Status)
+	}
+	manifest := &Manifest{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(manifest); err != nil {
+		return nil, "InvalidManifest", fmt.Errorf("failed to decode %s: %v", manifestURL, err)
+	}
+	return manifest, "", nil
+}
+
+// CheckCompatibility verifies the console SDK dependency declared by the manifest is
+// satisfied by the release version. Plugins which don't declare the dependency, as well
+// as development builds without a release version, are considered compatible.
+func CheckCompatibility(manifest *Manifest, releaseVersion string) (string, error) {
+	constraint, ok := manifest.Dependencies[PluginAPIDependency]
+	if !ok {
+		return "", nil
+	}
+	release, err := semver.ParseTolerant(releaseVersion)
+	if err != nil {
+		return "", nil
+	}
+	pluginAPIRange, err := ParsePluginAPIRange(constraint)
+	if err != nil {
+		return "InvalidPluginAPIDependency", fmt.Errorf("%s dependency %q of %q plugin is not a valid range: %v", PluginAPIDependency, constraint, manifest.Name, err)
+	}
+	// nightly and candidate builds carry a prerelease, which would fall outside of
+	// ranges like "~4.16.0", only the release itself matters here
+	release = semver.Version{Major: release.Major, Minor: release.Minor, Patch: release.Patch}
+	if !pluginAPIRange(release) {
+		return "IncompatiblePluginAPI", fmt.Errorf("%q plugin requires console %s, running %s", manifest.Name, constraint, release)
+	}
+	return "", nil
+}
+
+// GetCompatibilityVerdicts returns the verdicts of the plugins checked against the release.
+// The verdicts are kept in the console-plugin-compatibility configmap in the operator
+// namespace, keyed by the plugin name. The value is empty for a compatible plugin, and
+// holds the reason for an incompatible one. Plugins which weren't checked yet are missing.
+func GetCompatibilityVerdicts(configMap *corev1.ConfigMap) map[string]string {
+	verdicts := map[string]string{}
+	if configMap == nil {
+		return verdicts
+	}
+	for pluginName, message := range configMap.Data {
+		verdicts[pluginName] = message
+	}
+	return verdicts
+}
+
+// DefaultCompatibilityConfigMap returns the configmap holding the given verdicts.
+func DefaultCompatibilityConfigMap(verdicts map[string]string) *corev1.ConfigMap {
+	meta := util.SharedMeta()
+	meta.Name = api.PluginCompatibilityConfigMapName
+	meta.Namespace = api.OpenShiftConsoleOperatorNamespace
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: meta,
+		Data:       map[string]string{},
+	}
+	for pluginName, message := range verdicts {
+		configMap.Data[pluginName] = message
+	}
+	return configMap
+}
+
+// ParsePluginAPIRange parses the npm style semver range used by the plugin manifests.
+// Partial versions, x-ranges, hyphen ranges as well as the tilde and caret ranges are
+// expanded to the comparators semver.ParseRange understands.
+func ParsePluginAPIRange(constraint string) (semver.Range, error) {
+	alternatives := []string{}
+	for _, alternative := range strings.Split(constraint, "||") {
+		comparators, err := expandAlternative(strings.Fields(alternative))
+		if err != nil {
+			return nil, err
+		}
+		if len(comparators) == 0 {
+			comparators = append(comparators, ">=0.0.0")
+		}
+		alternatives = append(alternatives, strings.Join(comparators, " "))
+	}
+	return semver.ParseRange(strings.Join(alternatives, " || "))
+}
+
+func expandAlternative(fields []string) ([]string, error) {
+	comparators := []string{}
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		// hyphen range, as in "4.10 - 4.14"
+		if i+2 < len(fields) && fields[i+1] == "-" {
+			lower, err := parsePartialVersion(field)
+			if err != nil {
+				return nil, err
+			}
+			upper, err := parsePartialVersion(fields[i+2])
+			if err != nil {
+				return nil, err
+			}
+			comparators = append(comparators, ">="+lower.version.String())
+			switch upper.parts {
+			case 0:
+			case 3:
+				comparators = append(comparators, "<="+upper.version.String())
+			default:
+				comparators = append(comparators, "<"+upper.bump().String())
+			}
+			i += 2
+			continue
+		}
+		// operator separated from its version, as in ">= 4.12"
+		if strings.Trim(field, comparatorOperators) == "" && i+1 < len(fields) {
+			i++
+			field += fields[i]
+		}
+		expanded, err := expandComparator(field)
+		if err != nil {
+			return nil, err
+		}
+		comparators = append(comparators, expanded)
+	}
+	return comparators, nil
+}
+
+const comparatorOperators = "<>=~^"
+
+func expandComparator(comparator string) (string, error) {
+	operatorEnd := strings.IndexFunc(comparator, func(r rune) bool {
+		return !strings.ContainsRune(comparatorOperators, r)
+	})
+	if operatorEnd == -1 {
+		operatorEnd = len(comparator)
+	}
+	operator := comparator[:operatorEnd]
+	partial, err := parsePartialVersion(comparator[operatorEnd:])
+	if err != nil {
+		return "", err
+	}
+	version := partial.version.String()
+
+	if partial.parts == 0 {
+		switch operator {
+		case "<", ">":
+			// nothing is below or above any version
+			return "<0.0.0", nil
+		default:
+			return ">=0.0.0", nil
+		}
+	}
+	switch operator {
+	case "", "=":
+		if partial.parts == 3 {
+			return version, nil
+		}
+		return fmt.Sprintf(">=%s <%s", version, partial.bump()), nil
+	case ">=", "<":
+		return operator + version, nil
+	case ">":
+		if partial.parts == 3 {
+			return ">" + version, nil
+		}
+		return ">=" + partial.bump().String(), nil
+	case "<=":
+		if partial.parts == 3 {
+			return "<=" + version, nil
+		}
+		return "<" + partial.bump().String(), nil
+	case "~":
+		upper := semver.Version{Major: partial.version.Major, Minor: partial.version.Minor + 1}
+		if partial.parts == 1 {
+			upper = semver.Version{Major: partial.version.Major + 1}
+		}
+		return fmt.Sprintf(">=%s <%s", version, upper), nil
+	case "^":
+		var upper semver.Version
+		switch {
+		case partial.version.Major > 0 || partial.parts == 1:
+			upper = semver.Version{Major: partial.version.Major + 1}
+		case partial.version.Minor > 0 || partial.parts == 2:
+			upper = semver.Version{Minor: partial.version.Minor + 1}
+		default:
+			upper = semver.Version{Patch: partial.version.Patch + 1}
+		}
+		return fmt.Sprintf(">=%s <%s", version, upper), nil
+	}
+	return "", fmt.Errorf("unsupported operator %q in %q", operator, comparator)
+}
+
+// partialVersion is a version which may leave out its trailing parts, as in "4.12"
+// or "4.12.x". The missing parts are filled with zeros.
+type partialVersion struct {
+	version semver.Version
+	// parts is the number of specified version parts, wildcards excluded
+	parts int
+}
+
+// bump returns the first version outside of the partial version, e.g. 4.13.0 for "4.12".
+func (p partialVersion) bump() semver.Version {
+	switch p.parts {
+	case 1:
+		return semver.Version{Major: p.version.Major + 1}
+	case 2:
+		return semver.Version{Major: p.version.Major, Minor: p.version.Minor + 1}
+	}
+	return p.version
+}
+
+func parsePartialVersion(version string) (partialVersion, error) {
+	version = strings.TrimPrefix(version, "v")
+	if isWildcard(version) {
+		return partialVersion{}, nil
+	}
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) == 3 && !isWildcard(parts[2]) {
+		full, err := semver.Parse(version)
+		if err != nil {
+			return partialVersion{}, err
+		}
+		return partialVersion{version: full, parts: 3}, nil
+	}
+	numbers := []uint64{}
+	for _, part := range parts {
+		if isWildcard(part) {
+			break
+		}
+		number, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return partialVersion{}, fmt.Errorf("invalid version %q", version)
+		}
+		numbers = append(numbers, number)
+	}
+	partial := partialVersion{parts: len(numbers)}
+	if len(numbers) > 0 {
+		partial.version.Major = numbers[0]
+	}
+	if len(numbers) > 1 {
+		partial.version.Minor = numbers[1]
+	}
+	return partial, nil
+}
+
+func isWildcard(part string) bool {
+	return part == "" || part == "*" || part == "x" || part == "X"
+}
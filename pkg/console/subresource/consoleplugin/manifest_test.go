@@ -0,0 +1,216 @@
+package consoleplugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	consolev1 "github.com/openshift/api/console/v1"
+)
+
+func TestGetManifestURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend *consolev1.ConsolePluginService
+		want    string
+	}{
+		{
+			name: "Plugin served from the root",
+			backend: &consolev1.ConsolePluginService{
+				Name:      "plugin-service",
+				Namespace: "plugin-ns",
+				Port:      9443,
+			},
+			want: "https://plugin-service.plugin-ns.svc.cluster.local:9443/plugin-manifest.json",
+		},
+		{
+			name: "Plugin served from a base path",
+			backend: &consolev1.ConsolePluginService{
+				Name:      "plugin-service",
+				Namespace: "plugin-ns",
+				Port:      9443,
+				BasePath:  "/plugin/",
+			},
+			want: "https://plugin-service.plugin-ns.svc.cluster.local:9443/plugin/plugin-manifest.json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(GetManifestURL(tt.backend), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestFetchManifest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/valid/plugin-manifest.json":
+			w.Write([]byte(`{"name":"plugin","version":"1.0.0","dependencies":{"@console/pluginAPI":"~4.16.0"}}`))
+		case "/invalid/plugin-manifest.json":
+			w.Write([]byte(`<html></html>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		wantManifest *Manifest
+		wantReason   string
+	}{
+		{
+			name: "Valid manifest",
+			path: "/valid/plugin-manifest.json",
+			wantManifest: &Manifest{
+				Name:         "plugin",
+				Version:      "1.0.0",
+				Dependencies: map[string]string{PluginAPIDependency: "~4.16.0"},
+			},
+		},
+		{
+			name:       "Manifest is not JSON",
+			path:       "/invalid/plugin-manifest.json",
+			wantReason: "InvalidManifest",
+		},
+		{
+			name:       "Manifest is missing",
+			path:       "/missing/plugin-manifest.json",
+			wantReason: "FailedGetManifest",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest, reason, _ := FetchManifest(server.Client(), server.URL+tt.path)
+			if diff := deep.Equal(manifest, tt.wantManifest); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(reason, tt.wantReason); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	tests := []struct {
+		name           string
+		dependency     string
+		releaseVersion string
+		wantReason     string
+	}{
+		{
+			name:           "No dependency declared",
+			releaseVersion: "4.16.0",
+		},
+		{
+			name:           "Tilde range satisfied by a nightly build",
+			dependency:     "~4.16.0",
+			releaseVersion: "4.16.0-0.nightly-2024-05-01-111315",
+		},
+		{
+			name:           "Tilde range not satisfied by the next minor",
+			dependency:     "~4.15.0",
+			releaseVersion: "4.16.0",
+			wantReason:     "IncompatiblePluginAPI",
+		},
+		{
+			name:           "Caret range satisfied by the next minor",
+			dependency:     "^4.15.0",
+			releaseVersion: "4.16.2",
+		},
+		{
+			name:           "Comparators with prerelease bounds",
+			dependency:     ">=4.12.0-0 <4.16.0-0",
+			releaseVersion: "4.16.0",
+			wantReason:     "IncompatiblePluginAPI",
+		},
+		{
+			name:           "One of the alternatives satisfied",
+			dependency:     "~4.14.0 || 4.16.x",
+			releaseVersion: "4.16.1",
+		},
+		{
+			name:           "Partial lower bound",
+			dependency:     ">=4.12",
+			releaseVersion: "4.16.0",
+		},
+		{
+			name:           "Partial lower bound separated from its operator",
+			dependency:     ">= 4.17",
+			releaseVersion: "4.16.0",
+			wantReason:     "IncompatiblePluginAPI",
+		},
+		{
+			name:           "Partial upper bound",
+			dependency:     ">=4.10.0 <5",
+			releaseVersion: "4.16.0",
+		},
+		{
+			name:           "Partial inclusive upper bound",
+			dependency:     "<=4.15",
+			releaseVersion: "4.15.9",
+		},
+		{
+			name:           "X-range not satisfied",
+			dependency:     "4.12.x",
+			releaseVersion: "4.13.0",
+			wantReason:     "IncompatiblePluginAPI",
+		},
+		{
+			name:           "Tilde range on the major version",
+			dependency:     "~4",
+			releaseVersion: "4.16.0",
+		},
+		{
+			name:           "Caret range on a zero version",
+			dependency:     "^0.0.3",
+			releaseVersion: "0.0.4",
+			wantReason:     "IncompatiblePluginAPI",
+		},
+		{
+			name:           "Hyphen range with a partial upper bound",
+			dependency:     "4.10 - 4.16",
+			releaseVersion: "4.16.3",
+		},
+		{
+			name:           "Hyphen range not satisfied",
+			dependency:     "4.10 - 4.15.2",
+			releaseVersion: "4.15.3",
+			wantReason:     "IncompatiblePluginAPI",
+		},
+		{
+			name:           "Any version",
+			dependency:     "*",
+			releaseVersion: "4.16.0",
+		},
+		{
+			name:           "Invalid range",
+			dependency:     "latest",
+			releaseVersion: "4.16.0",
+			wantReason:     "InvalidPluginAPIDependency",
+		},
+		{
+			name:           "Development build without a release version",
+			dependency:     "~4.15.0",
+			releaseVersion: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := &Manifest{Name: "plugin"}
+			if tt.dependency != "" {
+				manifest.Dependencies = map[string]string{PluginAPIDependency: tt.dependency}
+			}
+			reason, _ := CheckCompatibility(manifest, tt.releaseVersion)
+			if diff := deep.Equal(reason, tt.wantReason); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package consoleplugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	// kube
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+
+	// openshift
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// EgressTarget is the pods backing a plugin backend or proxy service, which the console
+// pods need to reach. The pods are either selected by the service, or given by the
+// addresses of the endpoints of a service without a selector.
+type EgressTarget struct {
+	Namespace   string
+	PodSelector map[string]string
+	// CIDRs of the endpoint addresses, set when there is no pod selector
+	CIDRs []string
+	Port  intstr.IntOrString
+}
+
+func (t EgressTarget) key() string {
+	return fmt.Sprintf("%s/%v/%s/%s", t.Namespace, metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: t.PodSelector}), strings.Join(t.CIDRs, ","), t.Port.String())
+}
+
+// GetEgressTarget returns the target of the given service port. The endpoints of services
+// without a selector are matched by their addresses. ExternalName services can't be matched
+// by a policy, since it doesn't support host names.
+func GetEgressTarget(service *corev1.Service, endpointSlices []*discoveryv1.EndpointSlice, port int32) (*EgressTarget, error) {
+	if service.Spec.Type == corev1.ServiceTypeExternalName {
+		return nil, fmt.Errorf("%s/%s service of ExternalName type can't be matched by a network policy", service.Namespace, service.Name)
+	}
+	for _, servicePort := range service.Spec.Ports {
+		if servicePort.Port != port {
+			continue
+		}
+		if len(service.Spec.Selector) == 0 {
+			return getEndpointsEgressTarget(service, endpointSlices, servicePort)
+		}
+		// the policy applies to the pod ports, after the service port is translated
+		targetPort := servicePort.TargetPort
+		if targetPort.Type == intstr.Int && targetPort.IntVal == 0 {
+			targetPort = intstr.FromInt32(servicePort.Port)
+		}
+		return &EgressTarget{
+			Namespace:   service.Namespace,
+			PodSelector: service.Spec.Selector,
+			Port:        targetPort,
+		}, nil
+	}
+	return nil, fmt.Errorf("%s/%s service does not expose port %d", service.Namespace, service.Name, port)
+}
+
+// getEndpointsEgressTarget returns the target of the manually managed endpoints of a service
+// without a selector. The endpoints don't have to be ready, so the policy is in place once
+// they become ready.
+func getEndpointsEgressTarget(service *corev1.Service, endpointSlices []*discoveryv1.EndpointSlice, servicePort corev1.ServicePort) (*EgressTarget, error) {
+	var targetPort *int32
+	cidrs := sets.New[string]()
+	for _, endpointSlice := range endpointSlices {
+		var suffix string
+		switch endpointSlice.AddressType {
+		case discoveryv1.AddressTypeIPv4:
+			suffix = "/32"
+		case discoveryv1.AddressTypeIPv6:
+			suffix = "/128"
+		default:
+			continue
+		}
+		for _, endpointPort := range endpointSlice.Ports {
+			if ptr.Deref(endpointPort.Name, "") != servicePort.Name || endpointPort.Port == nil {
+				continue
+			}
+			targetPort = endpointPort.Port
+			for _, endpoint := range endpointSlice.Endpoints {
+				for _, address := range endpoint.Addresses {
+					cidrs.Insert(address + suffix)
+				}
+			}
+		}
+	}
+	if targetPort == nil || cidrs.Len() == 0 {
+		return nil, fmt.Errorf("%s/%s service has neither a pod selector nor endpoints for port %d", service.Namespace, service.Name, servicePort.Port)
+	}
+	return &EgressTarget{
+		Namespace: service.Namespace,
+		CIDRs:     sets.List(cidrs),
+		Port:      intstr.FromInt32(*targetPort),
+	}, nil
+}
+
+// DefaultEgressNetworkPolicy returns the policy allowing the console pods to reach the
+// given targets, in addition to the static policies of the openshift-console namespace.
+func DefaultEgressNetworkPolicy(operatorConfig *operatorv1.Console, targets []EgressTarget) *networkingv1.NetworkPolicy {
+	meta := util.SharedMeta()
+	meta.Name = api.PluginEgressNetworkPolicyName
+	networkPolicy := &networkingv1.NetworkPolicy{
+		ObjectMeta: meta,
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: util.LabelsForConsole(),
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress:      []networkingv1.NetworkPolicyEgressRule{},
+		},
+	}
+
+	// sort and deduplicate the targets, so the policy is only updated when they change
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].key() < targets[j].key()
+	})
+	seen := map[string]bool{}
+	for _, target := range targets {
+		if seen[target.key()] {
+			continue
+		}
+		seen[target.key()] = true
+
+		protocol := corev1.ProtocolTCP
+		port := target.Port
+		networkPolicy.Spec.Egress = append(networkPolicy.Spec.Egress, networkingv1.NetworkPolicyEgressRule{
+			To: getEgressPeers(target),
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &protocol, Port: &port},
+			},
+		})
+	}
+	util.AddOwnerRef(networkPolicy, util.OwnerRefFrom(operatorConfig))
+	return networkPolicy
+}
+
+func getEgressPeers(target EgressTarget) []networkingv1.NetworkPolicyPeer {
+	if len(target.PodSelector) == 0 {
+		peers := []networkingv1.NetworkPolicyPeer{}
+		for _, cidr := range target.CIDRs {
+			peers = append(peers, networkingv1.NetworkPolicyPeer{
+				IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+			})
+		}
+		return peers
+	}
+	return []networkingv1.NetworkPolicyPeer{
+		{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{corev1.LabelMetadataName: target.Namespace},
+			},
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: target.PodSelector,
+			},
+		},
+	}
+}
@@ -0,0 +1,160 @@
+package consoleplugin
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetEgressTarget(t *testing.T) {
+	selector := map[string]string{"app": "plugin"}
+	manualEndpoints := []*discoveryv1.EndpointSlice{
+		{
+			ObjectMeta:  metav1.ObjectMeta{Name: "plugin-ipv4", Namespace: "plugin-ns"},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Ports:       []discoveryv1.EndpointPort{{Name: ptr.To("https"), Port: ptr.To[int32](8443)}},
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.2"}},
+				{Addresses: []string{"10.0.0.1"}},
+			},
+		},
+		{
+			ObjectMeta:  metav1.ObjectMeta{Name: "plugin-ipv6", Namespace: "plugin-ns"},
+			AddressType: discoveryv1.AddressTypeIPv6,
+			Ports:       []discoveryv1.EndpointPort{{Name: ptr.To("https"), Port: ptr.To[int32](8443)}},
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"fd00::1"}}},
+		},
+	}
+	tests := []struct {
+		name           string
+		service        *corev1.Service
+		endpointSlices []*discoveryv1.EndpointSlice
+		port           int32
+		want           *EgressTarget
+		wantErr        bool
+	}{
+		{
+			name: "Target port",
+			service: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "plugin", Namespace: "plugin-ns"},
+				Spec: corev1.ServiceSpec{
+					Selector: selector,
+					Ports:    []corev1.ServicePort{{Port: 443, TargetPort: intstr.FromString("https")}},
+				},
+			},
+			port: 443,
+			want: &EgressTarget{Namespace: "plugin-ns", PodSelector: selector, Port: intstr.FromString("https")},
+		},
+		{
+			name: "Service port when the target port is not set",
+			service: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "plugin", Namespace: "plugin-ns"},
+				Spec: corev1.ServiceSpec{
+					Selector: selector,
+					Ports:    []corev1.ServicePort{{Port: 9443}},
+				},
+			},
+			port: 9443,
+			want: &EgressTarget{Namespace: "plugin-ns", PodSelector: selector, Port: intstr.FromInt32(9443)},
+		},
+		{
+			name: "Port not exposed",
+			service: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "plugin", Namespace: "plugin-ns"},
+				Spec: corev1.ServiceSpec{
+					Selector: selector,
+					Ports:    []corev1.ServicePort{{Port: 9443}},
+				},
+			},
+			port:    443,
+			wantErr: true,
+		},
+		{
+			name: "Service without a selector",
+			service: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "plugin", Namespace: "plugin-ns"},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "https", Port: 443}},
+				},
+			},
+			endpointSlices: manualEndpoints,
+			port:           443,
+			want:           &EgressTarget{Namespace: "plugin-ns", CIDRs: []string{"10.0.0.1/32", "10.0.0.2/32", "fd00::1/128"}, Port: intstr.FromInt32(8443)},
+		},
+		{
+			name: "Service without a selector nor endpoints",
+			service: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "plugin", Namespace: "plugin-ns"},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Port: 443}},
+				},
+			},
+			endpointSlices: manualEndpoints,
+			port:           443,
+			wantErr:        true,
+		},
+		{
+			name: "ExternalName service",
+			service: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "plugin", Namespace: "plugin-ns"},
+				Spec: corev1.ServiceSpec{
+					Type:         corev1.ServiceTypeExternalName,
+					ExternalName: "plugin.example.com",
+					Ports:        []corev1.ServicePort{{Port: 443}},
+				},
+			},
+			port:    443,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetEgressTarget(tt.service, tt.endpointSlices, tt.port)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetEgressTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestDefaultEgressNetworkPolicy(t *testing.T) {
+	operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Name: api.ConfigResourceName}}
+	targets := []EgressTarget{
+		{Namespace: "plugin-b", PodSelector: map[string]string{"app": "b"}, Port: intstr.FromInt32(9443)},
+		{Namespace: "plugin-a", PodSelector: map[string]string{"app": "a"}, Port: intstr.FromInt32(9443)},
+		{Namespace: "plugin-b", PodSelector: map[string]string{"app": "b"}, Port: intstr.FromInt32(9443)},
+		{Namespace: "plugin-c", CIDRs: []string{"10.0.0.1/32"}, Port: intstr.FromInt32(8443)},
+	}
+	networkPolicy := DefaultEgressNetworkPolicy(operatorConfig, targets)
+
+	if networkPolicy.Name != api.PluginEgressNetworkPolicyName || networkPolicy.Namespace != api.OpenShiftConsoleNamespace {
+		t.Errorf("unexpected policy %s/%s", networkPolicy.Namespace, networkPolicy.Name)
+	}
+	namespaces := []string{}
+	for _, rule := range networkPolicy.Spec.Egress[:2] {
+		namespaces = append(namespaces, rule.To[0].NamespaceSelector.MatchLabels[corev1.LabelMetadataName])
+	}
+	if diff := deep.Equal(namespaces, []string{"plugin-a", "plugin-b"}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(networkPolicy.Spec.Egress[2].To, []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.1/32"}}}); diff != nil {
+		t.Error(diff)
+	}
+	if len(networkPolicy.OwnerReferences) != 1 {
+		t.Errorf("expected an owner reference, got %v", networkPolicy.OwnerReferences)
+	}
+}
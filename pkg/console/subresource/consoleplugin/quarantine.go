@@ -0,0 +1,83 @@
+package consoleplugin
+
+import (
+	"encoding/json"
+	"sort"
+
+	// kube
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	// openshift
+	consolev1 "github.com/openshift/api/console/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// QuarantineRecord describes why a plugin was removed from the console config. The records
+// are kept in the console-plugin-quarantine configmap in the operator namespace, keyed by
+// the plugin name. Admins clear the quarantine of a plugin by removing its key, the quarantine
+// of a plugin whose backend was unreachable is also lifted once the backend recovers.
+type QuarantineRecord struct {
+	Reason  string      `json:"reason"`
+	Message string      `json:"message"`
+	Since   metav1.Time `json:"since"`
+}
+
+// GetQuarantineRecords returns the quarantined plugins. Entries which can't be decoded,
+// for example added by hand, still quarantine the plugin.
+func GetQuarantineRecords(configMap *corev1.ConfigMap) map[string]QuarantineRecord {
+	records := map[string]QuarantineRecord{}
+	if configMap == nil {
+		return records
+	}
+	for pluginName, value := range configMap.Data {
+		record := QuarantineRecord{}
+		if err := json.Unmarshal([]byte(value), &record); err != nil {
+			record = QuarantineRecord{Reason: "Unknown", Message: value}
+		}
+		records[pluginName] = record
+	}
+	return records
+}
+
+// DefaultQuarantineConfigMap returns the configmap holding the given quarantine records.
+func DefaultQuarantineConfigMap(records map[string]QuarantineRecord) (*corev1.ConfigMap, error) {
+	meta := util.SharedMeta()
+	meta.Name = api.PluginQuarantineConfigMapName
+	meta.Namespace = api.OpenShiftConsoleOperatorNamespace
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: meta,
+		Data:       map[string]string{},
+	}
+	for pluginName, record := range records {
+		value, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+		configMap.Data[pluginName] = string(value)
+	}
+	return configMap, nil
+}
+
+// RemoveQuarantinedPlugins returns the plugins which are not quarantined.
+func RemoveQuarantinedPlugins(plugins []*consolev1.ConsolePlugin, records map[string]QuarantineRecord) []*consolev1.ConsolePlugin {
+	remaining := []*consolev1.ConsolePlugin{}
+	for _, plugin := range plugins {
+		if _, quarantined := records[plugin.Name]; !quarantined {
+			remaining = append(remaining, plugin)
+		}
+	}
+	return remaining
+}
+
+// QuarantinedPluginNames returns the sorted names of the quarantined plugins.
+func QuarantinedPluginNames(records map[string]QuarantineRecord) []string {
+	names := []string{}
+	for pluginName := range records {
+		names = append(names, pluginName)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,24 @@
+package consoleplugin
+
+import (
+	consolev1 "github.com/openshift/api/console/v1"
+)
+
+// GetPluginServices returns the backend and proxy services of the plugin. Backends and
+// proxies of other types are not supported by the console.
+func GetPluginServices(plugin *consolev1.ConsolePlugin) []consolev1.ConsolePluginProxyServiceConfig {
+	services := []consolev1.ConsolePluginProxyServiceConfig{}
+	if backend := plugin.Spec.Backend.Service; plugin.Spec.Backend.Type == consolev1.Service && backend != nil {
+		services = append(services, consolev1.ConsolePluginProxyServiceConfig{
+			Name:      backend.Name,
+			Namespace: backend.Namespace,
+			Port:      backend.Port,
+		})
+	}
+	for _, proxy := range plugin.Spec.Proxy {
+		if proxy.Endpoint.Type == consolev1.ProxyTypeService && proxy.Endpoint.Service != nil {
+			services = append(services, *proxy.Endpoint.Service)
+		}
+	}
+	return services
+}
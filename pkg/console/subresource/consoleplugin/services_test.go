@@ -0,0 +1,52 @@
+package consoleplugin
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	consolev1 "github.com/openshift/api/console/v1"
+)
+
+func TestGetPluginServices(t *testing.T) {
+	tests := []struct {
+		name string
+		spec consolev1.ConsolePluginSpec
+		want []consolev1.ConsolePluginProxyServiceConfig
+	}{
+		{
+			name: "Backend and proxy services",
+			spec: consolev1.ConsolePluginSpec{
+				Backend: consolev1.ConsolePluginBackend{
+					Type:    consolev1.Service,
+					Service: &consolev1.ConsolePluginService{Name: "plugin", Namespace: "plugin-ns", Port: 9443},
+				},
+				Proxy: []consolev1.ConsolePluginProxy{{
+					Alias: "api",
+					Endpoint: consolev1.ConsolePluginProxyEndpoint{
+						Type:    consolev1.ProxyTypeService,
+						Service: &consolev1.ConsolePluginProxyServiceConfig{Name: "api", Namespace: "api-ns", Port: 8443},
+					},
+				}},
+			},
+			want: []consolev1.ConsolePluginProxyServiceConfig{
+				{Name: "plugin", Namespace: "plugin-ns", Port: 9443},
+				{Name: "api", Namespace: "api-ns", Port: 8443},
+			},
+		},
+		{
+			name: "Unsupported backend type",
+			spec: consolev1.ConsolePluginSpec{
+				Backend: consolev1.ConsolePluginBackend{Type: "Unknown"},
+			},
+			want: []consolev1.ConsolePluginProxyServiceConfig{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(GetPluginServices(&consolev1.ConsolePlugin{Spec: tt.spec}), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -7,12 +7,10 @@ import (
 	"github.com/go-test/deep"
 	"github.com/google/go-cmp/cmp"
 	configv1 "github.com/openshift/api/config/v1"
-	consolev1 "github.com/openshift/api/console/v1"
 	v1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
 	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // defaultTestCapabilities represents the default capabilities as defined in the operator manifest
@@ -916,21 +914,14 @@ func TestConsoleServerCLIConfigBuilder(t *testing.T) {
 			},
 		},
 		{
-			name: "Config builder should set plugins order from available plugins",
+			name: "Config builder should set plugins order",
 			input: func() Config {
 				b := &ConsoleServerCLIConfigBuilder{}
 				b.Plugins(map[string]string{
 					"plugin1": "plugin1_url",
 					"plugin2": "plugin2_url",
 				}).
-					PluginsOrder([]*consolev1.ConsolePlugin{
-						{ObjectMeta: metav1.ObjectMeta{Name: "plugin1"}},
-						{ObjectMeta: metav1.ObjectMeta{Name: "plugin2"}},
-					}, &v1.Console{
-						Spec: v1.ConsoleSpec{
-							Plugins: []string{"plugin1", "plugin2", "plugin3"},
-						},
-					})
+					PluginsOrder([]string{"plugin1", "plugin2"})
 				return b.Config()
 			},
 			output: Config{
@@ -0,0 +1,91 @@
+package consoleserver
+
+import (
+	"fmt"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// ImpersonationState defines who can use the console's user impersonation.
+// "Enabled" means everyone with the impersonate permission.
+// "Disabled" means no one.
+// "Restricted" means members of the allowed groups with the impersonate permission.
+type ImpersonationState string
+
+const (
+	ImpersonationEnabled    ImpersonationState = "Enabled"
+	ImpersonationDisabled   ImpersonationState = "Disabled"
+	ImpersonationRestricted ImpersonationState = "Restricted"
+)
+
+// Impersonation contains options for the console's user impersonation.
+type Impersonation struct {
+	// State defines who can impersonate from the console.
+	State ImpersonationState
+	// AllowedGroups is the list of groups whose members can impersonate when the state is Restricted.
+	AllowedGroups []string
+	// AuditAnnotation is the key of the extra user info holding the reason users give when they
+	// start impersonating. The console sends it along with the impersonation headers, so the API
+	// server records it in the audit log. When set, the console requires a reason.
+	AuditAnnotation string
+}
+
+// GetImpersonation returns the impersonation config of the console. It is set through
+// annotations on the operator config:
+//   - console.openshift.io/impersonation, one of Enabled, Disabled or Restricted
+//   - console.openshift.io/impersonation-groups, the comma separated groups allowed to
+//     impersonate when Restricted
+//   - console.openshift.io/impersonation-audit-annotation, the key of the extra user info the
+//     console records the impersonation reason under, e.g. console.openshift.io/impersonation-reason
+//
+// nil is returned when none of them is set, leaving impersonation to the console default.
+// Invalid values are returned as an error and disable impersonation until they are fixed, so
+// a typo can't lift a restriction.
+func GetImpersonation(operatorConfig *operatorv1.Console) (*Impersonation, error) {
+	state, hasState := operatorConfig.Annotations[api.ImpersonationAnnotation]
+	groups, hasGroups := operatorConfig.Annotations[api.ImpersonationGroupsAnnotation]
+	auditAnnotation, hasAuditAnnotation := operatorConfig.Annotations[api.ImpersonationAuditAnnotation]
+	if !hasState && !hasGroups && !hasAuditAnnotation {
+		return nil, nil
+	}
+
+	impersonation := &Impersonation{State: ImpersonationEnabled}
+	messages := []string{}
+	if hasState {
+		switch impersonationState := ImpersonationState(state); impersonationState {
+		case ImpersonationEnabled, ImpersonationDisabled, ImpersonationRestricted:
+			impersonation.State = impersonationState
+		default:
+			messages = append(messages, fmt.Sprintf("%s %q: must be one of %s, %s or %s", api.ImpersonationAnnotation, state, ImpersonationEnabled, ImpersonationDisabled, ImpersonationRestricted))
+		}
+	}
+	for _, group := range strings.Split(groups, ",") {
+		if group = strings.TrimSpace(group); len(group) > 0 {
+			impersonation.AllowedGroups = append(impersonation.AllowedGroups, group)
+		}
+	}
+	switch {
+	case impersonation.State == ImpersonationRestricted && len(impersonation.AllowedGroups) == 0:
+		messages = append(messages, fmt.Sprintf("%s is required when %s is %s", api.ImpersonationGroupsAnnotation, api.ImpersonationAnnotation, ImpersonationRestricted))
+	case impersonation.State != ImpersonationRestricted && hasGroups:
+		messages = append(messages, fmt.Sprintf("%s only applies when %s is %s", api.ImpersonationGroupsAnnotation, api.ImpersonationAnnotation, ImpersonationRestricted))
+	}
+	if hasAuditAnnotation {
+		if errs := validation.IsQualifiedName(auditAnnotation); len(errs) > 0 {
+			messages = append(messages, fmt.Sprintf("%s %q: %s", api.ImpersonationAuditAnnotation, auditAnnotation, strings.Join(errs, ", ")))
+		} else if auditAnnotation != strings.ToLower(auditAnnotation) {
+			// the API server lowercases the keys of the extra user info
+			messages = append(messages, fmt.Sprintf("%s %q: must be lowercase", api.ImpersonationAuditAnnotation, auditAnnotation))
+		} else {
+			impersonation.AuditAnnotation = auditAnnotation
+		}
+	}
+	if len(messages) > 0 {
+		return &Impersonation{State: ImpersonationDisabled}, fmt.Errorf("invalid console impersonation config: %s", strings.Join(messages, ", "))
+	}
+	return impersonation, nil
+}
@@ -0,0 +1,86 @@
+package consoleserver
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetImpersonation(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *Impersonation
+		wantErr     bool
+	}{
+		{
+			name: "Not configured",
+		},
+		{
+			name:        "Disabled",
+			annotations: map[string]string{api.ImpersonationAnnotation: "Disabled"},
+			want:        &Impersonation{State: ImpersonationDisabled},
+		},
+		{
+			name: "Restricted to groups with an audit annotation",
+			annotations: map[string]string{
+				api.ImpersonationAnnotation:       "Restricted",
+				api.ImpersonationGroupsAnnotation: "cluster-admins, support,",
+				api.ImpersonationAuditAnnotation:  "console.openshift.io/impersonation-reason",
+			},
+			want: &Impersonation{
+				State:           ImpersonationRestricted,
+				AllowedGroups:   []string{"cluster-admins", "support"},
+				AuditAnnotation: "console.openshift.io/impersonation-reason",
+			},
+		},
+		{
+			name:        "Audit annotation only",
+			annotations: map[string]string{api.ImpersonationAuditAnnotation: "reason"},
+			want:        &Impersonation{State: ImpersonationEnabled, AuditAnnotation: "reason"},
+		},
+		{
+			name:        "Invalid state disables impersonation",
+			annotations: map[string]string{api.ImpersonationAnnotation: "Admins"},
+			want:        &Impersonation{State: ImpersonationDisabled},
+			wantErr:     true,
+		},
+		{
+			name:        "Restricted without groups disables impersonation",
+			annotations: map[string]string{api.ImpersonationAnnotation: "Restricted"},
+			want:        &Impersonation{State: ImpersonationDisabled},
+			wantErr:     true,
+		},
+		{
+			name:        "Groups without the restriction disable impersonation",
+			annotations: map[string]string{api.ImpersonationGroupsAnnotation: "cluster-admins"},
+			want:        &Impersonation{State: ImpersonationDisabled},
+			wantErr:     true,
+		},
+		{
+			name: "Invalid audit annotation disables impersonation",
+			annotations: map[string]string{
+				api.ImpersonationAnnotation:      "Enabled",
+				api.ImpersonationAuditAnnotation: "Impersonation-Reason",
+			},
+			want:    &Impersonation{State: ImpersonationDisabled},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			got, err := GetImpersonation(operatorConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package deployment
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"slices"
 
@@ -29,12 +31,13 @@ const (
 )
 
 const (
-	configMapResourceVersionAnnotation             = "console.openshift.io/console-config-version"
-	proxyConfigResourceVersionAnnotation           = "console.openshift.io/proxy-config-version"
-	infrastructureConfigResourceVersionAnnotation  = "console.openshift.io/infrastructure-config-version"
-	serviceCAConfigMapResourceVersionAnnotation    = "console.openshift.io/service-ca-config-version"
-	trustedCAConfigMapResourceVersionAnnotation    = "console.openshift.io/trusted-ca-config-version"
-	secretResourceVersionAnnotation                = "console.openshift.io/oauth-secret-version"
+	configMapResourceVersionAnnotation            = "console.openshift.io/console-config-version"
+	proxyConfigResourceVersionAnnotation          = "console.openshift.io/proxy-config-version"
+	infrastructureConfigResourceVersionAnnotation = "console.openshift.io/infrastructure-config-version"
+	serviceCAConfigMapResourceVersionAnnotation   = "console.openshift.io/service-ca-config-version"
+	trustedCAConfigMapResourceVersionAnnotation   = "console.openshift.io/trusted-ca-config-version"
+	// SecretResourceVersionAnnotation holds the version of the client secrets the console is rolled out with
+	SecretResourceVersionAnnotation                = "console.openshift.io/oauth-secret-version"
 	consoleImageAnnotation                         = "console.openshift.io/image"
 	authnConfigVersionAnnotation                   = "console.openshift.io/authentication-config-version"
 	authnCATrustConfigMapResourceVersionAnnotation = "console.openshift.io/authn-ca-trust-config-version"
@@ -50,7 +53,7 @@ var (
 		serviceCAConfigMapResourceVersionAnnotation,
 		authnCATrustConfigMapResourceVersionAnnotation,
 		trustedCAConfigMapResourceVersionAnnotation,
-		secretResourceVersionAnnotation,
+		SecretResourceVersionAnnotation,
 		consoleImageAnnotation,
 		servingCertSecretResourceVersionAnnotation,
 	}
@@ -220,7 +223,7 @@ func withConsoleAnnotations(
 		trustedCAConfigMapResourceVersionAnnotation:   trustedCAConfigMap.GetResourceVersion(),
 		proxyConfigResourceVersionAnnotation:          proxyConfig.GetResourceVersion(),
 		infrastructureConfigResourceVersionAnnotation: infrastructureConfig.GetResourceVersion(),
-		secretResourceVersionAnnotation:               oAuthClientSecret.GetResourceVersion(),
+		SecretResourceVersionAnnotation:               GetClientSecretVersion(oAuthClientSecret),
 		consoleImageAnnotation:                        util.GetImageEnv("CONSOLE_IMAGE"),
 		servingCertSecretResourceVersionAnnotation:    consoleServingCertSecret.GetResourceVersion(),
 	}
@@ -491,6 +494,42 @@ func setEnvironmentVariables(proxyConfig *configv1.Proxy) []corev1.EnvVar {
 	return envVars
 }
 
+// GetClientSecretVersion returns a hash of the client secrets the console logs in with. Unlike the
+// resource version of the secret it doesn't change when only the metadata of the secret, the pending
+// or the previous client secret change, which don't require the console to be rolled out again.
+func GetClientSecretVersion(clientSecret *corev1.Secret) string {
+	keys := []string{}
+	for key := range clientSecret.Data {
+		if key != api.PendingClientSecretKey && key != api.PreviousClientSecretKey {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	slices.Sort(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write(clientSecret.Data[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// GetAuthServerCAConfigMapName returns the name of the OIDC provider's CA configmap the deployment
+// mounts, empty if it doesn't mount one.
+func GetAuthServerCAConfigMapName(deployment *appsv1.Deployment) string {
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		for _, volumeMount := range container.VolumeMounts {
+			if volumeMount.MountPath == api.AuthServerCAMountDir {
+				return volumeMount.Name
+			}
+		}
+	}
+	return ""
+}
+
 func IsAvailable(deployment *appsv1.Deployment) bool {
 	avail := deployment.Status.AvailableReplicas > 0
 	if !avail {
@@ -76,7 +76,7 @@ func TestDefaultDeployment(t *testing.T) {
 		Labels:                     labels,
 		Annotations: map[string]string{
 			configMapResourceVersionAnnotation:             "",
-			secretResourceVersionAnnotation:                "",
+			SecretResourceVersionAnnotation:                "",
 			authnCATrustConfigMapResourceVersionAnnotation: "",
 			serviceCAConfigMapResourceVersionAnnotation:    "",
 			trustedCAConfigMapResourceVersionAnnotation:    "",
@@ -131,7 +131,7 @@ func TestDefaultDeployment(t *testing.T) {
 
 	consoleDeploymentTemplateAnnotations := map[string]string{
 		configMapResourceVersionAnnotation:             "",
-		secretResourceVersionAnnotation:                "",
+		SecretResourceVersionAnnotation:                "",
 		authnCATrustConfigMapResourceVersionAnnotation: "",
 		serviceCAConfigMapResourceVersionAnnotation:    "",
 		trustedCAConfigMapResourceVersionAnnotation:    "",
@@ -650,7 +650,7 @@ func TestWithConsoleAnnotations(t *testing.T) {
 						trustedCAConfigMapResourceVersionAnnotation:    trustedCAConfigMap.GetResourceVersion(),
 						proxyConfigResourceVersionAnnotation:           proxyConfig.GetResourceVersion(),
 						infrastructureConfigResourceVersionAnnotation:  infrastructureConfig.GetResourceVersion(),
-						secretResourceVersionAnnotation:                oAuthClientSecret.GetResourceVersion(),
+						SecretResourceVersionAnnotation:                GetClientSecretVersion(oAuthClientSecret),
 						consoleImageAnnotation:                         util.GetImageEnv("CONSOLE_IMAGE"),
 						servingCertSecretResourceVersionAnnotation:     consoleServingCertSecret.GetResourceVersion(),
 					},
@@ -666,7 +666,7 @@ func TestWithConsoleAnnotations(t *testing.T) {
 								trustedCAConfigMapResourceVersionAnnotation:    trustedCAConfigMap.GetResourceVersion(),
 								proxyConfigResourceVersionAnnotation:           proxyConfig.GetResourceVersion(),
 								infrastructureConfigResourceVersionAnnotation:  infrastructureConfig.GetResourceVersion(),
-								secretResourceVersionAnnotation:                oAuthClientSecret.GetResourceVersion(),
+								SecretResourceVersionAnnotation:                GetClientSecretVersion(oAuthClientSecret),
 								consoleImageAnnotation:                         util.GetImageEnv("CONSOLE_IMAGE"),
 								servingCertSecretResourceVersionAnnotation:     consoleServingCertSecret.GetResourceVersion(),
 							},
@@ -1148,6 +1148,7 @@ func TestWithConsoleVolumes(t *testing.T) {
 		serviceCAVolume,
 		tmpVolume,
 	}
+
 	trustedVolumes := append(defaultVolumes, trustedCAVolume)
 	customLogoVolumes := append(defaultVolumes, customLogoVolume)
 	allVolumes := append(defaultVolumes, trustedCAVolume, customLogoVolume)
@@ -1214,6 +1215,7 @@ func TestWithConsoleVolumes(t *testing.T) {
 		serviceCAVolumeMount,
 		tmpVolumeMount,
 	}
+
 	trustedVolumeMounts := append(defaultVolumeMounts, trustedCAVolumeMount)
 	customLogoVolumeMounts := append(defaultVolumeMounts, customLogoVolumeMount)
 	allVolumeMounts := append(defaultVolumeMounts, trustedCAVolumeMount, customLogoVolumeMount)
@@ -2200,6 +2202,32 @@ func TestIsAvailableAndUpdated(t *testing.T) {
 
 }
 
+func TestGetClientSecretVersion(t *testing.T) {
+	secret := func(data map[string]string) *corev1.Secret {
+		secret := &corev1.Secret{Data: map[string][]byte{}}
+		for key, value := range data {
+			secret.Data[key] = []byte(value)
+		}
+		return secret
+	}
+	current := GetClientSecretVersion(secret(map[string]string{"clientSecret": "current"}))
+
+	if got := GetClientSecretVersion(secret(nil)); got != "" {
+		t.Errorf("GetClientSecretVersion() of an empty secret = %q, want an empty string", got)
+	}
+	updated := secret(map[string]string{"clientSecret": "current"})
+	updated.ResourceVersion = "2"
+	if got := GetClientSecretVersion(updated); got != current {
+		t.Errorf("GetClientSecretVersion() changed by the resource version")
+	}
+	if got := GetClientSecretVersion(secret(map[string]string{"clientSecret": "rotated"})); got == current {
+		t.Errorf("GetClientSecretVersion() unchanged by the rotated client secret")
+	}
+	if got := GetClientSecretVersion(secret(map[string]string{"clientSecret": "current", api.PreviousClientSecretKey: "previous", api.PendingClientSecretKey: "pending"})); got != current {
+		t.Errorf("GetClientSecretVersion() changed by the previous or pending client secret")
+	}
+}
+
 func infrastructureConfigWithTopology(controlPlaneTopologyMode, infrastructureTopologyMode configv1.TopologyMode) *configv1.Infrastructure {
 	return &configv1.Infrastructure{
 		TypeMeta:   metav1.TypeMeta{},
@@ -2,12 +2,21 @@ package oauthclient
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
+	configv1 "github.com/openshift/api/config/v1"
 	oauthv1 "github.com/openshift/api/oauth/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
 	oauthclient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
 
@@ -34,11 +43,17 @@ func CustomApplyOAuth(client oauthclient.OAuthClientsGetter, required *oauthv1.O
 	// tedious to manually copy things over
 	modified := resourcemerge.BoolPtr(false)
 	resourcemerge.EnsureObjectMeta(modified, &existing.ObjectMeta, required.ObjectMeta)
-	// at present, we only care about these two fields. this is NOT generic to all oauth clients
+	// at present, we only care about these fields. this is NOT generic to all oauth clients
 	secretSame := equality.Semantic.DeepEqual(existing.Secret, required.Secret)
 	redirectsSame := equality.Semantic.DeepEqual(existing.RedirectURIs, required.RedirectURIs)
+	// the grant method and the token lifetimes are only managed when they are configured, or
+	// were configured before and are reset
+	managedFields := GetManagedTokenConfigFields(required).Union(GetManagedTokenConfigFields(existing))
+	grantMethodSame := !managedFields.Has(TokenConfigGrantMethod) || existing.GrantMethod == required.GrantMethod
+	tokenLifetimesSame := (!managedFields.Has(TokenConfigAccessTokenMaxAge) || equality.Semantic.DeepEqual(existing.AccessTokenMaxAgeSeconds, required.AccessTokenMaxAgeSeconds)) &&
+		(!managedFields.Has(TokenConfigAccessTokenInactivityTimeout) || equality.Semantic.DeepEqual(existing.AccessTokenInactivityTimeoutSeconds, required.AccessTokenInactivityTimeoutSeconds))
 	// nothing changed, so don't update
-	if secretSame && redirectsSame && !*modified {
+	if secretSame && redirectsSame && grantMethodSame && tokenLifetimesSame && !*modified {
 		// per ApplyService, etc, if nothing changed, return nil.
 		return nil, false, nil
 	}
@@ -46,10 +61,16 @@ func CustomApplyOAuth(client oauthclient.OAuthClientsGetter, required *oauthv1.O
 	// existing.AdditionalSecrets = required.AdditionalSecrets
 	// existing.RespondWithChallenges = required.RespondWithChallenges
 	existing.RedirectURIs = required.RedirectURIs
-	// existing.GrantMethod = required.GrantMethod
+	if managedFields.Has(TokenConfigGrantMethod) {
+		existing.GrantMethod = required.GrantMethod
+	}
 	// existing.ScopeRestrictions = required.ScopeRestrictions
-	// existing.AccessTokenMaxAgeSeconds = required.AccessTokenMaxAgeSeconds
-	// existing.AccessTokenInactivityTimeoutSeconds = required.AccessTokenInactivityTimeoutSeconds
+	if managedFields.Has(TokenConfigAccessTokenMaxAge) {
+		existing.AccessTokenMaxAgeSeconds = required.AccessTokenMaxAgeSeconds
+	}
+	if managedFields.Has(TokenConfigAccessTokenInactivityTimeout) {
+		existing.AccessTokenInactivityTimeoutSeconds = required.AccessTokenInactivityTimeoutSeconds
+	}
 	actual, err := client.OAuthClients().Update(ctx, existing, metav1.UpdateOptions{})
 	return actual, true, err
 }
@@ -108,3 +129,256 @@ func SetRedirectURI(client *oauthv1.OAuthClient, host string) *oauthv1.OAuthClie
 func GetRedirectURIs(client *oauthv1.OAuthClient) []string {
 	return client.RedirectURIs
 }
+
+// the OAuth server doesn't accept shorter inactivity timeouts
+const minTokenInactivityTimeout = 300 * time.Second
+
+// TokenConfig configures the grant method and the lifetime of the tokens issued to the console.
+// It is set through annotations on the operator config:
+//   - console.openshift.io/oauth-grant-method, one of auto, prompt or deny
+//   - console.openshift.io/oauth-access-token-max-age, e.g. 8h
+//   - console.openshift.io/oauth-access-token-inactivity-timeout, e.g. 30m, at least 5m
+//
+// Only the fields whose annotation is set are managed, a field whose annotation is removed is reset.
+// Token lifetimes which are not set on the client fall back to the cluster's OAuth token config.
+type TokenConfig struct {
+	// GrantMethod is empty if it is not managed
+	GrantMethod                         oauthv1.GrantHandlerType
+	AccessTokenMaxAgeSeconds            *int32
+	AccessTokenInactivityTimeoutSeconds *int32
+	// invalid holds the fields whose annotation has an invalid value, they stay managed but
+	// the client keeps its current value
+	invalid sets.Set[string]
+}
+
+const (
+	// TokenConfigGrantMethod is the managed grant method of the client
+	TokenConfigGrantMethod = "grantMethod"
+	// TokenConfigAccessTokenMaxAge is the managed token max age of the client
+	TokenConfigAccessTokenMaxAge = "accessTokenMaxAgeSeconds"
+	// TokenConfigAccessTokenInactivityTimeout is the managed token inactivity timeout of the client
+	TokenConfigAccessTokenInactivityTimeout = "accessTokenInactivityTimeoutSeconds"
+)
+
+// GetTokenConfig returns the token config of the console OAuthClient. Invalid values are
+// returned as an error, the client keeps its current values for them.
+func GetTokenConfig(operatorConfig *operatorv1.Console) (TokenConfig, error) {
+	tokenConfig := TokenConfig{invalid: sets.New[string]()}
+	messages := []string{}
+	if value, ok := operatorConfig.Annotations[api.OAuthClientGrantMethodAnnotation]; ok {
+		switch grantMethod := oauthv1.GrantHandlerType(value); grantMethod {
+		case oauthv1.GrantHandlerAuto, oauthv1.GrantHandlerPrompt, oauthv1.GrantHandlerDeny:
+			tokenConfig.GrantMethod = grantMethod
+		default:
+			messages = append(messages, fmt.Sprintf("%s %q: must be one of %s, %s or %s", api.OAuthClientGrantMethodAnnotation, value, oauthv1.GrantHandlerAuto, oauthv1.GrantHandlerPrompt, oauthv1.GrantHandlerDeny))
+			tokenConfig.invalid.Insert(TokenConfigGrantMethod)
+		}
+	}
+	if value, ok := operatorConfig.Annotations[api.OAuthTokenMaxAgeAnnotation]; ok {
+		if seconds, err := parseTokenLifetime(value, time.Second); err != nil {
+			messages = append(messages, fmt.Sprintf("%s %q: %v", api.OAuthTokenMaxAgeAnnotation, value, err))
+			tokenConfig.invalid.Insert(TokenConfigAccessTokenMaxAge)
+		} else {
+			tokenConfig.AccessTokenMaxAgeSeconds = &seconds
+		}
+	}
+	if value, ok := operatorConfig.Annotations[api.OAuthTokenInactivityAnnotation]; ok {
+		if seconds, err := parseTokenLifetime(value, minTokenInactivityTimeout); err != nil {
+			messages = append(messages, fmt.Sprintf("%s %q: %v", api.OAuthTokenInactivityAnnotation, value, err))
+			tokenConfig.invalid.Insert(TokenConfigAccessTokenInactivityTimeout)
+		} else {
+			tokenConfig.AccessTokenInactivityTimeoutSeconds = &seconds
+		}
+	}
+	if maxAge, timeout := tokenConfig.AccessTokenMaxAgeSeconds, tokenConfig.AccessTokenInactivityTimeoutSeconds; maxAge != nil && timeout != nil && *timeout > *maxAge {
+		messages = append(messages, fmt.Sprintf("%s can't exceed %s", api.OAuthTokenInactivityAnnotation, api.OAuthTokenMaxAgeAnnotation))
+		tokenConfig.AccessTokenInactivityTimeoutSeconds = nil
+		tokenConfig.invalid.Insert(TokenConfigAccessTokenInactivityTimeout)
+	}
+	if len(messages) > 0 {
+		return tokenConfig, fmt.Errorf("invalid console OAuth client config: %s", strings.Join(messages, ", "))
+	}
+	return tokenConfig, nil
+}
+
+func parseTokenLifetime(value string, min time.Duration) (int32, error) {
+	lifetime, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if lifetime < min {
+		return 0, fmt.Errorf("must be at least %s", min)
+	}
+	if lifetime.Seconds() > math.MaxInt32 {
+		return 0, fmt.Errorf("must be at most %ds", math.MaxInt32)
+	}
+	return int32(lifetime.Seconds()), nil
+}
+
+// SetTokenConfig sets the managed grant method and token lifetimes of the client. The managed
+// fields are recorded in an annotation on the client, so that a field is reset once its config is
+// removed: the grant method to auto, which the console client is created with, and the token
+// lifetimes to the cluster's OAuth token config. Fields which were never managed are left as they are.
+func SetTokenConfig(client *oauthv1.OAuthClient, tokenConfig TokenConfig) *oauthv1.OAuthClient {
+	previouslyManaged := GetManagedTokenConfigFields(client)
+	managed := []string{}
+	switch {
+	case len(tokenConfig.GrantMethod) > 0:
+		client.GrantMethod = tokenConfig.GrantMethod
+		managed = append(managed, TokenConfigGrantMethod)
+	case tokenConfig.invalid.Has(TokenConfigGrantMethod):
+		managed = append(managed, TokenConfigGrantMethod)
+	case previouslyManaged.Has(TokenConfigGrantMethod):
+		client.GrantMethod = oauthv1.GrantHandlerAuto
+	}
+	switch {
+	case tokenConfig.AccessTokenMaxAgeSeconds != nil:
+		client.AccessTokenMaxAgeSeconds = tokenConfig.AccessTokenMaxAgeSeconds
+		managed = append(managed, TokenConfigAccessTokenMaxAge)
+	case tokenConfig.invalid.Has(TokenConfigAccessTokenMaxAge):
+		managed = append(managed, TokenConfigAccessTokenMaxAge)
+	case previouslyManaged.Has(TokenConfigAccessTokenMaxAge):
+		client.AccessTokenMaxAgeSeconds = nil
+	}
+	switch {
+	case tokenConfig.AccessTokenInactivityTimeoutSeconds != nil:
+		client.AccessTokenInactivityTimeoutSeconds = tokenConfig.AccessTokenInactivityTimeoutSeconds
+		managed = append(managed, TokenConfigAccessTokenInactivityTimeout)
+	case tokenConfig.invalid.Has(TokenConfigAccessTokenInactivityTimeout):
+		managed = append(managed, TokenConfigAccessTokenInactivityTimeout)
+	case previouslyManaged.Has(TokenConfigAccessTokenInactivityTimeout):
+		client.AccessTokenInactivityTimeoutSeconds = nil
+	}
+
+	if _, recorded := client.Annotations[api.OAuthClientManagedTokenConfigAnnotation]; !recorded && len(managed) == 0 {
+		return client
+	}
+	if client.Annotations == nil {
+		client.Annotations = map[string]string{}
+	}
+	client.Annotations[api.OAuthClientManagedTokenConfigAnnotation] = strings.Join(managed, ",")
+	return client
+}
+
+// GetManagedTokenConfigFields returns the token config fields of the client the operator manages.
+func GetManagedTokenConfigFields(client *oauthv1.OAuthClient) sets.Set[string] {
+	managed := sets.New[string]()
+	for _, field := range strings.Split(client.Annotations[api.OAuthClientManagedTokenConfigAnnotation], ",") {
+		if len(field) > 0 {
+			managed.Insert(field)
+		}
+	}
+	return managed
+}
+
+// GetInactivityTimeoutSeconds returns the inactivity timeout of the console sessions. The console
+// logs users out once their token would time out, so it follows the inactivity timeout of the
+// console OAuthClient, or the cluster's one, and doesn't outlast the tokens' max age.
+func GetInactivityTimeoutSeconds(client *oauthv1.OAuthClient, oauthConfig *configv1.OAuth) int {
+	inactivityTimeoutSeconds := 0
+	if client.AccessTokenInactivityTimeoutSeconds != nil {
+		inactivityTimeoutSeconds = int(*client.AccessTokenInactivityTimeoutSeconds)
+	} else if oauthConfig.Spec.TokenConfig.AccessTokenInactivityTimeout != nil {
+		inactivityTimeoutSeconds = int(oauthConfig.Spec.TokenConfig.AccessTokenInactivityTimeout.Seconds())
+	}
+	if maxAge := client.AccessTokenMaxAgeSeconds; maxAge != nil && *maxAge > 0 && inactivityTimeoutSeconds > int(*maxAge) {
+		inactivityTimeoutSeconds = int(*maxAge)
+	}
+	return inactivityTimeoutSeconds
+}
+
+const (
+	// RedirectURIsPrune removes the redirect URIs the console doesn't serve from the client
+	RedirectURIsPrune = "Prune"
+	// RedirectURIsReport keeps the redirect URIs the console doesn't serve and only reports them
+	RedirectURIsReport = "Report"
+
+	redirectURIPath = "/auth/callback"
+)
+
+// GetRedirectURIsPolicy returns how the redirect URIs of the console OAuthClient which don't
+// belong to the console's hosts are handled, configured through the
+// console.openshift.io/oauth-redirect-uris-policy annotation of the operator config. They are
+// pruned by default, the Report policy keeps them for the redirect URIs other actors rely on.
+// Invalid values fall back to pruning and are returned as an error.
+func GetRedirectURIsPolicy(operatorConfig *operatorv1.Console) (string, error) {
+	value, ok := operatorConfig.Annotations[api.OAuthRedirectURIsPolicyAnnotation]
+	if !ok {
+		return RedirectURIsPrune, nil
+	}
+	switch value {
+	case RedirectURIsPrune, RedirectURIsReport:
+		return value, nil
+	}
+	return RedirectURIsPrune, fmt.Errorf("%s %q: must be either %s or %s", api.OAuthRedirectURIsPolicyAnnotation, value, RedirectURIsPrune, RedirectURIsReport)
+}
+
+// RedirectURIAudit lists the redirect URIs of the console OAuthClient which the console doesn't
+// serve. Stale URIs are callbacks of console hosts the console was registered with before,
+// foreign URIs were added by other actors.
+type RedirectURIAudit struct {
+	Current []string
+	Stale   []string
+	Foreign []string
+}
+
+// AuditRedirectURIs compares the existing redirect URIs of the client with the ones the console
+// serves. Callbacks of the known hosts, the hosts the console was registered with before, are
+// stale. Unless the policy is to report them, the stale and foreign URIs are pruned and Current
+// holds only the console's URIs.
+func AuditRedirectURIs(existing []string, required []string, knownHosts []string, policy string) RedirectURIAudit {
+	audit := RedirectURIAudit{Current: slices.Clone(required)}
+	for _, uri := range existing {
+		if slices.Contains(required, uri) {
+			continue
+		}
+		if host, ok := getRedirectURIHost(uri); ok && slices.Contains(knownHosts, host) {
+			audit.Stale = append(audit.Stale, uri)
+		} else {
+			audit.Foreign = append(audit.Foreign, uri)
+		}
+		if policy == RedirectURIsReport {
+			audit.Current = append(audit.Current, uri)
+		}
+	}
+	return audit
+}
+
+// Unserved returns the stale and foreign redirect URIs.
+func (a RedirectURIAudit) Unserved() []string {
+	return append(slices.Clone(a.Stale), a.Foreign...)
+}
+
+// GetConsoleHosts returns the hosts the console registered its callbacks on the client for.
+func GetConsoleHosts(client *oauthv1.OAuthClient) []string {
+	value := client.Annotations[api.OAuthClientConsoleHostsAnnotation]
+	if len(value) == 0 {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// SetConsoleHosts records the hosts of the console's callbacks the client holds, so that they are
+// recognized as stale once the console no longer serves them. Callbacks of other hosts are
+// not recorded.
+func SetConsoleHosts(client *oauthv1.OAuthClient, redirectURIs []string) *oauthv1.OAuthClient {
+	hosts := []string{}
+	for _, uri := range redirectURIs {
+		if host, ok := getRedirectURIHost(uri); ok && !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	if client.Annotations == nil {
+		client.Annotations = map[string]string{}
+	}
+	client.Annotations[api.OAuthClientConsoleHostsAnnotation] = strings.Join(hosts, ",")
+	return client
+}
+
+func getRedirectURIHost(uri string) (string, bool) {
+	redirectURL, err := url.Parse(uri)
+	if err != nil || redirectURL.Scheme != "https" || redirectURL.Path != redirectURIPath {
+		return "", false
+	}
+	return redirectURL.Host, true
+}
@@ -2,13 +2,18 @@ package oauthclient
 
 import (
 	"testing"
+	"time"
 
 	"github.com/go-test/deep"
 	"github.com/openshift/console-operator/pkg/api"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
+	configv1 "github.com/openshift/api/config/v1"
 	oauthv1 "github.com/openshift/api/oauth/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 func TestDeRegisterConsoleFromOAuthClient(t *testing.T) {
@@ -195,3 +200,258 @@ func TestRegisterConsoleToOAuthClientNoAdditionalHosts(t *testing.T) {
 		t.Errorf("RegisterConsoleToOAuthClient() redirect URIs mismatch: %v", diff)
 	}
 }
+
+func TestGetTokenConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        TokenConfig
+		wantErr     bool
+	}{
+		{
+			name: "Not managed",
+			want: TokenConfig{},
+		},
+		{
+			name: "Configured",
+			annotations: map[string]string{
+				api.OAuthClientGrantMethodAnnotation: "prompt",
+				api.OAuthTokenMaxAgeAnnotation:       "8h",
+				api.OAuthTokenInactivityAnnotation:   "30m",
+			},
+			want: TokenConfig{
+				GrantMethod:                         oauthv1.GrantHandlerPrompt,
+				AccessTokenMaxAgeSeconds:            ptr.To[int32](28800),
+				AccessTokenInactivityTimeoutSeconds: ptr.To[int32](1800),
+			},
+		},
+		{
+			name: "Invalid values are not managed",
+			annotations: map[string]string{
+				api.OAuthClientGrantMethodAnnotation: "always",
+				api.OAuthTokenMaxAgeAnnotation:       "forever",
+				api.OAuthTokenInactivityAnnotation:   "1m",
+			},
+			want:    TokenConfig{},
+			wantErr: true,
+		},
+		{
+			name: "Inactivity timeout exceeding the max age",
+			annotations: map[string]string{
+				api.OAuthTokenMaxAgeAnnotation:     "10m",
+				api.OAuthTokenInactivityAnnotation: "1h",
+			},
+			want: TokenConfig{
+				AccessTokenMaxAgeSeconds: ptr.To[int32](600),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			got, err := GetTokenConfig(operatorConfig)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetTokenConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestSetTokenConfig(t *testing.T) {
+	client := func(managed string, grantMethod oauthv1.GrantHandlerType, maxAge, inactivityTimeout *int32) *oauthv1.OAuthClient {
+		c := &oauthv1.OAuthClient{
+			GrantMethod:                         grantMethod,
+			AccessTokenMaxAgeSeconds:            maxAge,
+			AccessTokenInactivityTimeoutSeconds: inactivityTimeout,
+		}
+		if len(managed) > 0 {
+			c.Annotations = map[string]string{api.OAuthClientManagedTokenConfigAnnotation: managed}
+		}
+		return c
+	}
+	tests := []struct {
+		name        string
+		existing    *oauthv1.OAuthClient
+		tokenConfig TokenConfig
+		want        *oauthv1.OAuthClient
+	}{
+		{
+			name:        "Unmanaged fields are kept",
+			existing:    client("", oauthv1.GrantHandlerPrompt, ptr.To[int32](3600), ptr.To[int32](600)),
+			tokenConfig: TokenConfig{},
+			want:        client("", oauthv1.GrantHandlerPrompt, ptr.To[int32](3600), ptr.To[int32](600)),
+		},
+		{
+			name:        "Managed fields are set and recorded",
+			existing:    client("", oauthv1.GrantHandlerPrompt, ptr.To[int32](3600), ptr.To[int32](600)),
+			tokenConfig: TokenConfig{GrantMethod: oauthv1.GrantHandlerAuto, AccessTokenMaxAgeSeconds: ptr.To[int32](28800)},
+			want:        client("grantMethod,accessTokenMaxAgeSeconds", oauthv1.GrantHandlerAuto, ptr.To[int32](28800), ptr.To[int32](600)),
+		},
+		{
+			name:        "Fields are reset once their config is removed",
+			existing:    client("grantMethod,accessTokenMaxAgeSeconds", oauthv1.GrantHandlerPrompt, ptr.To[int32](28800), ptr.To[int32](600)),
+			tokenConfig: TokenConfig{},
+			want: &oauthv1.OAuthClient{
+				ObjectMeta:                          metav1.ObjectMeta{Annotations: map[string]string{api.OAuthClientManagedTokenConfigAnnotation: ""}},
+				GrantMethod:                         oauthv1.GrantHandlerAuto,
+				AccessTokenInactivityTimeoutSeconds: ptr.To[int32](600),
+			},
+		},
+		{
+			name:        "Fields with an invalid config keep their value",
+			existing:    client("accessTokenMaxAgeSeconds", oauthv1.GrantHandlerPrompt, ptr.To[int32](28800), nil),
+			tokenConfig: TokenConfig{invalid: sets.New(TokenConfigAccessTokenMaxAge)},
+			want:        client("accessTokenMaxAgeSeconds", oauthv1.GrantHandlerPrompt, ptr.To[int32](28800), nil),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(SetTokenConfig(tt.existing, tt.tokenConfig), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetInactivityTimeoutSeconds(t *testing.T) {
+	oauthConfig := &configv1.OAuth{
+		Spec: configv1.OAuthSpec{
+			TokenConfig: configv1.TokenConfig{AccessTokenInactivityTimeout: &metav1.Duration{Duration: time.Hour}},
+		},
+	}
+	tests := []struct {
+		name   string
+		client *oauthv1.OAuthClient
+		want   int
+	}{
+		{
+			name:   "Cluster inactivity timeout",
+			client: &oauthv1.OAuthClient{},
+			want:   3600,
+		},
+		{
+			name:   "Client inactivity timeout",
+			client: &oauthv1.OAuthClient{AccessTokenInactivityTimeoutSeconds: ptr.To[int32](600)},
+			want:   600,
+		},
+		{
+			name:   "Capped by the client max age",
+			client: &oauthv1.OAuthClient{AccessTokenMaxAgeSeconds: ptr.To[int32](900)},
+			want:   900,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(GetInactivityTimeoutSeconds(tt.client, oauthConfig), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestAuditRedirectURIs(t *testing.T) {
+	existing := []string{
+		"https://console.example.com/auth/callback",
+		"https://old-console.example.com/auth/callback",
+		"https://app.example.com/auth/callback",
+		"http://localhost:9000/callback",
+	}
+	required := []string{
+		"https://console.example.com/auth/callback",
+		"https://console-alt.example.com/auth/callback",
+	}
+	knownHosts := []string{"console.example.com", "old-console.example.com"}
+	tests := []struct {
+		name   string
+		policy string
+		want   RedirectURIAudit
+	}{
+		{
+			name:   "Prune",
+			policy: RedirectURIsPrune,
+			want: RedirectURIAudit{
+				Current: required,
+				Stale:   []string{"https://old-console.example.com/auth/callback"},
+				Foreign: []string{"https://app.example.com/auth/callback", "http://localhost:9000/callback"},
+			},
+		},
+		{
+			name:   "Report",
+			policy: RedirectURIsReport,
+			want: RedirectURIAudit{
+				Current: []string{
+					"https://console.example.com/auth/callback",
+					"https://console-alt.example.com/auth/callback",
+					"https://old-console.example.com/auth/callback",
+					"https://app.example.com/auth/callback",
+					"http://localhost:9000/callback",
+				},
+				Stale:   []string{"https://old-console.example.com/auth/callback"},
+				Foreign: []string{"https://app.example.com/auth/callback", "http://localhost:9000/callback"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(AuditRedirectURIs(existing, required, knownHosts, tt.policy), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestConsoleHosts(t *testing.T) {
+	client := SetConsoleHosts(&oauthv1.OAuthClient{}, []string{
+		"https://console.example.com/auth/callback",
+		"https://console.example.com:8443/auth/callback",
+		"https://console.example.com/auth/callback",
+		"http://localhost:9000/callback",
+	})
+	want := []string{"console.example.com", "console.example.com:8443"}
+	if diff := deep.Equal(GetConsoleHosts(client), want); diff != nil {
+		t.Error(diff)
+	}
+	if hosts := GetConsoleHosts(&oauthv1.OAuthClient{}); hosts != nil {
+		t.Errorf("GetConsoleHosts() of a client without hosts = %v, want nil", hosts)
+	}
+}
+
+func TestGetRedirectURIsPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name: "Prune by default",
+			want: RedirectURIsPrune,
+		},
+		{
+			name:        "Report",
+			annotations: map[string]string{api.OAuthRedirectURIsPolicyAnnotation: RedirectURIsReport},
+			want:        RedirectURIsReport,
+		},
+		{
+			name:        "Invalid policy prunes",
+			annotations: map[string]string{api.OAuthRedirectURIsPolicyAnnotation: "Ignore"},
+			want:        RedirectURIsPrune,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetRedirectURIsPolicy(&operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetRedirectURIsPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package route
+
+import (
+	"fmt"
+
+	// kube
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+var CertificateGVR = schema.GroupVersionResource{
+	Group:    api.CertManagerAPIGroup,
+	Version:  api.CertManagerAPIVersion,
+	Resource: api.CertManagerCertificateResource,
+}
+
+// GetIssuingCertificate returns the cert-manager Certificate which issues the given secret,
+// or nil if the secret is not managed by cert-manager.
+func GetIssuingCertificate(certificates []runtime.Object, secretName string) *unstructured.Unstructured {
+	for _, obj := range certificates {
+		certificate, ok := obj.(*unstructured.Unstructured)
+		if ok && GetCertificateSecretName(certificate) == secretName {
+			return certificate
+		}
+	}
+	return nil
+}
+
+// GetCertificateSecretName returns the name of the secret the Certificate is issued into.
+func GetCertificateSecretName(certificate *unstructured.Unstructured) string {
+	name, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+	return name
+}
+
+// CheckCertificateReady verifies that the cert-manager Certificate issuing the secret in the
+// openshift-console namespace, if there is any, is ready. Secrets not issued by cert-manager
+// are used as they are. The lister is nil if cert-manager was not installed when the operator
+// started, no Certificate is checked then.
+func CheckCertificateReady(certificateLister cache.GenericLister, secretName string) error {
+	if certificateLister == nil {
+		return nil
+	}
+	certificates, err := certificateLister.ByNamespace(api.OpenShiftConsoleNamespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list cert-manager certificates: %w", err)
+	}
+	certificate := GetIssuingCertificate(certificates, secretName)
+	if certificate == nil {
+		return nil
+	}
+	if !IsCertificateReady(certificate) {
+		return fmt.Errorf("cert-manager certificate %s/%s issuing %q secret is not ready", certificate.GetNamespace(), certificate.GetName(), secretName)
+	}
+	return nil
+}
+
+// IsCertificateReady returns true if cert-manager reports the Certificate as Ready, meaning
+// the issued secret is up to date with the Certificate spec.
+func IsCertificateReady(certificate *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, condition := range conditions {
+		conditionMap, ok := condition.(map[string]interface{})
+		if ok && conditionMap["type"] == "Ready" && conditionMap["status"] == string(metav1.ConditionTrue) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package route
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func testCertificate(name, secretName, ready string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": api.OpenShiftConsoleNamespace,
+		},
+		"spec": map[string]interface{}{
+			"secretName": secretName,
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type":   "Ready",
+					"status": ready,
+				},
+			},
+		},
+	}}
+}
+
+func TestGetIssuingCertificate(t *testing.T) {
+	certificates := []runtime.Object{
+		testCertificate("console", "console-tls", "True"),
+		testCertificate("downloads", "downloads-tls", "False"),
+	}
+	tests := []struct {
+		name       string
+		secretName string
+		want       string
+	}{
+		{
+			name:       "Secret issued by cert-manager",
+			secretName: "downloads-tls",
+			want:       "downloads",
+		},
+		{
+			name:       "Secret not issued by cert-manager",
+			secretName: "custom-tls",
+			want:       "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if certificate := GetIssuingCertificate(certificates, tt.secretName); certificate != nil {
+				got = certificate.GetName()
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestIsCertificateReady(t *testing.T) {
+	tests := []struct {
+		name        string
+		certificate *unstructured.Unstructured
+		want        bool
+	}{
+		{
+			name:        "Ready certificate",
+			certificate: testCertificate("console", "console-tls", "True"),
+			want:        true,
+		},
+		{
+			name:        "Certificate not issued yet",
+			certificate: testCertificate("console", "console-tls", "False"),
+			want:        false,
+		},
+		{
+			name:        "Certificate without status",
+			certificate: &unstructured.Unstructured{Object: map[string]interface{}{}},
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(IsCertificateReady(tt.certificate), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestCheckCertificateReady(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, certificate := range []*unstructured.Unstructured{
+		testCertificate("console", "console-tls", "True"),
+		testCertificate("downloads", "downloads-tls", "False"),
+	} {
+		if err := indexer.Add(certificate); err != nil {
+			t.Fatal(err)
+		}
+	}
+	certificateLister := cache.NewGenericLister(indexer, CertificateGVR.GroupResource())
+
+	tests := []struct {
+		name              string
+		certificateLister cache.GenericLister
+		secretName        string
+		wantErr           bool
+	}{
+		{
+			name:              "Ready certificate",
+			certificateLister: certificateLister,
+			secretName:        "console-tls",
+		},
+		{
+			name:              "Certificate not ready",
+			certificateLister: certificateLister,
+			secretName:        "downloads-tls",
+			wantErr:           true,
+		},
+		{
+			name:              "Secret not issued by cert-manager",
+			certificateLister: certificateLister,
+			secretName:        "custom-tls",
+		},
+		{
+			name:       "cert-manager not installed",
+			secretName: "downloads-tls",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCertificateReady(tt.certificateLister, tt.secretName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckCertificateReady() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	// kube
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	networkingclientv1 "k8s.io/client-go/kubernetes/typed/networking/v1"
+	"k8s.io/klog/v2"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+var HTTPRouteGVR = schema.GroupVersionResource{
+	Group:    api.GatewayAPIGroup,
+	Version:  api.GatewayAPIVersion,
+	Resource: api.HTTPRouteResource,
+}
+
+var BackendTLSPolicyGVR = schema.GroupVersionResource{
+	Group:    api.GatewayAPIGroup,
+	Version:  api.GatewayAPIVersion,
+	Resource: api.BackendTLSPolicyResource,
+}
+
+// controllers of the IngressClasses the console can be exposed through. The console serves
+// TLS itself, so the ingress controller has to re-encrypt the connection to it, which is
+// configured differently by each of them.
+const (
+	OpenShiftIngressClassController = "openshift.io/ingress-to-route"
+	NginxIngressClassController     = "k8s.io/ingress-nginx"
+)
+
+// exposedBackend is the service port an Ingress or an HTTPRoute of a component points to.
+type exposedBackend struct {
+	portName string
+	port     int64
+}
+
+var exposedBackends = map[string]exposedBackend{
+	api.OpenShiftConsoleRouteName:          {portName: api.ConsoleContainerPortName, port: api.ConsoleContainerPort},
+	api.OpenShiftConsoleDownloadsRouteName: {portName: api.DownloadsPortName, port: 80},
+}
+
+// GetExposureMode returns whether the console and downloads are exposed through a Route,
+// a networking.k8s.io Ingress or a Gateway API HTTPRoute. Unknown modes fall back to a
+// Route, ValidateExposureMode reports them.
+func GetExposureMode(operatorConfig *operatorv1.Console) string {
+	switch mode := operatorConfig.Annotations[api.ExposureModeAnnotation]; mode {
+	case api.ExposureModeIngress, api.ExposureModeHTTPRoute:
+		return mode
+	default:
+		return api.ExposureModeRoute
+	}
+}
+
+func ValidateExposureMode(operatorConfig *operatorv1.Console) error {
+	switch mode := operatorConfig.Annotations[api.ExposureModeAnnotation]; mode {
+	case "", api.ExposureModeRoute, api.ExposureModeIngress, api.ExposureModeHTTPRoute:
+		return nil
+	default:
+		return fmt.Errorf("unknown exposure mode %q set in %q annotation, expected one of %q, %q or %q", mode, api.ExposureModeAnnotation, api.ExposureModeRoute, api.ExposureModeIngress, api.ExposureModeHTTPRoute)
+	}
+}
+
+// GetGatewayRef returns the namespace and the name of the Gateway the HTTPRoutes should be
+// attached to. The Gateway is referenced as "<namespace>/<name>".
+func GetGatewayRef(operatorConfig *operatorv1.Console) (string, string, error) {
+	gateway := operatorConfig.Annotations[api.GatewayAnnotation]
+	namespace, name, found := strings.Cut(gateway, "/")
+	if !found || len(namespace) == 0 || len(name) == 0 {
+		return "", "", fmt.Errorf("%q annotation has to reference a gateway as <namespace>/<name>, got %q", api.GatewayAnnotation, gateway)
+	}
+	return namespace, name, nil
+}
+
+// GetExposedHostname returns the hostname of the Ingress or the HTTPRoute. Unlike with
+// routes, there is a single object per component, so a custom hostname replaces the
+// default one instead of being served next to it.
+func (rc *RouteConfig) GetExposedHostname() string {
+	if rc.IsCustomHostnameSet() {
+		return rc.customRoute.Hostname
+	}
+	return rc.defaultRoute.Hostname
+}
+
+// GetExposedTLSSecretName returns the name of the secret in the openshift-config namespace
+// holding the certificate for the exposed hostname, if any.
+func (rc *RouteConfig) GetExposedTLSSecretName() string {
+	if rc.IsCustomHostnameSet() {
+		return rc.customRoute.SecretName
+	}
+	return rc.defaultRoute.SecretName
+}
+
+func (rc *RouteConfig) GetExposedURL() *url.URL {
+	return &url.URL{Scheme: "https", Host: rc.GetExposedHostname()}
+}
+
+// GetIngressTLSSecretName returns the name of the copy of the custom TLS secret in the
+// openshift-console namespace, since an Ingress can only reference secrets from its own namespace.
+func GetIngressTLSSecretName(routeName string) string {
+	return fmt.Sprintf("%s-ingress-tls", routeName)
+}
+
+// GetServiceHostname returns the hostname the serving certificate of the console service is
+// issued for, which the ingress controller or the Gateway verifies the backend against.
+func GetServiceHostname() string {
+	return fmt.Sprintf("%s.%s.svc", api.OpenShiftConsoleServiceName, api.OpenShiftConsoleNamespace)
+}
+
+// ingressBackendTLSAnnotations are the annotations making the controller of an IngressClass
+// re-encrypt the connection to the console.
+var ingressBackendTLSAnnotations = map[string]map[string]string{
+	// the router verifies the backend against the service CA on its own
+	OpenShiftIngressClassController: {"route.openshift.io/termination": "reencrypt"},
+	NginxIngressClassController: {
+		"nginx.ingress.kubernetes.io/backend-protocol":      "HTTPS",
+		"nginx.ingress.kubernetes.io/proxy-ssl-secret":      fmt.Sprintf("%s/%s", api.OpenShiftConsoleNamespace, api.BackendCAName),
+		"nginx.ingress.kubernetes.io/proxy-ssl-verify":      "on",
+		"nginx.ingress.kubernetes.io/proxy-ssl-name":        GetServiceHostname(),
+		"nginx.ingress.kubernetes.io/proxy-ssl-server-name": "on",
+	},
+}
+
+// GetIngressBackendTLSAnnotations returns the annotations making the controller of the
+// IngressClass re-encrypt the connection to the console. The annotations of the other
+// controllers are returned as removal keys, suffixed with "-", so they don't linger on the
+// Ingress after the IngressClass is switched. The second return value is true when the
+// annotations reference the backend CA secret, which then has to be synced.
+func GetIngressBackendTLSAnnotations(ingressClassController string) (map[string]string, bool, error) {
+	required, ok := ingressBackendTLSAnnotations[ingressClassController]
+	if !ok {
+		return nil, false, fmt.Errorf("ingress class controller %q is not known to re-encrypt the connection to the console, expected one of %q or %q", ingressClassController, OpenShiftIngressClassController, NginxIngressClassController)
+	}
+	annotations := map[string]string{}
+	for controller, controllerAnnotations := range ingressBackendTLSAnnotations {
+		if controller == ingressClassController {
+			continue
+		}
+		for key := range controllerAnnotations {
+			if _, ok := required[key]; !ok {
+				annotations[key+"-"] = ""
+			}
+		}
+	}
+	for key, value := range required {
+		annotations[key] = value
+	}
+	return annotations, ingressClassController == NginxIngressClassController, nil
+}
+
+// GetDefaultIngressClass returns the IngressClass marked as the default one, which serves
+// Ingresses without an ingress class name.
+func GetDefaultIngressClass(ingressClasses []*networkingv1.IngressClass) (*networkingv1.IngressClass, error) {
+	for _, ingressClass := range ingressClasses {
+		if ingressClass.Annotations[networkingv1.AnnotationIsDefaultIngressClass] == "true" {
+			return ingressClass, nil
+		}
+	}
+	return nil, fmt.Errorf("no default ingress class found, set the %q annotation", api.IngressClassAnnotation)
+}
+
+// DefaultIngress returns the Ingress for the component. The tlsSecretName can be empty,
+// in which case the ingress controller serves its default certificate. The annotations
+// configure the re-encryption to the backend, see GetIngressBackendTLSAnnotations.
+func (rc *RouteConfig) DefaultIngress(ingressClassName string, tlsSecretName string, annotations map[string]string) *networkingv1.Ingress {
+	backend := exposedBackends[rc.routeName]
+	hostname := rc.GetExposedHostname()
+	pathType := networkingv1.PathTypePrefix
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rc.routeName,
+			Namespace: api.OpenShiftConsoleNamespace,
+			Labels:    map[string]string{"app": api.OpenShiftConsoleName},
+		},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{{
+				Hosts:      []string{hostname},
+				SecretName: tlsSecretName,
+			}},
+			Rules: []networkingv1.IngressRule{{
+				Host: hostname,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: rc.routeName,
+									Port: networkingv1.ServiceBackendPort{Name: backend.portName},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	if len(ingressClassName) != 0 {
+		ingress.Spec.IngressClassName = &ingressClassName
+	}
+	if len(annotations) != 0 {
+		ingress.Annotations = annotations
+	}
+	rc.setShardLabels(&ingress.ObjectMeta)
+	return ingress
+}
+
+// DefaultHTTPRoute returns the Gateway API HTTPRoute for the component, attached to the
+// given Gateway. TLS is terminated by the Gateway listener.
+func (rc *RouteConfig) DefaultHTTPRoute(gatewayNamespace string, gatewayName string) *unstructured.Unstructured {
+	backend := exposedBackends[rc.routeName]
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": HTTPRouteGVR.GroupVersion().String(),
+		"kind":       "HTTPRoute",
+		"metadata": map[string]interface{}{
+			"name":      rc.routeName,
+			"namespace": api.OpenShiftConsoleNamespace,
+			"labels": map[string]interface{}{
+				"app": api.OpenShiftConsoleName,
+			},
+		},
+		"spec": map[string]interface{}{
+			"parentRefs": []interface{}{
+				map[string]interface{}{
+					"namespace": gatewayNamespace,
+					"name":      gatewayName,
+				},
+			},
+			"hostnames": []interface{}{rc.GetExposedHostname()},
+			"rules": []interface{}{
+				map[string]interface{}{
+					"backendRefs": []interface{}{
+						map[string]interface{}{
+							"name": rc.routeName,
+							"port": backend.port,
+						},
+					},
+				},
+			},
+		},
+	}}
+}
+
+// DefaultBackendTLSPolicy returns the Gateway API BackendTLSPolicy making the Gateway
+// re-encrypt the connection to the console service, verified against the service CA.
+func DefaultBackendTLSPolicy() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": BackendTLSPolicyGVR.GroupVersion().String(),
+		"kind":       "BackendTLSPolicy",
+		"metadata": map[string]interface{}{
+			"name":      api.OpenShiftConsoleServiceName,
+			"namespace": api.OpenShiftConsoleNamespace,
+			"labels": map[string]interface{}{
+				"app": api.OpenShiftConsoleName,
+			},
+		},
+		"spec": map[string]interface{}{
+			"targetRefs": []interface{}{
+				map[string]interface{}{
+					"group":       "",
+					"kind":        "Service",
+					"name":        api.OpenShiftConsoleServiceName,
+					"sectionName": api.ConsoleContainerPortName,
+				},
+			},
+			"validation": map[string]interface{}{
+				"caCertificateRefs": []interface{}{
+					map[string]interface{}{
+						"group": "",
+						"kind":  "ConfigMap",
+						"name":  api.BackendCAName,
+					},
+				},
+				"hostname": GetServiceHostname(),
+			},
+		},
+	}}
+}
+
+// IsIngressAdmitted returns true once the ingress controller published the Ingress.
+func IsIngressAdmitted(ingress *networkingv1.Ingress) bool {
+	return len(ingress.Status.LoadBalancer.Ingress) != 0
+}
+
+// IsHTTPRouteAccepted returns true if the given Gateway accepted the HTTPRoute.
+func IsHTTPRouteAccepted(httpRoute *unstructured.Unstructured, gatewayNamespace string, gatewayName string) bool {
+	parents, _, _ := unstructured.NestedSlice(httpRoute.Object, "status", "parents")
+	for _, parent := range parents {
+		parentMap, ok := parent.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		namespace, _, _ := unstructured.NestedString(parentMap, "parentRef", "namespace")
+		name, _, _ := unstructured.NestedString(parentMap, "parentRef", "name")
+		if namespace != gatewayNamespace || name != gatewayName {
+			continue
+		}
+		conditions, _, _ := unstructured.NestedSlice(parentMap, "conditions")
+		for _, condition := range conditions {
+			conditionMap, ok := condition.(map[string]interface{})
+			if ok && conditionMap["type"] == "Accepted" && conditionMap["status"] == string(metav1.ConditionTrue) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func ApplyIngress(ctx context.Context, client networkingclientv1.IngressesGetter, required *networkingv1.Ingress) (*networkingv1.Ingress, bool, error) {
+	existing, err := client.Ingresses(required.Namespace).Get(ctx, required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		requiredCopy := required.DeepCopy()
+		actual, err := client.Ingresses(requiredCopy.Namespace).Create(ctx, resourcemerge.WithCleanLabelsAndAnnotations(requiredCopy).(*networkingv1.Ingress), metav1.CreateOptions{})
+		return actual, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	existingCopy := existing.DeepCopy()
+	modified := resourcemerge.BoolPtr(false)
+	resourcemerge.EnsureObjectMeta(modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	specSame := equality.Semantic.DeepEqual(existingCopy.Spec, required.Spec)
+
+	if specSame && !*modified {
+		klog.V(4).Infof("%s ingress exists and is in the correct state", existingCopy.ObjectMeta.Name)
+		return existingCopy, false, nil
+	}
+
+	existingCopy.Spec = required.Spec
+	actual, err := client.Ingresses(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
+	return actual, true, err
+}
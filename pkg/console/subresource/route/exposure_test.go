@@ -0,0 +1,257 @@
+package route
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetExposedHostname(t *testing.T) {
+	ingressConfig := &configv1.Ingress{
+		Spec: configv1.IngressSpec{
+			Domain: "apps.devcluster.openshift.com",
+			ComponentRoutes: []configv1.ComponentRouteSpec{
+				{
+					Name:      api.OpenShiftConsoleDownloadsRouteName,
+					Namespace: api.OpenShiftConsoleNamespace,
+					Hostname:  "downloads.example.com",
+					ServingCertKeyPairSecret: configv1.SecretNameReference{
+						Name: "downloads-tls",
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name           string
+		routeName      string
+		wantHostname   string
+		wantSecretName string
+	}{
+		{
+			name:         "Default hostname is exposed",
+			routeName:    api.OpenShiftConsoleRouteName,
+			wantHostname: "console-openshift-console.apps.devcluster.openshift.com",
+		},
+		{
+			name:           "Custom hostname replaces the default hostname",
+			routeName:      api.OpenShiftConsoleDownloadsRouteName,
+			wantHostname:   "downloads.example.com",
+			wantSecretName: "downloads-tls",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routeConfig := NewRouteConfig(&operatorv1.Console{}, ingressConfig, tt.routeName)
+			if diff := deep.Equal(routeConfig.GetExposedHostname(), tt.wantHostname); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(routeConfig.GetExposedTLSSecretName(), tt.wantSecretName); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetGatewayRef(t *testing.T) {
+	tests := []struct {
+		name          string
+		annotation    string
+		wantNamespace string
+		wantName      string
+		wantErr       bool
+	}{
+		{
+			name:          "Valid gateway reference",
+			annotation:    "openshift-ingress/console-gateway",
+			wantNamespace: "openshift-ingress",
+			wantName:      "console-gateway",
+		},
+		{
+			name:       "Missing namespace",
+			annotation: "console-gateway",
+			wantErr:    true,
+		},
+		{
+			name:       "Missing name",
+			annotation: "openshift-ingress/",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{api.GatewayAnnotation: tt.annotation},
+				},
+			}
+			namespace, name, err := GetGatewayRef(operatorConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetGatewayRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := deep.Equal([]string{namespace, name}, []string{tt.wantNamespace, tt.wantName}); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestIsHTTPRouteAccepted(t *testing.T) {
+	httpRouteWithStatus := func(namespace, name, accepted string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"parents": []interface{}{
+					map[string]interface{}{
+						"parentRef": map[string]interface{}{
+							"namespace": namespace,
+							"name":      name,
+						},
+						"conditions": []interface{}{
+							map[string]interface{}{
+								"type":   "Accepted",
+								"status": accepted,
+							},
+						},
+					},
+				},
+			},
+		}}
+	}
+	tests := []struct {
+		name      string
+		httpRoute *unstructured.Unstructured
+		want      bool
+	}{
+		{
+			name:      "Accepted by the gateway",
+			httpRoute: httpRouteWithStatus("openshift-ingress", "console-gateway", "True"),
+			want:      true,
+		},
+		{
+			name:      "Rejected by the gateway",
+			httpRoute: httpRouteWithStatus("openshift-ingress", "console-gateway", "False"),
+			want:      false,
+		},
+		{
+			name:      "Accepted by another gateway",
+			httpRoute: httpRouteWithStatus("openshift-ingress", "other-gateway", "True"),
+			want:      false,
+		},
+		{
+			name:      "No status yet",
+			httpRoute: &unstructured.Unstructured{Object: map[string]interface{}{}},
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(IsHTTPRouteAccepted(tt.httpRoute, "openshift-ingress", "console-gateway"), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetIngressBackendTLSAnnotations(t *testing.T) {
+	tests := []struct {
+		name              string
+		controller        string
+		want              map[string]string
+		wantUsesBackendCA bool
+		wantErr           bool
+	}{
+		{
+			name:       "OpenShift router re-encrypts on its own",
+			controller: OpenShiftIngressClassController,
+			want: map[string]string{
+				"route.openshift.io/termination":                     "reencrypt",
+				"nginx.ingress.kubernetes.io/backend-protocol-":      "",
+				"nginx.ingress.kubernetes.io/proxy-ssl-secret-":      "",
+				"nginx.ingress.kubernetes.io/proxy-ssl-verify-":      "",
+				"nginx.ingress.kubernetes.io/proxy-ssl-name-":        "",
+				"nginx.ingress.kubernetes.io/proxy-ssl-server-name-": "",
+			},
+		},
+		{
+			name:       "Ingress-nginx verifies the backend against the backend CA",
+			controller: NginxIngressClassController,
+			want: map[string]string{
+				"nginx.ingress.kubernetes.io/backend-protocol":      "HTTPS",
+				"nginx.ingress.kubernetes.io/proxy-ssl-secret":      "openshift-console/console-backend-ca",
+				"nginx.ingress.kubernetes.io/proxy-ssl-verify":      "on",
+				"nginx.ingress.kubernetes.io/proxy-ssl-name":        "console.openshift-console.svc",
+				"nginx.ingress.kubernetes.io/proxy-ssl-server-name": "on",
+				"route.openshift.io/termination-":                   "",
+			},
+			wantUsesBackendCA: true,
+		},
+		{
+			name:       "Unknown controller",
+			controller: "example.com/ingress-controller",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, usesBackendCA, err := GetIngressBackendTLSAnnotations(tt.controller)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetIngressBackendTLSAnnotations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(usesBackendCA, tt.wantUsesBackendCA); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetDefaultIngressClass(t *testing.T) {
+	ingressClass := func(name string, isDefault string) *networkingv1.IngressClass {
+		return &networkingv1.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{networkingv1.AnnotationIsDefaultIngressClass: isDefault},
+			},
+		}
+	}
+	tests := []struct {
+		name           string
+		ingressClasses []*networkingv1.IngressClass
+		want           string
+		wantErr        bool
+	}{
+		{
+			name:           "Default ingress class",
+			ingressClasses: []*networkingv1.IngressClass{ingressClass("openshift-default", "false"), ingressClass("nginx", "true")},
+			want:           "nginx",
+		},
+		{
+			name:           "No default ingress class",
+			ingressClasses: []*networkingv1.IngressClass{ingressClass("openshift-default", "false")},
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetDefaultIngressClass(tt.ingressClasses)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetDefaultIngressClass() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != nil {
+				if diff := deep.Equal(got.Name, tt.want); diff != nil {
+					t.Error(diff)
+				}
+			}
+		})
+	}
+}
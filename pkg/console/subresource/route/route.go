@@ -14,11 +14,14 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
 	routeclient "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
@@ -50,6 +53,10 @@ type RouteConfig struct {
 	customRoute  RouteControllerSpec
 	domain       string
 	routeName    string
+	// labels required by the route selector of the IngressController shard
+	shardLabels map[string]string
+	// namespace of the custom TLS secrets, only set if it isn't openshift-config
+	tlsSecretNamespace string
 }
 
 type RouteControllerSpec struct {
@@ -76,8 +83,16 @@ func getComponentRouteStatus(ingressConfig *configv1.Ingress, componentName stri
 }
 
 func NewRouteConfig(operatorConfig *operatorv1.Console, ingressConfig *configv1.Ingress, routeName string) *RouteConfig {
+	return NewShardedRouteConfig(operatorConfig, ingressConfig, nil, routeName)
+}
+
+// NewShardedRouteConfig returns the route config for routes that are placed on the given
+// IngressController shard. The default route hostname is derived from the shard's domain
+// and the routes are labeled to match the shard's route selector.
+func NewShardedRouteConfig(operatorConfig *operatorv1.Console, ingressConfig *configv1.Ingress, ingressController *operatorv1.IngressController, routeName string) *RouteConfig {
+	domain := GetIngressDomain(ingressConfig, ingressController)
 	defaultRoute := RouteControllerSpec{
-		Hostname: GetDefaultRouteHost(routeName, ingressConfig),
+		Hostname: GetDefaultRouteHost(routeName, domain),
 	}
 	var customRoute RouteControllerSpec
 	var isIngressConfigCustomHostnameSet bool
@@ -117,8 +132,12 @@ func NewRouteConfig(operatorConfig *operatorv1.Console, ingressConfig *configv1.
 	routeConfig := &RouteConfig{
 		defaultRoute: defaultRoute,
 		customRoute:  customRoute,
-		domain:       ingressConfig.Spec.Domain,
+		domain:       domain,
 		routeName:    routeName,
+		shardLabels:  GetShardLabels(ingressController),
+	}
+	if namespace := operatorConfig.Annotations[api.RouteTLSSecretNamespaceAnnotation]; namespace != api.OpenShiftConfigNamespace {
+		routeConfig.tlsSecretNamespace = namespace
 	}
 
 	return routeConfig
@@ -168,14 +187,25 @@ func (rc *RouteConfig) GetDomain() string {
 	return rc.domain
 }
 
+// GetTLSSecretNamespace returns the namespace the custom TLS secrets are read from.
+// Admins can point it to openshift-console, where the certificates can be issued
+// directly by cert-manager, instead of copying them to openshift-config.
+func (rc *RouteConfig) GetTLSSecretNamespace() string {
+	if len(rc.tlsSecretNamespace) == 0 {
+		return api.OpenShiftConfigNamespace
+	}
+	return rc.tlsSecretNamespace
+}
+
 // Default `console` route points by default to the `console` service.
 // If custom Hostname for the console is set, then the default route
 // should point to the redirect `console-redirect` service and the
 // created custom route should be pointing to the `console` service.
-func (rc *RouteConfig) DefaultRoute(tlsConfig *CustomTLSCert, ingressConfig *configv1.Ingress) *routev1.Route {
+func (rc *RouteConfig) DefaultRoute(tlsConfig *CustomTLSCert) *routev1.Route {
 	route := resourceread.ReadRouteV1OrDie(bindata.MustAsset(fmt.Sprintf("assets/routes/%s-route.yaml", rc.routeName)))
-	route.Spec.Host = GetDefaultRouteHost(rc.routeName, ingressConfig)
+	route.Spec.Host = rc.defaultRoute.Hostname
 	setTLS(tlsConfig, route)
+	rc.setShardLabels(&route.ObjectMeta)
 	return route
 }
 
@@ -183,16 +213,161 @@ func (rc *RouteConfig) CustomRoute(tlsConfig *CustomTLSCert, routeName string) *
 	route := resourceread.ReadRouteV1OrDie(bindata.MustAsset(fmt.Sprintf("assets/routes/%s-custom-route.yaml", rc.routeName)))
 	route.Spec.Host = rc.customRoute.Hostname
 	setTLS(tlsConfig, route)
+	rc.setShardLabels(&route.ObjectMeta)
 	return route
 }
 
+func (rc *RouteConfig) setShardLabels(meta *metav1.ObjectMeta) {
+	if len(rc.shardLabels) == 0 {
+		return
+	}
+	if meta.Labels == nil {
+		meta.Labels = map[string]string{}
+	}
+	for k, v := range rc.shardLabels {
+		meta.Labels[k] = v
+	}
+}
+
 func (rc *RouteConfig) UnsetTLS() {
 	rc.defaultRoute.SecretName = ""
 	rc.customRoute.SecretName = ""
 }
 
-func GetDefaultRouteHost(routeName string, ingressConfig *configv1.Ingress) string {
-	return fmt.Sprintf("%s-%s.%s", routeName, api.OpenShiftConsoleNamespace, ingressConfig.Spec.Domain)
+func GetDefaultRouteHost(routeName string, domain string) string {
+	return fmt.Sprintf("%s-%s.%s", routeName, api.OpenShiftConsoleNamespace, domain)
+}
+
+// GetIngressControllerName returns the name of the IngressController which should expose
+// the console routes. Admins can place the routes on a router shard by annotating the
+// operator config, otherwise the default IngressController is used.
+func GetIngressControllerName(operatorConfig *operatorv1.Console) string {
+	if name := operatorConfig.Annotations[api.IngressControllerAnnotation]; len(name) != 0 {
+		return name
+	}
+	return api.DefaultIngressController
+}
+
+// GetRouterName returns the name the IngressController returned by GetIngressController admits
+// routes with. The shard asked for in the operator config may have been rejected in favor of the
+// default IngressController, which is also the one admitting the routes if none could be read.
+func GetRouterName(ingressController *operatorv1.IngressController) string {
+	if ingressController == nil {
+		return api.DefaultIngressController
+	}
+	return ingressController.Name
+}
+
+// GetIngressController returns the IngressController the console routes are placed on. A shard
+// which can't admit the routes of the console namespace is not used, the default IngressController
+// is returned instead together with the reason the shard was rejected, which the route controllers
+// report. Nil is only returned if the default IngressController can't be read either.
+func GetIngressController(ingressControllerLister operatorv1listers.IngressControllerLister, namespaceLister corev1listers.NamespaceLister, operatorConfig *operatorv1.Console) (*operatorv1.IngressController, string, error) {
+	ingressControllerName := GetIngressControllerName(operatorConfig)
+	ingressController, err := ingressControllerLister.IngressControllers(api.IngressControllerNamespace).Get(ingressControllerName)
+	reason := "FailedGetIngressController"
+	if err != nil {
+		err = fmt.Errorf("failed to get %q ingress controller: %w", ingressControllerName, err)
+	} else {
+		reason, err = validateIngressShard(namespaceLister, ingressController)
+	}
+	if err == nil || ingressControllerName == api.DefaultIngressController {
+		return ingressController, reason, err
+	}
+
+	klog.V(4).Infof("falling back to the %q ingress controller: %v", api.DefaultIngressController, err)
+	defaultIngressController, defaultErr := ingressControllerLister.IngressControllers(api.IngressControllerNamespace).Get(api.DefaultIngressController)
+	if defaultErr != nil {
+		klog.V(4).Infof("failed to get %q ingress controller: %v", api.DefaultIngressController, defaultErr)
+		return nil, reason, err
+	}
+	return defaultIngressController, reason, err
+}
+
+// GetServedIngressController returns the IngressController serving the console routes, see
+// GetIngressController. A rejected shard is only logged, the route controllers report it. An
+// error is returned if no IngressController can be read, the route hosts can't be told then.
+func GetServedIngressController(ingressControllerLister operatorv1listers.IngressControllerLister, namespaceLister corev1listers.NamespaceLister, operatorConfig *operatorv1.Console) (*operatorv1.IngressController, error) {
+	ingressController, _, err := GetIngressController(ingressControllerLister, namespaceLister, operatorConfig)
+	if ingressController == nil {
+		return nil, err
+	}
+	if err != nil {
+		klog.V(4).Infof("console routes are served by the %q ingress controller: %v", ingressController.Name, err)
+	}
+	return ingressController, nil
+}
+
+// validateIngressShard verifies that the routes in the console namespace can be admitted by the
+// IngressController shard. The default IngressController admits them without any labels.
+func validateIngressShard(namespaceLister corev1listers.NamespaceLister, ingressController *operatorv1.IngressController) (string, error) {
+	if ingressController.Name == api.DefaultIngressController {
+		return "", nil
+	}
+
+	if len(ingressController.Status.Domain) == 0 {
+		return "IngressControllerDomainNotSet", fmt.Errorf("%q ingress controller has no domain set in its status", ingressController.Name)
+	}
+
+	if ingressController.Spec.RouteSelector != nil {
+		routeSelector, err := metav1.LabelSelectorAsSelector(ingressController.Spec.RouteSelector)
+		if err != nil {
+			return "InvalidRouteSelector", fmt.Errorf("failed to parse route selector of %q ingress controller: %w", ingressController.Name, err)
+		}
+		if !routeSelector.Matches(labels.Set(GetShardLabels(ingressController))) {
+			return "UnsupportedRouteSelector", fmt.Errorf("route selector of %q ingress controller can not be satisfied by route labels, only matchLabels are supported", ingressController.Name)
+		}
+	}
+
+	if ingressController.Spec.NamespaceSelector != nil {
+		namespaceSelector, err := metav1.LabelSelectorAsSelector(ingressController.Spec.NamespaceSelector)
+		if err != nil {
+			return "InvalidNamespaceSelector", fmt.Errorf("failed to parse namespace selector of %q ingress controller: %w", ingressController.Name, err)
+		}
+		namespace, err := namespaceLister.Get(api.OpenShiftConsoleNamespace)
+		if err != nil {
+			return "FailedGetNamespace", err
+		}
+		if !namespaceSelector.Matches(labels.Set(namespace.Labels)) {
+			return "NamespaceNotSelected", fmt.Errorf("namespace selector of %q ingress controller does not select the %q namespace, label the namespace to match %q", ingressController.Name, api.OpenShiftConsoleNamespace, namespaceSelector.String())
+		}
+	}
+
+	return "", nil
+}
+
+// GetIngressDomain returns the domain of the IngressController shard, or the cluster
+// ingress domain if no shard is used.
+func GetIngressDomain(ingressConfig *configv1.Ingress, ingressController *operatorv1.IngressController) string {
+	if ingressController != nil && ingressController.Name != api.DefaultIngressController && len(ingressController.Status.Domain) != 0 {
+		return ingressController.Status.Domain
+	}
+	return ingressConfig.Spec.Domain
+}
+
+// GetShardLabels returns the labels a route needs to be selected by the route selector
+// of the IngressController shard. Only the default IngressController is not considered a shard.
+func GetShardLabels(ingressController *operatorv1.IngressController) map[string]string {
+	if ingressController == nil || ingressController.Name == api.DefaultIngressController || ingressController.Spec.RouteSelector == nil {
+		return nil
+	}
+	return ingressController.Spec.RouteSelector.MatchLabels
+}
+
+// IsAdmittedByIngressController checks if the route was admitted by the router
+// of the given IngressController.
+func IsAdmittedByIngressController(route *routev1.Route, ingressControllerName string) bool {
+	for _, ingress := range route.Status.Ingress {
+		if ingress.RouterName != ingressControllerName {
+			continue
+		}
+		for _, condition := range ingress.Conditions {
+			if condition.Type == routev1.RouteAdmitted && condition.Status == corev1.ConditionTrue {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func ApplyRoute(client routeclient.RoutesGetter, required *routev1.Route) (*routev1.Route, bool, error) {
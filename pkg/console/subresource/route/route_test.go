@@ -7,8 +7,14 @@ import (
 
 	"github.com/go-test/deep"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
 )
 
@@ -37,7 +43,7 @@ func TestGetDefaultRouteHost(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if diff := deep.Equal(GetDefaultRouteHost(tt.args.routeName, tt.args.ingressConfig), tt.want); diff != nil {
+			if diff := deep.Equal(GetDefaultRouteHost(tt.args.routeName, tt.args.ingressConfig.Spec.Domain), tt.want); diff != nil {
 				t.Error(diff)
 			}
 		})
@@ -162,6 +168,176 @@ func TestNewRouteConfig(t *testing.T) {
 	}
 }
 
+func TestNewShardedRouteConfig(t *testing.T) {
+	ingressConfig := &configv1.Ingress{Spec: configv1.IngressSpec{Domain: "apps.example.com"}}
+	tests := []struct {
+		name              string
+		ingressController *operatorv1.IngressController
+		wantHostname      string
+		wantLabels        map[string]string
+	}{
+		{
+			name: "Default IngressController uses the cluster ingress domain",
+			ingressController: &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{Name: api.DefaultIngressController},
+				Status:     operatorv1.IngressControllerStatus{Domain: "apps.example.com"},
+			},
+			wantHostname: "console-openshift-console.apps.example.com",
+			wantLabels:   map[string]string{"app": "console"},
+		},
+		{
+			name: "IngressController shard uses its own domain and route selector",
+			ingressController: &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{Name: "private"},
+				Spec: operatorv1.IngressControllerSpec{
+					RouteSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"type": "private"},
+					},
+				},
+				Status: operatorv1.IngressControllerStatus{Domain: "private.example.com"},
+			},
+			wantHostname: "console-openshift-console.private.example.com",
+			wantLabels:   map[string]string{"app": "console", "type": "private"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NewShardedRouteConfig(&operatorv1.Console{}, ingressConfig, tt.ingressController, api.OpenShiftConsoleRouteName)
+			route := config.DefaultRoute(nil)
+			if diff := deep.Equal(route.Spec.Host, tt.wantHostname); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(route.Labels, tt.wantLabels); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetIngressController(t *testing.T) {
+	defaultIngressController := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: api.DefaultIngressController, Namespace: api.IngressControllerNamespace},
+		Status:     operatorv1.IngressControllerStatus{Domain: "apps.example.com"},
+	}
+	shard := func(domain string, namespaceLabels map[string]string) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "private", Namespace: api.IngressControllerNamespace},
+			Spec: operatorv1.IngressControllerSpec{
+				RouteSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"type": "private"}},
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: namespaceLabels},
+			},
+			Status: operatorv1.IngressControllerStatus{Domain: domain},
+		}
+	}
+	consoleNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: api.OpenShiftConsoleNamespace, Labels: map[string]string{"ingress": "private"}},
+	}
+	tests := []struct {
+		name           string
+		shard          *operatorv1.IngressController
+		wantController string
+		wantReason     string
+	}{
+		{
+			name:           "Valid IngressController shard is used",
+			shard:          shard("private.example.com", map[string]string{"ingress": "private"}),
+			wantController: "private",
+		},
+		{
+			name:           "IngressController shard without a domain falls back to the default",
+			shard:          shard("", map[string]string{"ingress": "private"}),
+			wantController: api.DefaultIngressController,
+			wantReason:     "IngressControllerDomainNotSet",
+		},
+		{
+			name:           "IngressController shard not selecting the console namespace falls back to the default",
+			shard:          shard("private.example.com", map[string]string{"ingress": "public"}),
+			wantController: api.DefaultIngressController,
+			wantReason:     "NamespaceNotSelected",
+		},
+		{
+			name:           "Missing IngressController shard falls back to the default",
+			wantController: api.DefaultIngressController,
+			wantReason:     "FailedGetIngressController",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			indexer.Add(defaultIngressController)
+			if tt.shard != nil {
+				indexer.Add(tt.shard)
+			}
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{api.IngressControllerAnnotation: "private"}},
+			}
+			namespaceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			namespaceIndexer.Add(consoleNamespace)
+			ingressController, reason, err := GetIngressController(operatorv1listers.NewIngressControllerLister(indexer), corev1listers.NewNamespaceLister(namespaceIndexer), operatorConfig)
+			if ingressController == nil {
+				t.Fatalf("expected an ingress controller, got nil")
+			}
+			if diff := deep.Equal(ingressController.Name, tt.wantController); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(reason, tt.wantReason); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != (len(tt.wantReason) != 0) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetServedIngressController(t *testing.T) {
+	defaultIngressController := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: api.DefaultIngressController, Namespace: api.IngressControllerNamespace},
+		Status:     operatorv1.IngressControllerStatus{Domain: "apps.example.com"},
+	}
+	tests := []struct {
+		name               string
+		ingressControllers []*operatorv1.IngressController
+		wantController     string
+		wantErr            bool
+	}{
+		{
+			name:               "Rejected IngressController shard is not an error",
+			ingressControllers: []*operatorv1.IngressController{defaultIngressController},
+			wantController:     api.DefaultIngressController,
+		},
+		{
+			name:    "Missing default IngressController",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, ingressController := range tt.ingressControllers {
+				indexer.Add(ingressController)
+			}
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{api.IngressControllerAnnotation: "private"}},
+			}
+			namespaceLister := corev1listers.NewNamespaceLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}))
+			ingressController, err := GetServedIngressController(operatorv1listers.NewIngressControllerLister(indexer), namespaceLister, operatorConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				if ingressController != nil {
+					t.Errorf("expected no ingress controller, got %q", ingressController.Name)
+				}
+				return
+			}
+			if diff := deep.Equal(ingressController.Name, tt.wantController); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
 func TestGetAdditionalComponentRouteSpecs(t *testing.T) {
 	tests := []struct {
 		name          string
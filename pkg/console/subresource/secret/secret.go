@@ -1,16 +1,26 @@
 package secret
 
 import (
+	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	// kube
 	corev1 "k8s.io/api/core/v1"
 	// openshift
+	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/console-operator/pkg/console/subresource/deployment"
 	"github.com/openshift/console-operator/pkg/console/subresource/util"
 )
 
 const ClientSecretKey = "clientSecret"
 
+// authTypeAnnotation records the authentication type the client secrets were synced for, the
+// client secret is kept under the same key for every type.
+const authTypeAnnotation = "console.openshift.io/authentication-type"
+
+// pendingAuthTypeAnnotation records the authentication type of the pending client secret, which is
+// staged next to the served one until the console switches to the type.
+const pendingAuthTypeAnnotation = "console.openshift.io/pending-authentication-type"
+
 func DefaultSecret(cr *operatorv1.Console, randomBits string) *corev1.Secret {
 	secret := Stub()
 
@@ -44,3 +54,108 @@ func SetSecretString(secret *corev1.Secret, randomBits string) *corev1.Secret {
 	}
 	return secret
 }
+
+// SetSecretStrings sets the client secrets of the console client, keyed the way they
+// are read from the mounted secret.
+func SetSecretStrings(secret *corev1.Secret, secretStrings map[string]string) *corev1.Secret {
+	secret.Data = map[string][]byte{}
+	for key, value := range secretStrings {
+		secret.Data[key] = []byte(value)
+	}
+	return secret
+}
+
+// HasSecretStrings returns whether the secret holds exactly the given client secrets.
+func HasSecretStrings(secret *corev1.Secret, secretStrings map[string]string) bool {
+	if secret == nil || len(secret.Data) != len(secretStrings) {
+		return false
+	}
+	for key, value := range secretStrings {
+		if data, ok := secret.Data[key]; !ok || string(data) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// SetAuthType records the authentication type the client secrets of the secret belong to.
+func SetAuthType(secret *corev1.Secret, authType configv1.AuthenticationType) *corev1.Secret {
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[authTypeAnnotation] = string(authType)
+	return secret
+}
+
+// GetAuthType returns the authentication type the client secrets of the secret belong to.
+func GetAuthType(secret *corev1.Secret) configv1.AuthenticationType {
+	return configv1.AuthenticationType(secret.Annotations[authTypeAnnotation])
+}
+
+// SameAuthType returns whether the console logs in the same way for both authentication types,
+// it serves the "openshift" auth type for every type but OIDC.
+func SameAuthType(a, b configv1.AuthenticationType) bool {
+	return (a == configv1.AuthenticationTypeOIDC) == (b == configv1.AuthenticationTypeOIDC)
+}
+
+// SetPendingSecretString stages the client secret of an authentication type the console doesn't
+// serve yet next to the served one.
+func SetPendingSecretString(secret *corev1.Secret, authType configv1.AuthenticationType, secretString string) *corev1.Secret {
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[api.PendingClientSecretKey] = []byte(secretString)
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[pendingAuthTypeAnnotation] = string(authType)
+	return secret
+}
+
+// GetPendingAuthType returns the authentication type of the pending client secret, if the secret
+// holds one.
+func GetPendingAuthType(secret *corev1.Secret) (configv1.AuthenticationType, bool) {
+	authType, ok := secret.Annotations[pendingAuthTypeAnnotation]
+	return configv1.AuthenticationType(authType), ok
+}
+
+// GetPreviousSecretString returns the client secret a rotation replaced, empty if the secret holds none.
+func GetPreviousSecretString(secret *corev1.Secret) string {
+	return string(secret.Data[api.PreviousClientSecretKey])
+}
+
+// GetSecretStringForAuthType returns the served or the pending client secret belonging to the
+// authentication type, empty if the secret holds none.
+func GetSecretStringForAuthType(secret *corev1.Secret, authType configv1.AuthenticationType) string {
+	if SameAuthType(GetAuthType(secret), authType) {
+		return GetSecretString(secret)
+	}
+	if pendingAuthType, ok := GetPendingAuthType(secret); ok && SameAuthType(pendingAuthType, authType) {
+		return string(secret.Data[api.PendingClientSecretKey])
+	}
+	return ""
+}
+
+// PromotePendingSecretString makes the pending client secret the served one, it returns false if
+// the secret holds no pending client secret. The pending annotation is removed once the secret is
+// applied with resourceapply.
+func PromotePendingSecretString(secret *corev1.Secret) bool {
+	pendingAuthType, ok := GetPendingAuthType(secret)
+	if !ok {
+		return false
+	}
+	SetSecretString(secret, string(secret.Data[api.PendingClientSecretKey]))
+	SetAuthType(secret, pendingAuthType)
+	ClearPendingAuthType(secret)
+	return true
+}
+
+// ClearPendingAuthType removes the pending annotation once the secret is applied with resourceapply.
+func ClearPendingAuthType(secret *corev1.Secret) *corev1.Secret {
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	delete(secret.Annotations, pendingAuthTypeAnnotation)
+	secret.Annotations[pendingAuthTypeAnnotation+"-"] = ""
+	return secret
+}
@@ -9,6 +9,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 
+	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/console-operator/pkg/console/subresource/deployment"
@@ -150,3 +151,106 @@ func TestSetSecretString(t *testing.T) {
 		})
 	}
 }
+
+func TestHasSecretStrings(t *testing.T) {
+	secret := SetSecretStrings(&corev1.Secret{}, map[string]string{
+		ClientSecretKey: "primary",
+		"clientID":      "console",
+	})
+	tests := []struct {
+		name          string
+		secret        *corev1.Secret
+		secretStrings map[string]string
+		want          bool
+	}{
+		{
+			name:          "Same client secrets",
+			secret:        secret,
+			secretStrings: map[string]string{ClientSecretKey: "primary", "clientID": "console"},
+			want:          true,
+		},
+		{
+			name:          "Changed value",
+			secret:        secret,
+			secretStrings: map[string]string{ClientSecretKey: "primary", "clientID": "changed"},
+			want:          false,
+		},
+		{
+			name:          "Removed key",
+			secret:        secret,
+			secretStrings: map[string]string{ClientSecretKey: "primary"},
+			want:          false,
+		},
+		{
+			name:          "Missing secret",
+			secretStrings: map[string]string{ClientSecretKey: "primary"},
+			want:          false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(HasSecretStrings(tt.secret, tt.secretStrings), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetSecretStringForAuthType(t *testing.T) {
+	served := SetAuthType(SetSecretString(&corev1.Secret{}, "oauth"), configv1.AuthenticationTypeIntegratedOAuth)
+	staged := SetPendingSecretString(served.DeepCopy(), configv1.AuthenticationTypeOIDC, "oidc")
+	tests := []struct {
+		name     string
+		secret   *corev1.Secret
+		authType configv1.AuthenticationType
+		want     string
+	}{
+		{
+			name:     "Served client secret",
+			secret:   staged,
+			authType: configv1.AuthenticationTypeNone,
+			want:     "oauth",
+		},
+		{
+			name:     "Pending client secret",
+			secret:   staged,
+			authType: configv1.AuthenticationTypeOIDC,
+			want:     "oidc",
+		},
+		{
+			name:     "No client secret of the auth type",
+			secret:   served,
+			authType: configv1.AuthenticationTypeOIDC,
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(GetSecretStringForAuthType(tt.secret, tt.authType), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestPromotePendingSecretString(t *testing.T) {
+	served := SetAuthType(SetSecretString(&corev1.Secret{}, "oauth"), configv1.AuthenticationTypeIntegratedOAuth)
+	if PromotePendingSecretString(served.DeepCopy()) {
+		t.Errorf("PromotePendingSecretString() promoted a secret without a pending client secret")
+	}
+
+	promoted := SetPendingSecretString(served.DeepCopy(), configv1.AuthenticationTypeOIDC, "oidc")
+	if !PromotePendingSecretString(promoted) {
+		t.Fatalf("PromotePendingSecretString() didn't promote the pending client secret")
+	}
+	want := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			authTypeAnnotation:              string(configv1.AuthenticationTypeOIDC),
+			pendingAuthTypeAnnotation + "-": "",
+		}},
+		Data: map[string][]byte{ClientSecretKey: []byte("oidc")},
+	}
+	if diff := deep.Equal(promoted, want); diff != nil {
+		t.Error(diff)
+	}
+}
@@ -36,7 +36,7 @@ func TestDownloadsEndpoint(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	ocDownloads := clidownloads.PlatformBasedOCConsoleCLIDownloads(url.String(), api.OCCLIDownloadsCustomResourceName)
+	ocDownloads := clidownloads.PlatformBasedOCConsoleCLIDownloads(url.String(), api.OCCLIDownloadsCustomResourceName, "")
 
 	for _, link := range ocDownloads.Spec.Links {
 		req := getRequest(t, link.Href)